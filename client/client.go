@@ -33,9 +33,19 @@ type (
 		UserAgent string
 		// Dump indicates whether to dump request response.
 		Dump bool
+		// MaxRetries is the maximum number of times DoWithRetry retries a failed request.
+		// Requests are not retried if zero, the default.
+		MaxRetries int
+		// RetryBackoff is the delay before the first retry, subsequent retries double the
+		// delay. DefaultRetryBackoff is used if zero.
+		RetryBackoff time.Duration
 	}
 )
 
+// DefaultRetryBackoff is the delay before the first retry used when the client RetryBackoff
+// field is zero.
+const DefaultRetryBackoff = 100 * time.Millisecond
+
 // New creates a new API client that wraps c.
 // If c is nil, the returned client wraps http.DefaultClient.
 func New(c Doer) *Client {
@@ -89,6 +99,53 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	return resp, err
 }
 
+// DoWithRetry calls Do and retries the request if it fails with a transport error or if the
+// response status code is 429 or 5xx. Retries are delayed using an exponential backoff starting
+// at RetryBackoff and stop after MaxRetries attempts or when the context is done, requests are not
+// retried unless MaxRetries is set. Generated clients only use DoWithRetry for actions that are
+// safe or idempotent. Requests with a body may only be retried if their GetBody field is set.
+func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	backoff := c.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.Do(ctx, req)
+		if attempt >= c.MaxRetries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, berr := req.GetBody()
+			if berr != nil {
+				return resp, err
+			}
+			req.Body = body
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		goa.LogInfo(ctx, "retrying", "attempt", attempt+1, "backoff", backoff.String())
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// shouldRetry returns true if the request that produced resp and err may succeed if retried.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
 // Dump request if needed.
 func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
 	reqBody, err := dumpReqBody(req)
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// defaultFileType is the content type of the file parts whose header does not specify one.
+const defaultFileType = "application/octet-stream"
+
+var (
+	fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+	textMarshalerT = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	quoteEscaper   = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+)
+
+// NewFileHeader returns a file header holding the content read from r. Use it to set the File
+// attributes of the payloads sent with EncodeMultipart. The header file name is the base name of
+// filename.
+func NewFileHeader(filename string, r io.Reader) (*multipart.FileHeader, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	w, err := mw.CreateFormFile("file", filepath.Base(filename))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	// Keep the content in memory so that no temporary file is left behind.
+	form, err := multipart.NewReader(&buf, mw.Boundary()).ReadForm(int64(buf.Len()) + 1)
+	if err != nil {
+		return nil, err
+	}
+	return form.File["file"][0], nil
+}
+
+// EncodeMultipart writes payload to w as a multipart/form-data body and returns the body content
+// type. payload must be a struct or a pointer to a struct. The fields are named after their "form"
+// tag, their "json" tag or their name in this order. Fields of type multipart.FileHeader,
+// []multipart.FileHeader or pointers to these are sent as files, the other struct, map and
+// interface fields are sent as JSON and the nil fields are omitted.
+func EncodeMultipart(payload interface{}, w io.Writer) (string, error) {
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", fmt.Errorf("cannot encode nil payload")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return "", fmt.Errorf("cannot encode %s as multipart form", v.Type())
+	}
+	mw := multipart.NewWriter(w)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		name := formFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		if err := writeField(mw, name, v.Field(i)); err != nil {
+			return "", fmt.Errorf("field %s: %s", name, err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return mw.FormDataContentType(), nil
+}
+
+// formFieldName returns the name of the form part holding the given struct field.
+func formFieldName(sf reflect.StructField) string {
+	for _, key := range []string{"form", "json"} {
+		if tag := sf.Tag.Get(key); tag != "" {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return sf.Name
+}
+
+// writeField writes the parts holding the value of f.
+func writeField(mw *multipart.Writer, name string, f reflect.Value) error {
+	switch f.Kind() {
+	case reflect.Ptr:
+		if f.IsNil() {
+			return nil
+		}
+		if f.Type().Elem() == fileHeaderType {
+			return writeFile(mw, name, f.Interface().(*multipart.FileHeader))
+		}
+		return writeField(mw, name, f.Elem())
+	case reflect.Interface:
+		if f.IsNil() {
+			return nil
+		}
+		return writeField(mw, name, f.Elem())
+	case reflect.Map:
+		if f.IsNil() {
+			return nil
+		}
+	case reflect.Struct:
+		if f.Type() == fileHeaderType {
+			fh := f.Interface().(multipart.FileHeader)
+			return writeFile(mw, name, &fh)
+		}
+	case reflect.Slice:
+		if f.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < f.Len(); i++ {
+				if err := writeField(mw, name, f.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	val, err := formValue(f)
+	if err != nil {
+		return err
+	}
+	return mw.WriteField(name, val)
+}
+
+// formValue returns the string representation of f.
+func formValue(f reflect.Value) (string, error) {
+	if f.Type().Implements(textMarshalerT) {
+		b, err := f.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch f.Kind() {
+	case reflect.String:
+		return f.String(), nil
+	case reflect.Slice:
+		return string(f.Bytes()), nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(f.Interface()), nil
+	}
+	b, err := json.Marshal(f.Interface())
+	return string(b), err
+}
+
+// writeFile writes the part holding the content of the file described by fh.
+func writeFile(mw *multipart.Writer, name string, fh *multipart.FileHeader) error {
+	file, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	ct := fh.Header.Get("Content-Type")
+	if ct == "" {
+		ct = defaultFileType
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(name), quoteEscaper.Replace(fh.Filename)))
+	h.Set("Content-Type", ct)
+	w, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
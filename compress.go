@@ -0,0 +1,339 @@
+package goa
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultMaxDecompressedLength is the default maximum length of decompressed request bodies, see
+// Service.MaxDecompressedLength.
+const DefaultMaxDecompressedLength int64 = 32 << 20
+
+// Content codings supported by the response compression, see Service.CompressResponses.
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+// CompressMode controls the compression of the responses of a single action, see
+// CompressResponse.
+type CompressMode string
+
+const (
+	// CompressAuto leaves the decision to compress to the service settings.
+	CompressAuto CompressMode = "auto"
+	// CompressAlways compresses the responses whenever the client accepts it regardless of the
+	// service settings and minimum size.
+	CompressAlways CompressMode = "always"
+	// CompressNever never compresses the responses, for example because the action returns
+	// content that is already compressed.
+	CompressNever CompressMode = "never"
+)
+
+// compressWriter is the response writer used to compress response bodies. The decision to
+// compress is made when the response header is written so that handlers may opt out by setting
+// the Content-Encoding header themselves, for example when serving pre-compressed content.
+//
+// If minSize is greater than 0 bodies smaller than minSize bytes are not compressed. The size
+// is taken from the Content-Length header when set, otherwise the writer buffers up to minSize
+// bytes and delays writing the response header until the decision can be made.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	disabled bool // set by CompressResponse for actions that are never compressed
+	cw       io.WriteCloser
+	started  bool
+	compress bool
+	pending  bool   // true while the decision waits for minSize bytes
+	status   int    // status code of a pending response
+	buf      []byte // body written while pending
+}
+
+// negotiateEncoding returns the content coding to use given the value of a request
+// Accept-Encoding header. It returns an empty string if the client does not accept any of the
+// supported codings. The coding with the highest quality value is selected, gzip is preferred
+// over deflate when both are equally acceptable. The "*" wildcard only applies to the codings
+// that are not listed explicitly so that "gzip;q=0, *" does not select gzip.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		if coding == "x-gzip" {
+			coding = encodingGzip
+		}
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[coding] = q
+	}
+	best, bestQ := "", 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		q, ok := qualities[coding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// decompressReader decompresses request bodies sent with a gzip or deflate content coding. Reads
+// fail once more than max bytes have been decompressed to protect against decompression bombs.
+type decompressReader struct {
+	io.ReadCloser
+	encoding string
+	max      int64
+	n        int64
+	r        io.Reader // decompressor, created on first read
+	corrupt  error     // error produced by a corrupt stream if any
+	exceeded bool
+}
+
+// errDecompressedTooLarge is the error returned by decompressReader when the maximum length is
+// exceeded.
+var errDecompressedTooLarge = errors.New("decompressed request body too large")
+
+// requestEncoding returns the content coding of a request body given the value of its
+// Content-Encoding header. It returns an empty string if the coding is not supported.
+func requestEncoding(contentEncoding string) string {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case encodingGzip, "x-gzip":
+		return encodingGzip
+	case encodingDeflate:
+		return encodingDeflate
+	}
+	return ""
+}
+
+// Read decompresses data read from the underlying reader.
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, errDecompressedTooLarge
+	}
+	if r.r == nil {
+		if r.encoding == encodingGzip {
+			gr, err := gzip.NewReader(r.ReadCloser)
+			if err != nil {
+				return 0, r.check(err)
+			}
+			r.r = gr
+		} else {
+			r.r = flate.NewReader(r.ReadCloser)
+		}
+	}
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.n > r.max {
+		r.exceeded = true
+		return 0, errDecompressedTooLarge
+	}
+	if err != nil && err != io.EOF {
+		err = r.check(err)
+	}
+	return n, err
+}
+
+// check records err if it is caused by a corrupt compressed stream and returns it.
+func (r *decompressReader) check(err error) error {
+	switch err.(type) {
+	case flate.CorruptInputError:
+		r.corrupt = err
+	default:
+		if err == gzip.ErrHeader || err == gzip.ErrChecksum || err == io.ErrUnexpectedEOF || err == io.EOF {
+			r.corrupt = err
+		}
+	}
+	return err
+}
+
+// CompressResponse overrides the service response compression settings for the given action
+// handler. CompressNever disables compression for the action even if Service.CompressResponses is
+// set while CompressAlways compresses the responses of the action whenever the client accepts a
+// supported content coding, even if Service.CompressResponses is not set or the body is smaller
+// than Service.CompressMinSize. CompressAuto returns h unchanged.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func CompressResponse(mode CompressMode, h Handler) Handler {
+	if mode != CompressNever && mode != CompressAlways {
+		return h
+	}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		cw, _ := ctx.Value(compressKey).(*compressWriter)
+		if mode == CompressNever {
+			if cw != nil {
+				cw.disabled = true
+			}
+			return h(ctx, rw, req)
+		}
+		if cw != nil {
+			cw.minSize = 0
+			return h(ctx, rw, req)
+		}
+		resp := ContextResponse(ctx)
+		resp.Header().Add("Vary", "Accept-Encoding")
+		enc := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+		if enc == "" {
+			return h(ctx, rw, req)
+		}
+		w := resp.SwitchWriter(nil)
+		cw = newCompressWriter(w, enc, 0)
+		resp.SwitchWriter(cw)
+		err := h(ctx, rw, req)
+		if !resp.Written() {
+			// Let the error response, if any, be written uncompressed.
+			resp.SwitchWriter(w)
+			return err
+		}
+		if cerr := cw.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}
+}
+
+// newCompressWriter wraps w so that response bodies of at least minSize bytes get compressed
+// using the given coding.
+func newCompressWriter(w http.ResponseWriter, encoding string, minSize int) *compressWriter {
+	return &compressWriter{ResponseWriter: w, encoding: encoding, minSize: minSize}
+}
+
+// WriteHeader sets the compression headers unless the response already has a content coding or
+// cannot have a body and calls the underlying writer.
+func (w *compressWriter) WriteHeader(status int) {
+	if w.started {
+		return
+	}
+	w.start(status)
+	if !w.pending {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write compresses b and writes the result to the underlying writer.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.pending {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	return w.write(b)
+}
+
+// write writes b to the underlying writer compressing it if needed.
+func (w *compressWriter) write(b []byte) (int, error) {
+	if !w.compress {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.cw == nil {
+		if w.encoding == encodingDeflate {
+			w.cw, _ = flate.NewWriter(w.ResponseWriter, flate.DefaultCompression)
+		} else {
+			w.cw = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+	return w.cw.Write(b)
+}
+
+// Flush flushes the compressed data written so far to the client. Flushing a response whose
+// body has not reached the minimum size yet sends it uncompressed.
+func (w *compressWriter) Flush() {
+	if w.pending {
+		w.decide(false)
+	}
+	if f, ok := w.cw.(interface {
+		Flush() error
+	}); ok {
+		f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close flushes any pending compressed data. It must be called once the response is complete.
+func (w *compressWriter) Close() error {
+	if w.pending {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.cw == nil {
+		return nil
+	}
+	return w.cw.Close()
+}
+
+// start decides whether the response body is compressed.
+func (w *compressWriter) start(status int) {
+	if w.started {
+		return
+	}
+	w.started = true
+	h := w.Header()
+	if w.disabled || h.Get("Content-Encoding") != "" || status < 200 ||
+		status == http.StatusNoContent || status == http.StatusNotModified {
+		return
+	}
+	if w.minSize > 0 {
+		if l, err := strconv.Atoi(h.Get("Content-Length")); err == nil {
+			if l < w.minSize {
+				return
+			}
+		} else {
+			w.pending = true
+			w.status = status
+			return
+		}
+	}
+	w.compress = true
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+}
+
+// decide ends the pending state of a response, it writes the response header and the buffered
+// body compressing it if compress is true.
+func (w *compressWriter) decide(compress bool) error {
+	w.pending = false
+	if compress {
+		w.compress = true
+		h := w.Header()
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
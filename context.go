@@ -1,9 +1,12 @@
 package goa
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 )
@@ -19,10 +22,17 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	grantedScopesKey
+	compressKey
+	idempotencyKey
+	coalesceKey
 )
 
 type (
 	// RequestData provides access to the underlying HTTP request.
+	// The body of requests made to actions that do not define a payload is left untouched so
+	// that handlers may stream it, for example multipart requests may be processed one part at
+	// a time using the embedded request MultipartReader method.
 	RequestData struct {
 		*http.Request
 
@@ -31,6 +41,13 @@ type (
 		// Params contains the raw values for the parameters defined in the design including
 		// path parameters, query string parameters and header parameters.
 		Params url.Values
+		// FeatureFlags evaluates the feature flag with the given name for the request, it is
+		// set by feature flag middlewares such as middleware.Features.
+		FeatureFlags func(name string) bool
+		// RequestID is the unique ID of the request, it is set by request ID middlewares such
+		// as middleware.RequestID so that actions may include it in log entries or error
+		// responses.
+		RequestID string
 	}
 
 	// ResponseData provides access to the underlying HTTP response.
@@ -45,6 +62,9 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+
+		// linkRelations lists the relation types accepted by AddLink if not nil.
+		linkRelations []string
 	}
 
 	// key is the type used to store internal values in the context.
@@ -92,6 +112,18 @@ func WithError(ctx context.Context, err error) context.Context {
 	return context.WithValue(ctx, errKey, err)
 }
 
+// WithIdempotency creates a context with the given middleware that the controller generated code
+// applies to the actions declared idempotent in the design, see IdempotentAction.
+func WithIdempotency(ctx context.Context, m Middleware) context.Context {
+	return context.WithValue(ctx, idempotencyKey, m)
+}
+
+// WithCoalescing creates a context with the given middleware that the controller generated code
+// applies to the actions declared coalesced in the design, see CoalesceAction.
+func WithCoalescing(ctx context.Context, m Middleware) context.Context {
+	return context.WithValue(ctx, coalesceKey, m)
+}
+
 // ContextController extracts the controller name from the given context.
 func ContextController(ctx context.Context) string {
 	if c := ctx.Value(ctrlKey); c != nil {
@@ -140,6 +172,71 @@ func ContextError(ctx context.Context) error {
 	return nil
 }
 
+// BearerToken returns the token given in the request "Authorization" header using the
+// "Bearer" scheme as described in RFC 6750. The boolean is false if the header is absent or
+// malformed.
+func (r *RequestData) BearerToken() (string, bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "bearer "
+	if len(auth) <= len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(auth[len(prefix):])
+	if token == "" || strings.ContainsAny(token, " \t") {
+		return "", false
+	}
+	return token, true
+}
+
+// Feature returns true if the feature flag with the given name is enabled for the request.
+// Feature returns false if no feature flag middleware is mounted.
+func (r *RequestData) Feature(name string) bool {
+	if r.FeatureFlags == nil {
+		return false
+	}
+	return r.FeatureFlags(name)
+}
+
+// Prefer returns the value of the preference with the given name listed in the request "Prefer"
+// headers as described in RFC 7240, e.g. "minimal" for the "return" preference of a request
+// with the header "Prefer: return=minimal". Preference names are case insensitive and the value
+// of preferences given without one is the empty string. The boolean is false if the preference
+// is absent.
+func (r *RequestData) Prefer(name string) (string, bool) {
+	for _, header := range r.Header["Prefer"] {
+		for _, pref := range strings.Split(header, ",") {
+			// Drop the preference parameters
+			if i := strings.Index(pref, ";"); i >= 0 {
+				pref = pref[:i]
+			}
+			key, val := pref, ""
+			if i := strings.Index(pref, "="); i >= 0 {
+				key, val = pref[:i], strings.Trim(strings.TrimSpace(pref[i+1:]), `"`)
+			}
+			if strings.EqualFold(strings.TrimSpace(key), name) {
+				return val, true
+			}
+		}
+	}
+	return "", false
+}
+
+// PreferMinimal returns true if the request asks for a minimal response with
+// "Prefer: return=minimal". Actions that honor the preference should call
+// ResponseData.PreferenceApplied("return", "minimal").
+func (r *RequestData) PreferMinimal() bool {
+	ret, _ := r.Prefer("return")
+	return ret == "minimal"
+}
+
+// PreferRepresentation returns true if the request asks for the full representation of the
+// resource with "Prefer: return=representation". Actions that honor the preference should call
+// ResponseData.PreferenceApplied("return", "representation").
+func (r *RequestData) PreferRepresentation() bool {
+	ret, _ := r.Prefer("return")
+	return ret == "representation"
+}
+
 // SwitchWriter overrides the underlying response writer. It returns the response
 // writer that was previously set.
 func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter {
@@ -148,6 +245,98 @@ func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter
 	return rwo
 }
 
+// AddLink adds a RFC 8288 web link with the given relation type and target URL to the response
+// "Link" header. Multiple links are comma separated in a single header value. AddLink returns an
+// error if rel is not a valid relation type or is not one of the relations declared by the action
+// with the LinkRelations DSL, or if href contains characters that cannot appear in a link target.
+// AddLink must be called before the response header is written.
+func (r *ResponseData) AddLink(rel, href string) error {
+	if rel == "" || strings.IndexFunc(rel, invalidLinkRune('"', '\\')) >= 0 {
+		return fmt.Errorf("invalid link relation %#v", rel)
+	}
+	if r.linkRelations != nil {
+		declared := false
+		for _, lr := range r.linkRelations {
+			if lr == rel {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			return fmt.Errorf("link relation %#v is not declared by the action", rel)
+		}
+	}
+	if strings.IndexFunc(href, invalidLinkRune('<', '>', '"')) >= 0 {
+		return fmt.Errorf("invalid link target %#v", href)
+	}
+	link := "<" + href + `>; rel="` + rel + `"`
+	if l := r.Header().Get("Link"); l != "" {
+		link = l + ", " + link
+	}
+	r.Header().Set("Link", link)
+	return nil
+}
+
+// LinkRelations restricts the relation types of the links that the given action handler may add
+// to the response with ResponseData.AddLink to rels.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func LinkRelations(rels []string, h Handler) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if resp := ContextResponse(ctx); resp != nil {
+			resp.linkRelations = rels
+		}
+		return h(ctx, rw, req)
+	}
+}
+
+// invalidLinkRune returns a function that reports whether a rune may not appear in a Link header
+// value: spaces, control and non ASCII characters and the given delimiters.
+func invalidLinkRune(delims ...rune) func(rune) bool {
+	return func(r rune) bool {
+		if r <= ' ' || r >= 0x7f {
+			return true
+		}
+		for _, d := range delims {
+			if r == d {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// PreferenceApplied adds the preference with the given name and value to the response
+// "Preference-Applied" header to let the client know that the preference given in the request
+// "Prefer" header was honored as described in RFC 7240. value is omitted if empty.
+// PreferenceApplied must be called before the response header is written.
+func (r *ResponseData) PreferenceApplied(name, value string) {
+	pref := name
+	if value != "" {
+		pref += "=" + value
+	}
+	if p := r.Header().Get("Preference-Applied"); p != "" {
+		pref = p + ", " + pref
+	}
+	r.Header().Set("Preference-Applied", pref)
+}
+
+// AddServerTiming adds a metric to the response "Server-Timing" header. The metric duration is
+// expressed in milliseconds and desc is omitted if empty. Controllers may call AddServerTiming
+// multiple times to report the time spent in each expensive operation. AddServerTiming must be
+// called before the response header is written.
+func (r *ResponseData) AddServerTiming(name string, dur time.Duration, desc string) {
+	ms := float64(dur) / float64(time.Millisecond)
+	metric := name + ";dur=" + strconv.FormatFloat(ms, 'f', -1, 64)
+	if desc != "" {
+		metric += ";desc=" + strconv.Quote(desc)
+	}
+	if t := r.Header().Get("Server-Timing"); t != "" {
+		metric = t + ", " + metric
+	}
+	r.Header().Set("Server-Timing", metric)
+}
+
 // Written returns true if the response was written, false otherwise.
 func (r *ResponseData) Written() bool {
 	return r.Status != 0
@@ -2,7 +2,9 @@ package goa_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -11,6 +13,106 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+var _ = Describe("RequestData", func() {
+	Context("BearerToken", func() {
+		var auth string
+		var token string
+		var ok bool
+
+		JustBeforeEach(func() {
+			req, err := http.NewRequest("GET", "google.com", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			if auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+			ctx := goa.NewContext(context.Background(), &TestResponseWriter{}, req, nil)
+			token, ok = goa.ContextRequest(ctx).BearerToken()
+		})
+
+		Context("with no Authorization header", func() {
+			BeforeEach(func() {
+				auth = ""
+			})
+
+			It("returns false", func() {
+				Ω(ok).Should(BeFalse())
+			})
+		})
+
+		Context("with a malformed Authorization header", func() {
+			BeforeEach(func() {
+				auth = "Bearer"
+			})
+
+			It("returns false", func() {
+				Ω(ok).Should(BeFalse())
+			})
+		})
+
+		Context("with a bearer token", func() {
+			BeforeEach(func() {
+				auth = "bearer abc.def"
+			})
+
+			It("returns the token", func() {
+				Ω(ok).Should(BeTrue())
+				Ω(token).Should(Equal("abc.def"))
+			})
+		})
+	})
+
+	Context("Prefer", func() {
+		var prefer []string
+		var data *goa.RequestData
+
+		JustBeforeEach(func() {
+			req, err := http.NewRequest("PUT", "/bottles/1", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			for _, p := range prefer {
+				req.Header.Add("Prefer", p)
+			}
+			ctx := goa.NewContext(context.Background(), &TestResponseWriter{}, req, nil)
+			data = goa.ContextRequest(ctx)
+		})
+
+		Context("with no Prefer header", func() {
+			BeforeEach(func() {
+				prefer = nil
+			})
+
+			It("returns false", func() {
+				_, ok := data.Prefer("return")
+				Ω(ok).Should(BeFalse())
+				Ω(data.PreferMinimal()).Should(BeFalse())
+				Ω(data.PreferRepresentation()).Should(BeFalse())
+			})
+		})
+
+		Context("with preferences", func() {
+			BeforeEach(func() {
+				prefer = []string{`respond-async, wait=10`, `Return=minimal; foo="bar", handling="lenient"`}
+			})
+
+			It("returns their values", func() {
+				val, ok := data.Prefer("respond-async")
+				Ω(ok).Should(BeTrue())
+				Ω(val).Should(BeEmpty())
+				val, ok = data.Prefer("wait")
+				Ω(ok).Should(BeTrue())
+				Ω(val).Should(Equal("10"))
+				val, ok = data.Prefer("return")
+				Ω(ok).Should(BeTrue())
+				Ω(val).Should(Equal("minimal"))
+				val, ok = data.Prefer("handling")
+				Ω(ok).Should(BeTrue())
+				Ω(val).Should(Equal("lenient"))
+				Ω(data.PreferMinimal()).Should(BeTrue())
+				Ω(data.PreferRepresentation()).Should(BeFalse())
+			})
+		})
+	})
+})
+
 var _ = Describe("ResponseData", func() {
 	var data *goa.ResponseData
 	var rw http.ResponseWriter
@@ -39,4 +141,118 @@ var _ = Describe("ResponseData", func() {
 			Ω(trw.Status).Should(Equal(42))
 		})
 	})
+
+	Context("Write", func() {
+		var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+		var contentType string
+		var resp *http.Response
+
+		BeforeEach(func() {
+			contentType = ""
+		})
+
+		JustBeforeEach(func() {
+			// The content type of bodies with no declared type is sniffed by the net/http
+			// server when the first bytes of the body are written.
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				data := goa.ContextResponse(goa.NewContext(context.Background(), rw, req, nil))
+				if contentType != "" {
+					data.Header().Set("Content-Type", contentType)
+				}
+				data.WriteHeader(200)
+				data.Write(png)
+			}))
+			defer server.Close()
+			var err error
+			resp, err = http.Get(server.URL)
+			Ω(err).ShouldNot(HaveOccurred())
+			resp.Body.Close()
+		})
+
+		It("lets the server detect the content type of bodies with no declared type", func() {
+			Ω(resp.Header.Get("Content-Type")).Should(Equal("image/png"))
+		})
+
+		Context("with a declared content type", func() {
+			BeforeEach(func() {
+				contentType = "application/octet-stream"
+			})
+
+			It("does not override it", func() {
+				Ω(resp.Header.Get("Content-Type")).Should(Equal("application/octet-stream"))
+			})
+		})
+	})
+
+	Context("AddLink", func() {
+		BeforeEach(func() {
+			data.SwitchWriter(&TestResponseWriter{ParentHeader: make(http.Header)})
+		})
+
+		It("sets the Link header", func() {
+			Ω(data.AddLink("self", "/bottles?page=2")).ShouldNot(HaveOccurred())
+			Ω(data.AddLink("next", "/bottles?page=3")).ShouldNot(HaveOccurred())
+			Ω(data.Header().Get("Link")).Should(Equal(`</bottles?page=2>; rel="self", </bottles?page=3>; rel="next"`))
+		})
+
+		It("rejects targets that would break the header", func() {
+			Ω(data.AddLink("next", `/bottles>; rel="evil"`)).Should(HaveOccurred())
+			Ω(data.AddLink("next", "/bottles?name=a b")).Should(HaveOccurred())
+			Ω(data.Header().Get("Link")).Should(BeEmpty())
+		})
+
+		It("rejects invalid relation types", func() {
+			Ω(data.AddLink(`next"`, "/bottles?page=3")).Should(HaveOccurred())
+			Ω(data.AddLink("", "/bottles?page=3")).Should(HaveOccurred())
+			Ω(data.Header().Get("Link")).Should(BeEmpty())
+		})
+
+		Context("with declared link relations", func() {
+			var addErr error
+
+			JustBeforeEach(func() {
+				req, err := http.NewRequest("GET", "/bottles", nil)
+				Ω(err).ShouldNot(HaveOccurred())
+				rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+				ctx := goa.NewContext(context.Background(), rw, req, nil)
+				h := goa.LinkRelations([]string{"next"}, func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					resp := goa.ContextResponse(ctx)
+					if err := resp.AddLink("next", "/bottles?page=3"); err != nil {
+						return err
+					}
+					addErr = resp.AddLink("prev", "/bottles?page=1")
+					return nil
+				})
+				Ω(h(ctx, rw, req)).ShouldNot(HaveOccurred())
+				data = goa.ContextResponse(ctx)
+			})
+
+			It("rejects undeclared relations", func() {
+				Ω(addErr).Should(HaveOccurred())
+				Ω(data.Header().Get("Link")).Should(Equal(`</bottles?page=3>; rel="next"`))
+			})
+		})
+	})
+	Context("PreferenceApplied", func() {
+		BeforeEach(func() {
+			data.SwitchWriter(&TestResponseWriter{ParentHeader: make(http.Header)})
+		})
+
+		It("sets the Preference-Applied header", func() {
+			data.PreferenceApplied("return", "minimal")
+			data.PreferenceApplied("respond-async", "")
+			Ω(data.Header().Get("Preference-Applied")).Should(Equal("return=minimal, respond-async"))
+		})
+	})
+	Context("AddServerTiming", func() {
+		BeforeEach(func() {
+			data.SwitchWriter(&TestResponseWriter{ParentHeader: make(http.Header)})
+		})
+
+		It("sets the Server-Timing header", func() {
+			data.AddServerTiming("db", 53*time.Millisecond+200*time.Microsecond, "Database query")
+			data.AddServerTiming("cache", 2*time.Millisecond, "")
+			Ω(data.Header().Get("Server-Timing")).Should(Equal(`db;dur=53.2;desc="Database query", cache;dur=2`))
+		})
+	})
 })
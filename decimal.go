@@ -0,0 +1,181 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an arbitrary precision decimal number suitable for representing amounts of money.
+// Contrary to float64 values decimals hold the exact value they were parsed from, so that for
+// example "19.99" is rendered back as "19.99". The zero value is the number 0.
+//
+// Decimal values are encoded to JSON as strings to prevent clients from parsing them into
+// floating point numbers. Both strings and numbers are accepted when decoding.
+type Decimal struct {
+	// unscaled is the decimal digits of the number, the value is unscaled * 10^-scale.
+	unscaled *big.Int
+	// scale is the number of digits after the decimal point.
+	scale int
+}
+
+const (
+	// MaxDecimalDigits is the maximum number of digits accepted by ParseDecimal.
+	MaxDecimalDigits = 1000
+
+	// MaxDecimalScale is the maximum magnitude of the power of ten applied to the digits of
+	// decimals accepted by ParseDecimal, e.g. "1e1001" and "1e-1001" are both rejected. Bounding
+	// the digits and the scale bounds the memory and CPU needed to process decimals read from
+	// requests.
+	MaxDecimalScale = 1000
+)
+
+// ParseDecimal parses the given string into a decimal. The string may use exponent notation,
+// e.g. "1.5e3". ParseDecimal returns an error if the number has more than MaxDecimalDigits
+// digits or if its scale exceeds MaxDecimalScale.
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %#v", s)
+		}
+		exp = e
+		str = str[:i]
+	}
+	digits := str
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+	intPart, fracPart := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intPart, fracPart = digits[:i], digits[i+1:]
+	}
+	if intPart+fracPart == "" || strings.Trim(intPart+fracPart, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %#v", s)
+	}
+	if len(intPart)+len(fracPart) > MaxDecimalDigits {
+		return Decimal{}, fmt.Errorf("invalid decimal %#v, more than %d digits", s, MaxDecimalDigits)
+	}
+	scale := len(fracPart) - exp
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("invalid decimal %#v, exponent out of range", s)
+	}
+	unscaled, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if str[0] == '-' {
+		unscaled.Neg(unscaled)
+	}
+	if scale < 0 {
+		unscaled.Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	return Decimal{unscaled: unscaled, scale: scale}, nil
+}
+
+// NewDecimal creates a decimal from the given value. It accepts strings, json.Number values,
+// integers, floats and decimals. Floats are converted using the shortest representation that
+// parses back to the same float so that NewDecimal(19.99) is exactly 19.99.
+func NewDecimal(v interface{}) (Decimal, error) {
+	switch actual := v.(type) {
+	case Decimal:
+		return actual, nil
+	case *Decimal:
+		return *actual, nil
+	case string:
+		return ParseDecimal(actual)
+	case json.Number:
+		return ParseDecimal(string(actual))
+	case int:
+		return Decimal{unscaled: big.NewInt(int64(actual))}, nil
+	case int32:
+		return Decimal{unscaled: big.NewInt(int64(actual))}, nil
+	case int64:
+		return Decimal{unscaled: big.NewInt(actual)}, nil
+	case float32:
+		return ParseDecimal(strconv.FormatFloat(float64(actual), 'f', -1, 32))
+	case float64:
+		return ParseDecimal(strconv.FormatFloat(actual, 'f', -1, 64))
+	}
+	return Decimal{}, fmt.Errorf("cannot convert %#v to decimal", v)
+}
+
+// String returns the decimal representation of d, e.g. "19.99".
+func (d Decimal) String() string {
+	if d.unscaled == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Rat returns the value of d as a rational number.
+func (d Decimal) Rat() *big.Rat {
+	if d.unscaled == nil {
+		return new(big.Rat)
+	}
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.scale)), nil)
+	return new(big.Rat).SetFrac(d.unscaled, denom)
+}
+
+// Cmp compares d and other and returns -1 if d < other, 0 if d == other and +1 if d > other.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Rat().Cmp(other.Rat())
+}
+
+// MarshalJSON encodes the decimal as a JSON string.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON decodes a decimal from a JSON string or number.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("invalid decimal %s", b)
+		}
+	}
+	dec, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = dec
+	return nil
+}
+
+// ParamDecimal returns the value of the request parameter with the given name as a decimal.
+// It returns the zero decimal and false if the parameter is missing and an error if its value is
+// not a valid decimal. Parameters given multiple times are resolved using ScalarParam.
+func (service *Service) ParamDecimal(req *RequestData, name string) (Decimal, bool, error) {
+	vals, ok := req.Params[name]
+	if !ok || len(vals) == 0 {
+		return Decimal{}, false, nil
+	}
+	raw, err := service.ScalarParam(name, vals)
+	if err != nil {
+		return Decimal{}, true, err
+	}
+	d, err := ParseDecimal(raw)
+	if err != nil {
+		return Decimal{}, true, InvalidParamTypeError(name, raw, "decimal")
+	}
+	return d, true, nil
+}
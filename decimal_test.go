@@ -0,0 +1,120 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decimal", func() {
+	Describe("ParseDecimal", func() {
+		It("parses decimal strings", func() {
+			cases := map[string]string{
+				"19.99":   "19.99",
+				"-0.05":   "-0.05",
+				"+42":     "42",
+				"1.50":    "1.50",
+				"1.5e3":   "1500",
+				"25e-3":   "0.025",
+				" .5 ":    "0.5",
+				"0000.10": "0.10",
+			}
+			for s, expected := range cases {
+				d, err := goa.ParseDecimal(s)
+				Ω(err).ShouldNot(HaveOccurred(), s)
+				Ω(d.String()).Should(Equal(expected), s)
+			}
+		})
+
+		It("rejects invalid strings", func() {
+			for _, s := range []string{"", "-", "1.2.3", "abc", "1e", "0x10"} {
+				_, err := goa.ParseDecimal(s)
+				Ω(err).Should(HaveOccurred(), s)
+			}
+		})
+
+		It("rejects numbers that are too large or too precise", func() {
+			digits := strings.Repeat("1", goa.MaxDecimalDigits+1)
+			for _, s := range []string{"1e30000000", "1e-300000000", "1e1001", digits, "0." + digits} {
+				_, err := goa.ParseDecimal(s)
+				Ω(err).Should(HaveOccurred(), s)
+			}
+			d, err := goa.ParseDecimal("1e1000")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(d.String()).Should(HaveLen(1001))
+		})
+	})
+
+	Describe("NewDecimal", func() {
+		It("converts strings, integers and floats", func() {
+			for _, v := range []interface{}{"19.99", 19.99, json.Number("19.99")} {
+				d, err := goa.NewDecimal(v)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(d.String()).Should(Equal("19.99"))
+			}
+			d, err := goa.NewDecimal(int64(-7))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(d.String()).Should(Equal("-7"))
+		})
+
+		It("rejects other types", func() {
+			_, err := goa.NewDecimal(true)
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	It("round-trips through JSON without drift", func() {
+		var v struct {
+			Price goa.Decimal `json:"price"`
+		}
+		Ω(json.Unmarshal([]byte(`{"price":"19.99"}`), &v)).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(v)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"price":"19.99"}`))
+
+		Ω(json.Unmarshal([]byte(`{"price":19.99}`), &v)).ShouldNot(HaveOccurred())
+		Ω(v.Price.String()).Should(Equal("19.99"))
+	})
+
+	It("compares values", func() {
+		a, _ := goa.ParseDecimal("1.50")
+		b, _ := goa.ParseDecimal("1.5")
+		c, _ := goa.ParseDecimal("2")
+		Ω(a.Cmp(b)).Should(Equal(0))
+		Ω(a.Cmp(c)).Should(Equal(-1))
+		Ω(goa.Decimal{}.String()).Should(Equal("0"))
+	})
+
+	Describe("ParamDecimal", func() {
+		var service *goa.Service
+		var req *goa.RequestData
+
+		BeforeEach(func() {
+			service = goa.New("test")
+			req = &goa.RequestData{Params: url.Values{"price": {"19.99"}, "bad": {"nineteen"}}}
+		})
+
+		It("loads the parameter value", func() {
+			d, ok, err := service.ParamDecimal(req, "price")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ok).Should(BeTrue())
+			Ω(d.String()).Should(Equal("19.99"))
+		})
+
+		It("reports missing parameters", func() {
+			_, ok, err := service.ParamDecimal(req, "missing")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ok).Should(BeFalse())
+		})
+
+		It("reports invalid values", func() {
+			_, ok, err := service.ParamDecimal(req, "bad")
+			Ω(ok).Should(BeTrue())
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
@@ -79,6 +79,10 @@ var (
 	// WildcardRegex is the regular expression used to capture path parameters.
 	WildcardRegex = regexp.MustCompile(`/(?::|\*)([a-zA-Z0-9_]+)`)
 
+	// bracedWildcardRegex is the regular expression used to capture path parameters written
+	// using the alternative "{param}" syntax.
+	bracedWildcardRegex = regexp.MustCompile(`/\{(\*?[a-zA-Z0-9_]+)\}`)
+
 	// DefaultDecoders contains the decoding definitions used when no Consumes DSL is found.
 	DefaultDecoders []*EncodingDefinition
 
@@ -222,6 +226,20 @@ func HasKnownEncoder(mimeType string) bool {
 	return KnownEncoders[mimeType] != ""
 }
 
+// NormalizePath converts the path parameters written using the "{param}" syntax into the ":param"
+// syntax used by the rest of the design and by the mux, e.g. "/bottles/{id}" becomes
+// "/bottles/:id". Catch-all parameters written as "{*param}" become "*param". Paths that only use
+// the ":param" syntax are returned unchanged.
+func NormalizePath(path string) string {
+	return bracedWildcardRegex.ReplaceAllStringFunc(path, func(m string) string {
+		name := m[2 : len(m)-1]
+		if strings.HasPrefix(name, "*") {
+			return "/" + name
+		}
+		return "/:" + name
+	})
+}
+
 // ExtractWildcards returns the names of the wildcards that appear in path.
 func ExtractWildcards(path string) []string {
 	matches := WildcardRegex.FindAllStringSubmatch(path, -1)
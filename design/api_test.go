@@ -65,6 +65,16 @@ var _ = Describe("ExtractWildcards", func() {
 	})
 })
 
+var _ = Describe("NormalizePath", func() {
+	It("leaves paths using the colon syntax unchanged", func() {
+		Ω(design.NormalizePath("/a/:foo/b/*bar")).Should(Equal("/a/:foo/b/*bar"))
+	})
+
+	It("converts braced wildcards to the colon syntax", func() {
+		Ω(design.NormalizePath("/a/{foo}/b/{*bar}")).Should(Equal("/a/:foo/b/*bar"))
+	})
+})
+
 var _ = Describe("MediaTypeRoot", func() {
 	var root design.MediaTypeRoot
 
@@ -2,6 +2,8 @@ package apidsl
 
 import (
 	"fmt"
+	"strings"
+	"time"
 	"unicode"
 
 	"github.com/goadesign/goa/design"
@@ -114,7 +116,8 @@ func Action(name string, dsl func()) {
 // The route function takes the path as argument. Route paths may use wildcards as described in the
 // [httptreemux](https://godoc.org/github.com/dimfeld/httptreemux) package documentation. These
 // wildcards define parameters using the `:name` or `*name` syntax where `:name` matches a path
-// segment and `*name` is a catch-all that matches the path until the end.
+// segment and `*name` is a catch-all that matches the path until the end. Wildcards may also be
+// written `{name}` and `{*name}`, such paths are normalized to the `:name` and `*name` syntax.
 func Routing(routes ...*design.RouteDefinition) {
 	if a, ok := actionDefinition(); ok {
 		for _, r := range routes {
@@ -126,7 +129,7 @@ func Routing(routes ...*design.RouteDefinition) {
 
 // GET creates a route using the GET HTTP method.
 func GET(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "GET", Path: path}
+	route := &design.RouteDefinition{Verb: "GET", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -137,7 +140,7 @@ func GET(path string, dsl ...func()) *design.RouteDefinition {
 
 // HEAD creates a route using the HEAD HTTP method.
 func HEAD(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "HEAD", Path: path}
+	route := &design.RouteDefinition{Verb: "HEAD", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -148,7 +151,7 @@ func HEAD(path string, dsl ...func()) *design.RouteDefinition {
 
 // POST creates a route using the POST HTTP method.
 func POST(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "POST", Path: path}
+	route := &design.RouteDefinition{Verb: "POST", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -159,7 +162,7 @@ func POST(path string, dsl ...func()) *design.RouteDefinition {
 
 // PUT creates a route using the PUT HTTP method.
 func PUT(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "PUT", Path: path}
+	route := &design.RouteDefinition{Verb: "PUT", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -170,7 +173,7 @@ func PUT(path string, dsl ...func()) *design.RouteDefinition {
 
 // DELETE creates a route using the DELETE HTTP method.
 func DELETE(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "DELETE", Path: path}
+	route := &design.RouteDefinition{Verb: "DELETE", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -181,7 +184,7 @@ func DELETE(path string, dsl ...func()) *design.RouteDefinition {
 
 // OPTIONS creates a route using the OPTIONS HTTP method.
 func OPTIONS(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "OPTIONS", Path: path}
+	route := &design.RouteDefinition{Verb: "OPTIONS", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -192,7 +195,7 @@ func OPTIONS(path string, dsl ...func()) *design.RouteDefinition {
 
 // TRACE creates a route using the TRACE HTTP method.
 func TRACE(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "TRACE", Path: path}
+	route := &design.RouteDefinition{Verb: "TRACE", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -203,7 +206,7 @@ func TRACE(path string, dsl ...func()) *design.RouteDefinition {
 
 // CONNECT creates a route using the CONNECT HTTP method.
 func CONNECT(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "CONNECT", Path: path}
+	route := &design.RouteDefinition{Verb: "CONNECT", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -214,7 +217,7 @@ func CONNECT(path string, dsl ...func()) *design.RouteDefinition {
 
 // PATCH creates a route using the PATCH HTTP method.
 func PATCH(path string, dsl ...func()) *design.RouteDefinition {
-	route := &design.RouteDefinition{Verb: "PATCH", Path: path}
+	route := &design.RouteDefinition{Verb: "PATCH", Path: design.NormalizePath(path)}
 	if len(dsl) != 0 {
 		if !dslengine.Execute(dsl[0], route) {
 			return nil
@@ -446,6 +449,155 @@ func payload(isOptional bool, p interface{}, dsls ...func()) {
 	}
 }
 
+// Idempotent marks the action as idempotent. Generated clients retry failed requests made to
+// idempotent actions when retries are enabled (see the client.Client MaxRetries field). Actions
+// whose routes all use safe HTTP methods (GET or HEAD) are retried whether or not they are marked
+// idempotent. The generated controllers also let the middleware.Idempotent middleware replay the
+// responses of the action. Example:
+//
+//	Action("update", func() {
+//		Routing(PUT("/:id"))
+//		Idempotent()
+//	})
+//
+func Idempotent() {
+	if a, ok := actionDefinition(); ok {
+		a.Idempotent = true
+	}
+}
+
+// Coalesce lets concurrent identical GET requests made to the action share a single execution of
+// the action. The generated controllers let the middleware.Coalesce middleware handle the action,
+// see its documentation for the requests that are considered identical. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Coalesce()
+//	})
+//
+func Coalesce() {
+	if a, ok := actionDefinition(); ok {
+		a.Coalesce = true
+	}
+}
+
+// MaxPayloadBytes sets the maximum length in bytes of the request bodies accepted by the action.
+// Requests with a longer body are rejected with a 413 response before the controller action is
+// invoked. The limit applies in addition to the service and controller wide limits (see
+// goa.Service.RequestBudget and goa.Controller.MaxRequestBodyLength), the smallest wins. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST(""))
+//		Payload(UploadPayload)
+//		MaxPayloadBytes(10 << 20) // 10MB
+//	})
+//
+func MaxPayloadBytes(n int64) {
+	if n <= 0 {
+		dslengine.ReportError("maximum payload length must be greater than 0, got %d", n)
+		return
+	}
+	if a, ok := actionDefinition(); ok {
+		a.MaxPayloadBytes = n
+	}
+}
+
+// Compress overrides the service response compression settings for the action. mode must be one
+// of "auto" (the default, the service settings apply), "always" (responses are compressed whenever
+// the client accepts it) or "never" (responses are never compressed, for example because the
+// action returns content that is already compressed). Example:
+//
+//	Action("download", func() {
+//		Routing(GET("/:id/archive"))
+//		Compress("never")
+//	})
+//
+func Compress(mode string) {
+	switch mode {
+	case "auto", "always", "never":
+	default:
+		dslengine.ReportError(`invalid compression mode %#v, must be one of "auto", "always" or "never"`, mode)
+		return
+	}
+	if a, ok := actionDefinition(); ok {
+		a.Compress = mode
+	}
+}
+
+// Timeout sets the maximum duration of the action. The generated code runs the controller action
+// under a context whose deadline expires after the given duration and responds with 503 if the
+// action does not return in time (see goa.ActionTimeout). The action should watch the context
+// Done channel to stop any work in progress once the deadline expires. Example:
+//
+//	Action("report", func() {
+//		Routing(GET("/report"))
+//		Timeout(5 * time.Second)
+//	})
+//
+func Timeout(d time.Duration) {
+	if d <= 0 {
+		dslengine.ReportError("timeout must be greater than 0, got %s", d)
+		return
+	}
+	if a, ok := actionDefinition(); ok {
+		a.Timeout = d
+	}
+}
+
+// LinkRelations declares the relation types of the web links that the action may add to its
+// responses "Link" header. goa.ResponseData.AddLink returns an error for any other relation type
+// when relations are declared. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		LinkRelations("first", "prev", "next", "last")
+//	})
+//
+func LinkRelations(rels ...string) {
+	for _, rel := range rels {
+		if rel == "" || strings.ContainsAny(rel, "\"\\ \t\r\n") {
+			dslengine.ReportError("invalid link relation %#v", rel)
+			return
+		}
+	}
+	if a, ok := actionDefinition(); ok {
+		a.LinkRelations = append(a.LinkRelations, rels...)
+	}
+}
+
+// PageLimit defines an integer parameter that limits the number of results returned by an action.
+// The parameter defaults to defaultLimit when absent from the request and must be between 1 and
+// maxLimit: the generated contexts set the default value and return a 400 response for values
+// outside of that range before the controller action is invoked. PageLimit must appear in a
+// Params DSL, the optional DSL can add a description or other properties to the parameter.
+// Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Params(func() {
+//			PageLimit("maxResults", 20, 100)
+//		})
+//	})
+//
+func PageLimit(name string, defaultLimit, maxLimit int, dsls ...func()) {
+	if maxLimit < 1 {
+		dslengine.ReportError("maximum limit must be greater than 0, got %d", maxLimit)
+		return
+	}
+	if defaultLimit < 1 || defaultLimit > maxLimit {
+		dslengine.ReportError("default limit %d must be between 1 and %d", defaultLimit, maxLimit)
+		return
+	}
+	Param(name, design.Integer, func() {
+		Default(defaultLimit)
+		Minimum(1)
+		Maximum(maxLimit)
+		for _, dsl := range dsls {
+			dsl()
+		}
+	})
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
@@ -2,6 +2,7 @@ package apidsl_test
 
 import (
 	"strconv"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -57,6 +58,7 @@ var _ = Describe("Action", func() {
 			Ω(action.Routes).ShouldNot(BeNil())
 			Ω(action.Routes).Should(HaveLen(1))
 			Ω(action.Routes[0]).Should(Equal(route))
+			Ω(action.Retryable()).Should(BeTrue())
 		})
 
 		Context("with an empty params DSL", func() {
@@ -94,6 +96,193 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with a POST route", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Routing(POST("")) }
+		})
+
+		It("is not retryable", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Idempotent).Should(BeFalse())
+			Ω(action.Retryable()).Should(BeFalse())
+		})
+
+		Context("declared idempotent", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(POST(""))
+					Idempotent()
+				}
+			})
+
+			It("is retryable", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(action.Idempotent).Should(BeTrue())
+				Ω(action.Retryable()).Should(BeTrue())
+			})
+		})
+	})
+
+	Context("with a coalesced action", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET(""))
+				Coalesce()
+			}
+		})
+
+		It("marks the action as coalesced", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Coalesce).Should(BeTrue())
+		})
+	})
+
+	Context("with a maximum payload length", func() {
+		BeforeEach(func() {
+			name = "upload"
+			dsl = func() {
+				Routing(POST(""))
+				Payload(String)
+				MaxPayloadBytes(1024)
+			}
+		})
+
+		It("sets the action maximum payload length", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.MaxPayloadBytes).Should(Equal(int64(1024)))
+		})
+	})
+
+	Context("with a compression mode", func() {
+		var mode string
+
+		BeforeEach(func() {
+			name = "download"
+			mode = "never"
+			dsl = func() {
+				Routing(GET("/archive"))
+				Compress(mode)
+			}
+		})
+
+		It("sets the action compression mode", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Compress).Should(Equal("never"))
+		})
+
+		Context("that is invalid", func() {
+			BeforeEach(func() {
+				mode = "sometimes"
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid compression mode "sometimes"`))
+			})
+		})
+	})
+
+	Context("with a timeout", func() {
+		BeforeEach(func() {
+			name = "report"
+			dsl = func() {
+				Routing(GET("/report"))
+				Timeout(5 * time.Second)
+			}
+		})
+
+		It("sets the action timeout", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Timeout).Should(Equal(5 * time.Second))
+		})
+	})
+
+	Context("with link relations", func() {
+		var rel string
+
+		BeforeEach(func() {
+			name = "list"
+			rel = "next"
+			dsl = func() {
+				Routing(GET(""))
+				LinkRelations(rel, "prev")
+			}
+		})
+
+		It("sets the action link relations", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.LinkRelations).Should(Equal([]string{"next", "prev"}))
+		})
+
+		Context("that are invalid", func() {
+			BeforeEach(func() {
+				rel = `next"`
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid link relation"))
+			})
+		})
+	})
+
+	Context("with a page limit parameter", func() {
+		var defaultLimit, maxLimit int
+
+		BeforeEach(func() {
+			name = "list"
+			defaultLimit, maxLimit = 20, 100
+			dsl = func() {
+				Routing(GET(""))
+				Params(func() {
+					PageLimit("maxResults", defaultLimit, maxLimit, func() {
+						Description("Maximum number of results")
+					})
+				})
+			}
+		})
+
+		It("defines the parameter with a default and a maximum", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			p := action.Params.Type.ToObject()["maxResults"]
+			Ω(p).ShouldNot(BeNil())
+			Ω(p.Type).Should(Equal(Integer))
+			Ω(p.Description).Should(Equal("Maximum number of results"))
+			Ω(p.DefaultValue).Should(Equal(20))
+			Ω(p.Validation).ShouldNot(BeNil())
+			Ω(*p.Validation.Minimum).Should(Equal(1.0))
+			Ω(*p.Validation.Maximum).Should(Equal(100.0))
+		})
+
+		Context("with a default greater than the maximum", func() {
+			BeforeEach(func() {
+				defaultLimit = 200
+			})
+
+			It("fails", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with routes using both wildcard syntaxes", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:id"), PUT("/{id}"))
+			}
+		})
+
+		It("normalizes the route paths", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Routes).Should(HaveLen(2))
+			Ω(action.Routes[1].Path).Should(Equal(action.Routes[0].Path))
+			Ω(action.Routes[1].Params()).Should(Equal([]string{"id"}))
+		})
+	})
+
 	Context("with a string payload", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -62,6 +62,7 @@ import (
 //			MediaType(arg2)
 //		})
 //              NoExample()                             // Prevent automatic generation of examples
+//		FailFast()				// Stop validating requests at the first error
 //		Trait("Authenticated", func() {		// Traits define DSL that can be run anywhere
 //			Headers(func() {
 //				Header("header")
@@ -126,6 +127,7 @@ func Description(d string) {
 // The path may define wildcards (see Routing for a description of the wildcard syntax).
 // The corresponding parameters must be described using Params.
 func BasePath(val string) {
+	val = design.NormalizePath(val)
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
 		def.BasePath = val
@@ -222,6 +224,15 @@ func Credentials() {
 	}
 }
 
+// FailFast causes the generated code to stop validating a request or a response at the first
+// validation error. The generated code reports all the validation errors by default which can be
+// costly for large invalid payloads. Used in API DSL.
+func FailFast() {
+	if a, ok := apiDefinition(); ok {
+		a.FailFast = true
+	}
+}
+
 // TermsOfService describes the API terms of services or links to them.
 func TermsOfService(terms string) {
 	if a, ok := apiDefinition(); ok {
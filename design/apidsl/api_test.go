@@ -118,6 +118,18 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with fail fast validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					FailFast()
+				}
+			})
+
+			It("sets the API fail fast flag", func() {
+				Ω(Design.FailFast).Should(BeTrue())
+			})
+		})
+
 		Context("with contact information", func() {
 			const contactName = "contactName"
 			const contactEmail = "contactEmail"
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -17,7 +18,9 @@ import (
 // attributes may include other attributes. At the basic level an attribute has a name,
 // a type and optionally a default value and validation rules. The type of an attribute can be one of:
 //
-// * The primitive types Boolean, Integer, Number, DateTime, UUID or String.
+// * The primitive types Boolean, Integer, Number, Decimal, DateTime, UUID or String.
+//
+// * The File type for files uploaded in multipart/form-data request bodies, payloads only.
 //
 // * A type defined via the Type function.
 //
@@ -315,7 +318,7 @@ func Enum(val ...interface{}) {
 			// one below are really a convenience to the user and not a fundamental feature
 			// - not checking in the case the type is not known yet is OK.
 			if a.Type != nil && !a.Type.IsCompatible(v) {
-				dslengine.ReportError("value %#v at index #d is incompatible with attribute of type %s",
+				dslengine.ReportError("value %#v at index %d is incompatible with attribute of type %s",
 					v, i, a.Type.Name())
 				ok = false
 			}
@@ -407,61 +410,131 @@ func Pattern(p string) {
 
 // Minimum adds a "minimum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor21.
+// The minimum of a DateTime attribute is given as a time.Time or a RFC3339 string.
 func Minimum(val interface{}) {
-	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
-			incompatibleAttributeType("minimum", a.Type.Name(), "an integer or a number")
-		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					dslengine.ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				dslengine.ReportError("invalid number value %#v", v)
-				return
-			}
-			if a.Validation == nil {
-				a.Validation = &dslengine.ValidationDefinition{}
-			}
-			a.Validation.Minimum = &f
-		}
-	}
+	rangeValidation("minimum", val, true, false)
+}
+
+// ExclusiveMinimum adds a "minimum" validation with "exclusiveMinimum" set to the attribute: the
+// attribute value must be strictly greater than val.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor21.
+func ExclusiveMinimum(val interface{}) {
+	rangeValidation("exclusiveMinimum", val, true, true)
 }
 
 // Maximum adds a "maximum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor17.
+// The maximum of a DateTime attribute is given as a time.Time or a RFC3339 string.
 func Maximum(val interface{}) {
+	rangeValidation("maximum", val, false, false)
+}
+
+// ExclusiveMaximum adds a "maximum" validation with "exclusiveMaximum" set to the attribute: the
+// attribute value must be strictly lesser than val.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor17.
+func ExclusiveMaximum(val interface{}) {
+	rangeValidation("exclusiveMaximum", val, false, true)
+}
+
+// rangeValidation implements the minimum and maximum validation DSLs.
+func rangeValidation(name string, val interface{}, isMin, exclusive bool) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	if a.Type != nil && a.Type.Kind() == design.DateTimeKind {
+		t, ok := timeValue(val)
+		if !ok {
+			return
+		}
+		if a.Validation == nil {
+			a.Validation = &dslengine.ValidationDefinition{}
+		}
+		if isMin {
+			a.Validation.MinimumTime = &t
+			a.Validation.ExclusiveMinimum = exclusive
+		} else {
+			a.Validation.MaximumTime = &t
+			a.Validation.ExclusiveMaximum = exclusive
+		}
+		return
+	}
+	if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+		incompatibleAttributeType(name, a.Type.Name(), "an integer, a number or a date time")
+		return
+	}
+	f, ok := numberValue(val)
+	if !ok {
+		return
+	}
+	if a.Validation == nil {
+		a.Validation = &dslengine.ValidationDefinition{}
+	}
+	if isMin {
+		a.Validation.Minimum = &f
+		a.Validation.ExclusiveMinimum = exclusive
+	} else {
+		a.Validation.Maximum = &f
+		a.Validation.ExclusiveMaximum = exclusive
+	}
+}
+
+// MultipleOf adds a "multipleOf" validation to the attribute: the attribute value must be a
+// multiple of val. val must be strictly greater than 0.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor14.
+func MultipleOf(val interface{}) {
 	if a, ok := attributeDefinition(); ok {
 		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
-			incompatibleAttributeType("maximum", a.Type.Name(), "an integer or a number")
-		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					dslengine.ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				dslengine.ReportError("invalid number value %#v", v)
+			incompatibleAttributeType("multipleOf", a.Type.Name(), "an integer or a number")
+		} else if f, ok := numberValue(val); ok {
+			if f <= 0 {
+				dslengine.ReportError("multipleOf value must be strictly greater than 0, got %v", f)
 				return
 			}
 			if a.Validation == nil {
 				a.Validation = &dslengine.ValidationDefinition{}
 			}
-			a.Validation.Maximum = &f
+			a.Validation.MultipleOf = &f
+		}
+	}
+}
+
+// numberValue converts the value given to a minimum or maximum validation DSL to a float64. It
+// reports an error and returns false if the value is not a number.
+func numberValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float(), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			dslengine.ReportError("invalid number value %#v", v)
+			return 0, false
 		}
+		return f, true
+	default:
+		dslengine.ReportError("invalid number value %#v", v)
+		return 0, false
+	}
+}
+
+// timeValue converts the value given to a minimum or maximum validation DSL of a DateTime
+// attribute to a time.Time. It reports an error and returns false if the value is neither a
+// time.Time nor a RFC3339 string.
+func timeValue(val interface{}) (time.Time, bool) {
+	switch v := val.(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			dslengine.ReportError("invalid date time value %#v, must be RFC3339", v)
+			return time.Time{}, false
+		}
+		return t, true
+	default:
+		dslengine.ReportError("invalid date time value %#v", v)
+		return time.Time{}, false
 	}
 }
 
@@ -495,6 +568,22 @@ func MaxLength(val int) {
 	}
 }
 
+// UniqueItems adds a "uniqueItems" validation to the attribute: the elements of the array value
+// must all be different.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor49.
+func UniqueItems() {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.ArrayKind {
+			incompatibleAttributeType("uniqueItems", a.Type.Name(), "an array")
+		} else {
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.UniqueItems = true
+		}
+	}
+}
+
 // Required adds a "required" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor61.
 func Required(names ...string) {
@@ -520,6 +609,145 @@ func Required(names ...string) {
 	}
 }
 
+// RequiredFor makes the attribute required in the payload of requests made with one of the given
+// HTTP methods. This makes it possible to use the same type for the payloads of actions that
+// create and update a resource:
+//
+//	var BottlePayload = Type("BottlePayload", func() {
+//		Attribute("name", String, func() {
+//			RequiredFor("POST", "PUT") // name may be omitted by PATCH requests
+//		})
+//	})
+//
+// RequiredFor only applies to the top level attributes of action payloads, the presence of the
+// attribute is checked when the request payload is loaded.
+func RequiredFor(methods ...string) {
+	if a, ok := attributeDefinition(); ok {
+		for _, m := range methods {
+			a.RequiredFor = append(a.RequiredFor, strings.ToUpper(m))
+		}
+	}
+}
+
+// AdditionalProperties defines how keys of object values that do not match any attribute are
+// handled. The argument is either a design.AdditionalPropertiesMode or the data type of the
+// additional properties values. The generated code rejects JSON request payloads that contain
+// additional properties not allowed by the design and the JSON schema and Swagger specifications
+// describe them with "additionalProperties":
+//
+//	Attribute("settings", func() {
+//		Attribute("theme", String)
+//		AdditionalProperties(design.AdditionalPropertiesReject)
+//	})
+//
+//	Attribute("labels", func() {
+//		AdditionalProperties(String)
+//	})
+func AdditionalProperties(v interface{}) {
+	var at *design.AttributeDefinition
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		at = def
+	case *design.MediaTypeDefinition:
+		at = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+
+	if at.Type == nil {
+		at.Type = make(design.Object)
+	}
+	if at.Type.Kind() != design.ObjectKind {
+		incompatibleAttributeType("additional properties", at.Type.Name(), "an object")
+		return
+	}
+	switch actual := v.(type) {
+	case design.AdditionalPropertiesMode:
+		at.AdditionalProperties = actual
+	case design.DataType:
+		at.AdditionalPropertiesType = actual
+	default:
+		dslengine.ReportError("invalid AdditionalProperties argument, must be a mode or a type")
+	}
+}
+
+// TimeFormat sets the layout used to parse the values of a DateTime attribute. The layout uses the
+// reference time syntax of the standard library time package, for example:
+//
+//	Attribute("due", DateTime, func() {
+//		TimeFormat("2006-01-02")
+//	})
+//
+// Values are parsed using exactly this layout, values that do not match it are rejected. Values
+// of DateTime attributes with no time format are parsed as RFC3339 timestamps. TimeFormat only
+// applies to request parameters and headers, the generated client also uses it to format them.
+// Payloads and media types are encoded as RFC3339 timestamps and may not use it.
+func TimeFormat(layout string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.DateTimeKind {
+			incompatibleAttributeType("time format", a.Type.Name(), "a date time")
+			return
+		}
+		if layout == "" {
+			dslengine.ReportError("time format cannot be empty")
+			return
+		}
+		a.TimeFormat = layout
+	}
+}
+
+// TimeUnit sets the unit of integer values of a DateTime attribute: integer values are the number
+// of "seconds" or "milliseconds" elapsed since the Unix epoch. This makes it possible to accept
+// timestamps sent by JavaScript clients (Date.now()) for example:
+//
+//	Param("since", DateTime, func() {
+//		TimeUnit("milliseconds")
+//	})
+//
+// Other values are still parsed as RFC3339 timestamps or using the TimeFormat layout if any.
+// Integer values are rejected if no time unit is set. As with TimeFormat, TimeUnit only applies to
+// request parameters and headers.
+func TimeUnit(unit string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.DateTimeKind {
+			incompatibleAttributeType("time unit", a.Type.Name(), "a date time")
+			return
+		}
+		if unit != "seconds" && unit != "milliseconds" {
+			dslengine.ReportError(`invalid time unit %#v, must be "seconds" or "milliseconds"`, unit)
+			return
+		}
+		a.TimeUnit = unit
+	}
+}
+
+// CollectionFormat sets the format of the values of an array parameter. The elements of array
+// parameters are read from repeated query string keys by default (?label=a&label=b), the "csv"
+// format makes it possible to also give them as comma separated values (?label=a,b):
+//
+//	Param("label", ArrayOf(String), func() {
+//		CollectionFormat("csv")
+//	})
+//
+// The supported formats are "csv" (comma), "ssv" (space), "tsv" (tab), "pipes" (|) and "multi"
+// (repeated keys only).
+func CollectionFormat(format string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && !a.Type.IsArray() {
+			incompatibleAttributeType("collection format", a.Type.Name(), "an array")
+			return
+		}
+		switch format {
+		case "csv", "ssv", "tsv", "pipes", "multi":
+			a.CollectionFormat = format
+		default:
+			dslengine.ReportError("invalid collection format %#v, must be one of csv, ssv, tsv, pipes or multi", format)
+		}
+	}
+}
+
 // incompatibleAttributeType reports an error for validations defined on
 // incompatible attributes (e.g. max value on string).
 func incompatibleAttributeType(validation, actual, expected string) {
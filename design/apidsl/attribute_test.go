@@ -1,6 +1,8 @@
 package apidsl_test
 
 import (
+	"time"
+
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -233,6 +235,22 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type string and a DSL defining the methods that require it", func() {
+		BeforeEach(func() {
+			name = "name"
+			dataType = String
+			dsl = func() { RequiredFor("post", "PUT") }
+		})
+
+		It("records the methods", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].RequiredFor).Should(Equal([]string{"POST", "PUT"}))
+			Ω(o[name].Validation).Should(BeNil())
+		})
+	})
+
 	Context("with a name, type integer and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -253,6 +271,227 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type number and a DSL defining exclusive bounds", func() {
+		BeforeEach(func() {
+			name = "price"
+			dataType = Number
+			dsl = func() {
+				ExclusiveMinimum(0)
+				ExclusiveMaximum(100.5)
+			}
+		})
+
+		It("produces an attribute with exclusive minimum and maximum validations", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			val := o[name].Validation
+			Ω(val).ShouldNot(BeNil())
+			Ω(val.Minimum).ShouldNot(BeNil())
+			Ω(*val.Minimum).Should(Equal(0.0))
+			Ω(val.ExclusiveMinimum).Should(BeTrue())
+			Ω(val.Maximum).ShouldNot(BeNil())
+			Ω(*val.Maximum).Should(Equal(100.5))
+			Ω(val.ExclusiveMaximum).Should(BeTrue())
+		})
+	})
+
+	Context("with a name, type number and a DSL defining a minimum", func() {
+		BeforeEach(func() {
+			name = "balance"
+			dataType = Number
+			dsl = func() { Minimum(-0.5) }
+		})
+
+		It("produces an attribute with a minimum validation", func() {
+			val := parent.Type.(Object)[name].Validation
+			Ω(val).ShouldNot(BeNil())
+			Ω(val.Minimum).ShouldNot(BeNil())
+			Ω(*val.Minimum).Should(Equal(-0.5))
+			Ω(val.ExclusiveMinimum).Should(BeFalse())
+		})
+	})
+
+	Context("with a name, type date time and a DSL defining bounds", func() {
+		BeforeEach(func() {
+			name = "expires_at"
+			dataType = DateTime
+			dsl = func() {
+				Minimum("2016-01-01T00:00:00Z")
+				ExclusiveMaximum(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC))
+			}
+		})
+
+		It("produces an attribute with time minimum and maximum validations", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			val := parent.Type.(Object)[name].Validation
+			Ω(val).ShouldNot(BeNil())
+			Ω(val.Minimum).Should(BeNil())
+			Ω(val.MinimumTime).ShouldNot(BeNil())
+			Ω(val.MinimumTime.Equal(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+			Ω(val.ExclusiveMinimum).Should(BeFalse())
+			Ω(val.MaximumTime).ShouldNot(BeNil())
+			Ω(val.MaximumTime.Equal(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC))).Should(BeTrue())
+			Ω(val.ExclusiveMaximum).Should(BeTrue())
+		})
+
+		Context("given an invalid time", func() {
+			BeforeEach(func() {
+				dsl = func() { Minimum("yesterday") }
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("RFC3339"))
+			})
+		})
+	})
+
+	Context("with a name, type integer and a DSL defining a multipleOf validation", func() {
+		BeforeEach(func() {
+			name = "quantity"
+			dataType = Integer
+			dsl = func() { MultipleOf(5) }
+		})
+
+		It("produces an attribute with a multipleOf validation", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Validation).ShouldNot(BeNil())
+			Ω(o[name].Validation.MultipleOf).ShouldNot(BeNil())
+			Ω(*o[name].Validation.MultipleOf).Should(Equal(5.0))
+		})
+	})
+
+	Context("with a name, type array and a DSL defining a collection format", func() {
+		BeforeEach(func() {
+			name = "labels"
+			dataType = ArrayOf(String)
+			dsl = func() { CollectionFormat("csv") }
+		})
+
+		It("sets the attribute collection format", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].CollectionFormat).Should(Equal("csv"))
+		})
+	})
+
+	Context("with a name, type array and a DSL defining an invalid collection format", func() {
+		BeforeEach(func() {
+			name = "labels"
+			dataType = ArrayOf(String)
+			dsl = func() { CollectionFormat("json") }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid collection format "json"`))
+		})
+	})
+
+	Context("with a name, type array and a DSL defining a uniqueItems validation", func() {
+		BeforeEach(func() {
+			name = "tags"
+			dataType = ArrayOf(String)
+			dsl = func() { UniqueItems() }
+		})
+
+		It("produces an attribute with a uniqueItems validation", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Validation).ShouldNot(BeNil())
+			Ω(o[name].Validation.UniqueItems).Should(BeTrue())
+		})
+	})
+
+	Context("with a name, type string and a DSL defining a uniqueItems validation", func() {
+		BeforeEach(func() {
+			name = "tag"
+			dataType = String
+			dsl = func() { UniqueItems() }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid uniqueItems validation definition"))
+		})
+	})
+
+	Context("with a name, type number and a DSL defining a multipleOf validation with a zero divisor", func() {
+		BeforeEach(func() {
+			name = "latitude"
+			dataType = Number
+			dsl = func() { MultipleOf(0) }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("multipleOf value must be strictly greater than 0"))
+		})
+	})
+
+	Context("with a name, type date time and a DSL defining a time format", func() {
+		BeforeEach(func() {
+			name = "due"
+			dataType = DateTime
+			dsl = func() { TimeFormat("2006-01-02") }
+		})
+
+		It("sets the attribute time format", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].TimeFormat).Should(Equal("2006-01-02"))
+		})
+	})
+
+	Context("with a name, type date time and a DSL defining a time unit", func() {
+		BeforeEach(func() {
+			name = "since"
+			dataType = DateTime
+			dsl = func() { TimeUnit("milliseconds") }
+		})
+
+		It("sets the attribute time unit", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o[name].TimeUnit).Should(Equal("milliseconds"))
+		})
+
+		Context("using an unknown unit", func() {
+			BeforeEach(func() {
+				dsl = func() { TimeUnit("hours") }
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a name, type string and a DSL defining a time format", func() {
+		BeforeEach(func() {
+			name = "due"
+			dataType = String
+			dsl = func() { TimeFormat("2006-01-02") }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a name, type string and a DSL defining an exclusive minimum", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { ExclusiveMinimum(0) }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with a name, type integer, a description and a DSL defining an enum validation", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -330,6 +569,36 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with additional properties", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Attribute("bar")
+				AdditionalProperties(AdditionalPropertiesReject)
+			}
+		})
+
+		It("records the additional properties mode", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			att := parent.Type.(Object)[name]
+			Ω(att.AdditionalProperties).Should(Equal(AdditionalPropertiesReject))
+			Ω(att.AdditionalPropertiesType).Should(BeNil())
+		})
+
+		Context("given a type", func() {
+			BeforeEach(func() {
+				dsl = func() { AdditionalProperties(Integer) }
+			})
+
+			It("sets the object type and records the additional properties type", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				att := parent.Type.(Object)[name]
+				Ω(att.Type).Should(BeAssignableToTypeOf(Object{}))
+				Ω(att.AdditionalPropertiesType).Should(Equal(Integer))
+			})
+		})
+	})
+
 	Context("with child attributes", func() {
 		const childAtt = "childAtt"
 
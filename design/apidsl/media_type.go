@@ -187,6 +187,54 @@ func Reference(t design.DataType) {
 	}
 }
 
+// Extend makes the media type inherit the attributes, links and views of the base media type.
+// Extend must appear first in the media type DSL so that the views may list the inherited
+// attributes. The media type may redefine inherited attributes, links and views, its own
+// definitions take precedence over the ones of base with the same name:
+//
+//	var Entity = MediaType("application/vnd.entity", func() {
+//		Attributes(func() {
+//			Attribute("id", Integer)
+//			Attribute("href", String)
+//			Attribute("createdAt", DateTime)
+//		})
+//		View("default", func() {
+//			Attribute("id")
+//			Attribute("href")
+//		})
+//	})
+//
+//	var Blogger = MediaType("application/vnd.blogger", func() {
+//		Extend(Entity)
+//		Attributes(func() {
+//			Attribute("name", String)
+//		})
+//		View("default", func() {
+//			Attribute("id")
+//			Attribute("name")
+//		})
+//	})
+func Extend(base *design.MediaTypeDefinition) {
+	mt, ok := mediaTypeDefinition()
+	if !ok {
+		return
+	}
+	if base == nil || base == mt {
+		dslengine.ReportError("invalid Extend argument: must be another media type")
+		return
+	}
+	// Media type DSLs run in identifier order so the base DSL may not have run yet.
+	if dsl := base.DSLFunc; dsl != nil {
+		base.DSLFunc = nil // So that it doesn't run again when the media types DSL root is executed
+		dslengine.Execute(dsl, base)
+	}
+	if !base.Type.IsObject() {
+		dslengine.ReportError("cannot extend non object media type %#v", base.Identifier)
+		return
+	}
+	mt.Extends(base)
+}
+
 // TypeName makes it possible to set the Go struct name for a type or media type in the generated
 // code. By default goagen uses the name (type) or identifier (media type) given in the apidsl and
 // computes a valid Go identifier from it. This function makes it possible to override that and
@@ -243,7 +291,7 @@ func View(name string, apidsl ...func()) {
 		if mt.Views == nil {
 			mt.Views = make(map[string]*design.ViewDefinition)
 		} else {
-			if _, ok := mt.Views[name]; ok {
+			if _, ok := mt.Views[name]; ok && (mt.Base == nil || mt.Base.Views[name] == nil) {
 				dslengine.ReportError("multiple definitions for view %#v in media type %#v", name, mt.TypeName)
 				return
 			}
@@ -301,7 +349,7 @@ func buildView(name string, mt *design.MediaTypeDefinition, at *design.Attribute
 				dup.View = cat.View
 				o[n] = dup
 			} else if n != "links" {
-				return nil, fmt.Errorf("unknown attribute %#v", n)
+				return nil, fmt.Errorf("view %#v references unknown attribute %#v", name, n)
 			}
 		}
 	}
@@ -337,7 +385,7 @@ func Link(name string, view ...string) {
 		if mt.Links == nil {
 			mt.Links = make(map[string]*design.LinkDefinition)
 		} else {
-			if _, ok := mt.Links[name]; ok {
+			if _, ok := mt.Links[name]; ok && (mt.Base == nil || mt.Base.Links[name] == nil) {
 				dslengine.ReportError("duplicate definition for link %#v", name)
 				return
 			}
@@ -219,6 +219,65 @@ var _ = Describe("MediaType", func() {
 	})
 })
 
+var _ = Describe("Extend", func() {
+	var base, mt *MediaTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		mt = MediaType("application/vnd.blogger", func() {
+			Extend(base)
+			Attributes(func() {
+				Attribute("id", String)
+				Attribute("name")
+			})
+			View("default", func() {
+				Attribute("href")
+				Attribute("name")
+			})
+		})
+		base = MediaType("application/vnd.entity", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("href")
+				Required("id")
+			})
+			View("default", func() {
+				Attribute("id")
+			})
+			View("link", func() {
+				Attribute("href")
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		dslengine.Run()
+	})
+
+	It("inherits the attributes and views of the base media type", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(mt.Base).Should(Equal(base))
+		o := mt.Type.ToObject()
+		Ω(o).Should(HaveKey("href"))
+		Ω(o).Should(HaveKey("name"))
+		Ω(mt.Validation.Required).Should(Equal([]string{"id"}))
+		Ω(mt.Views).Should(HaveKey("link"))
+		Ω(mt.Views["link"].Parent).Should(Equal(mt))
+	})
+
+	It("overrides the inherited definitions", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(mt.Type.ToObject()["id"].Type).Should(Equal(String))
+		Ω(mt.Views["default"].Type.ToObject()).Should(HaveKey("name"))
+		Ω(mt.Views["default"].Type.ToObject()).ShouldNot(HaveKey("id"))
+	})
+
+	It("leaves the base media type unchanged", func() {
+		Ω(base.Type.ToObject()).ShouldNot(HaveKey("name"))
+		Ω(base.Type.ToObject()["id"].Type).Should(Equal(Integer))
+	})
+})
+
 var _ = Describe("Duplicate media types", func() {
 	var duplicate *MediaTypeDefinition
 	const id = "application/foo"
@@ -206,6 +206,35 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Context("with a 200 response that does not specify a media type", func() {
+		const mediaType = "application/mt"
+
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Response("Success", func() {
+					Status(200)
+				})
+				DefaultMedia(mediaType, "compact")
+				Action("show", func() {
+					Routing(GET(""))
+					Response(OK)
+				})
+			}
+		})
+
+		It("inherits the resource media type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := res.Actions["show"]
+			Ω(a).ShouldNot(BeNil())
+			Ω(a.Responses).Should(HaveKey(OK))
+			Ω(a.Responses[OK].MediaType).Should(Equal(mediaType))
+			Ω(a.Responses).Should(HaveKey("Success"))
+			Ω(a.Responses["Success"].MediaType).Should(Equal(mediaType))
+			Ω(a.Responses["Success"].ViewName).Should(Equal("compact"))
+		})
+	})
+
 	Context("with an invalid media type", func() {
 		var mediaType = &MediaTypeDefinition{Identifier: "application/foo"}
 
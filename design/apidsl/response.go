@@ -37,6 +37,8 @@ import (
 //                Status(201)                     // Set response status (overrides template's)
 //        })
 //
+//        Response("Success", design.StatusRange{Min: 200, Max: 299}) // Accept any 2xx status code
+//
 //        Response("MyResponse", func() {         // Define custom response (using no template)
 //                Description("This is my response")
 //                Media(BottleMedia)
@@ -64,6 +66,9 @@ import (
 // goa also defines a default response template for the OK response which takes a single argument:
 // the identifier of the media type used to render the response. The API DSL can define additional
 // response templates or override the default OK response template using ResponseTemplate.
+// Responses with status 200 that do not specify a media type use the resource default media type
+// and view, see DefaultMedia. This holds whether DefaultMedia appears before or after the response
+// in the resource DSL.
 //
 // The media type identifier specified in a response definition via the Media function can be
 // "generic" such as "text/plain" or "application/json" or can correspond to the identifier of a
@@ -81,10 +86,6 @@ func Response(name string, paramsAndDSL ...interface{}) {
 			return
 		}
 		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
-			if resp.Status == 200 && resp.MediaType == "" {
-				resp.MediaType = def.Parent.MediaType
-				resp.ViewName = def.Parent.DefaultViewName
-			}
 			resp.Parent = def
 			def.Responses[name] = resp
 		}
@@ -98,10 +99,6 @@ func Response(name string, paramsAndDSL ...interface{}) {
 			return
 		}
 		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
-			if resp.Status == 200 && resp.MediaType == "" {
-				resp.MediaType = def.MediaType
-				resp.ViewName = def.DefaultViewName
-			}
 			resp.Parent = def
 			def.Responses[name] = resp
 		}
@@ -123,11 +120,18 @@ func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.Respon
 	var dsl func()
 	var ok bool
 	var dt design.DataType
+	var rng *design.StatusRange
 	if len(paramsAndDSL) > 0 {
 		d := paramsAndDSL[len(paramsAndDSL)-1]
 		if dsl, ok = d.(func()); ok {
 			paramsAndDSL = paramsAndDSL[:len(paramsAndDSL)-1]
 		}
+		if len(paramsAndDSL) > 0 {
+			if r, ok := paramsAndDSL[0].(design.StatusRange); ok {
+				rng = &r
+				paramsAndDSL = paramsAndDSL[1:]
+			}
+		}
 		if len(paramsAndDSL) > 0 {
 			t := paramsAndDSL[0]
 			if dt, ok = t.(design.DataType); ok {
@@ -163,6 +167,10 @@ func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.Respon
 			resp = &design.ResponseDefinition{Name: name}
 		}
 	}
+	if rng != nil {
+		resp.StatusRange = rng
+		resp.Standard = false
+	}
 	if dsl != nil {
 		if !dslengine.Execute(dsl, resp) {
 			return nil
@@ -11,6 +11,7 @@ import (
 var _ = Describe("Response", func() {
 	var name string
 	var dt DataType
+	var rng *StatusRange
 	var dsl func()
 
 	var res *ResponseDefinition
@@ -20,12 +21,15 @@ var _ = Describe("Response", func() {
 		name = ""
 		dsl = nil
 		dt = nil
+		rng = nil
 	})
 
 	JustBeforeEach(func() {
 		Resource("res", func() {
 			Action("action", func() {
-				if dt != nil {
+				if rng != nil {
+					Response(name, *rng, dsl)
+				} else if dt != nil {
 					Response(name, dt, dsl)
 				} else {
 					Response(name, dsl)
@@ -114,6 +118,34 @@ var _ = Describe("Response", func() {
 		})
 	})
 
+	Context("with a status range", func() {
+		BeforeEach(func() {
+			name = "foo"
+			rng = &StatusRange{Min: 200, Max: 299}
+		})
+
+		It("produces a valid response definition that accepts any status in the range", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.StatusRange).Should(Equal(rng))
+			Ω(res.AcceptsStatus(204)).Should(BeTrue())
+			Ω(res.AcceptsStatus(301)).Should(BeFalse())
+		})
+
+		Context("and a status outside of the range", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Status(301)
+				}
+			})
+
+			It("produces an invalid response definition", func() {
+				Ω(res).ShouldNot(BeNil())
+				Ω(res.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("with a status and name override", func() {
 		const status = 201
 
@@ -0,0 +1,498 @@
+package design
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/dslengine"
+	goauuid "github.com/goadesign/goa/uuid"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	// AdditionalPropertiesAllow keeps additional properties as is, or coerces them to the
+	// attribute AdditionalPropertiesType if set. This is the default.
+	AdditionalPropertiesAllow AdditionalPropertiesMode = iota
+	// AdditionalPropertiesIgnore drops additional properties from the coerced value.
+	AdditionalPropertiesIgnore
+	// AdditionalPropertiesReject reports an error for each additional property.
+	AdditionalPropertiesReject
+)
+
+type (
+	// CoerceReport describes the steps taken by Coerce to coerce a value.
+	CoerceReport struct {
+		// Steps lists the coercion steps in the order they were applied.
+		Steps []*CoerceStep
+		// Errors lists the coercion and validation errors.
+		Errors []error
+	}
+
+	// CoerceStep describes the coercion of a single value.
+	CoerceStep struct {
+		// Path is the path to the value from the root value, e.g. "posts[0].id". The path of
+		// the root value is the empty string.
+		Path string
+		// From is the name of the Go type of the raw value.
+		From string
+		// To is the name of the design type the value is coerced to.
+		To string
+		// Rules lists the validation rules that were applied to the coerced value, e.g.
+		// "minimum" or "pattern".
+		Rules []string
+	}
+)
+
+// Coerce coerces raw into a value of type t and validates the result against the validations
+// defined on the attributes of t. raw is typically the result of unmarshaling JSON or of parsing
+// a query string so that for example strings are coerced to integers when t is Integer. Coerce
+// returns the coerced value together with a report that describes each coercion step as well as
+// any coercion or validation error.
+func Coerce(t DataType, raw interface{}) (interface{}, *CoerceReport) {
+	report := &CoerceReport{}
+	val := report.coerce(&AttributeDefinition{Type: t}, raw, "")
+	return val, report
+}
+
+// HasErrors returns true if the coercion failed or if the coerced value is invalid.
+func (r *CoerceReport) HasErrors() bool {
+	return len(r.Errors) > 0
+}
+
+// Err returns nil if the coercion succeeded, the error if there is only one and a goa.MultiError
+// listing all the errors otherwise.
+func (r *CoerceReport) Err() error {
+	switch len(r.Errors) {
+	case 0:
+		return nil
+	case 1:
+		return r.Errors[0]
+	default:
+		return goa.MultiError(r.Errors)
+	}
+}
+
+// coerce coerces raw into the type of att, records the step and applies the attribute
+// validations.
+func (r *CoerceReport) coerce(att *AttributeDefinition, raw interface{}, path string) interface{} {
+	if raw == nil {
+		return nil
+	}
+	step := &CoerceStep{Path: path, From: reflect.TypeOf(raw).String(), To: att.Type.Name()}
+	r.Steps = append(r.Steps, step)
+	val, err := r.coerceType(att.Type, raw, path, att)
+	if err != nil {
+		r.Errors = append(r.Errors, err)
+		return raw
+	}
+	r.validate(att.Validation, val, path, step)
+	if ut, ok := att.Type.(*UserTypeDefinition); ok {
+		r.validate(ut.Validation, val, path, step)
+	} else if mt, ok := att.Type.(*MediaTypeDefinition); ok {
+		r.validate(mt.Validation, val, path, step)
+	}
+	return val
+}
+
+// coerceType coerces raw into a value of type t. props is the attribute that defines how
+// additional properties of object values are handled.
+func (r *CoerceReport) coerceType(t DataType, raw interface{}, path string, props *AttributeDefinition) (interface{}, error) {
+	switch actual := t.(type) {
+	case *UserTypeDefinition:
+		return r.coerceType(actual.Type, raw, path, additionalProps(props, actual.AttributeDefinition))
+	case *MediaTypeDefinition:
+		return r.coerceType(actual.Type, raw, path, additionalProps(props, actual.AttributeDefinition))
+	case *Array:
+		if props != nil && props.CollectionFormat != "" {
+			switch v := raw.(type) {
+			case string:
+				raw = stringsToValues(goa.SplitCollectionParam([]string{v}, props.CollectionFormat))
+			case []string:
+				raw = stringsToValues(goa.SplitCollectionParam(v, props.CollectionFormat))
+			}
+		}
+		vals, ok := arrayValues(raw)
+		if !ok {
+			return nil, coerceError(path, raw, t)
+		}
+		res := make([]interface{}, len(vals))
+		for i, v := range vals {
+			res[i] = r.coerce(actual.ElemType, v, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return res, nil
+	case *Hash:
+		vals, ok := hashValues(raw)
+		if !ok {
+			return nil, coerceError(path, raw, t)
+		}
+		res := make(map[interface{}]interface{}, len(vals))
+		for k, v := range vals {
+			p := fmt.Sprintf("%s[%q]", path, k)
+			res[r.coerce(actual.KeyType, k, p)] = r.coerce(actual.ElemType, v, p)
+		}
+		return res, nil
+	case Object:
+		vals, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, coerceError(path, raw, t)
+		}
+		res := make(map[string]interface{}, len(vals))
+		for k, v := range vals {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if att, ok := actual[k]; ok {
+				res[k] = r.coerce(att, v, p)
+				continue
+			}
+			switch {
+			case props.AdditionalProperties == AdditionalPropertiesReject:
+				r.Errors = append(r.Errors, fmt.Errorf("%s: additional property is not allowed", p))
+			case props.AdditionalProperties == AdditionalPropertiesIgnore:
+				// Drop the property.
+			case props.AdditionalPropertiesType != nil:
+				res[k] = r.coerce(&AttributeDefinition{Type: props.AdditionalPropertiesType}, v, p)
+			default:
+				res[k] = v
+			}
+		}
+		return res, nil
+	case Primitive:
+		if actual == DateTime && props != nil && props.TimeUnit != "" {
+			if n, ok := epochValue(raw); ok {
+				return goa.EpochTime(n, props.TimeUnit)
+			}
+		}
+		if s, ok := raw.(string); ok && actual == DateTime && props != nil && props.TimeFormat != "" {
+			d, err := time.Parse(props.TimeFormat, s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: cannot parse %#v using time layout %#v", displayPath(path), s, props.TimeFormat)
+			}
+			return d, nil
+		}
+		return coercePrimitive(actual, raw, path)
+	}
+	return nil, fmt.Errorf("%s: unsupported type %s", displayPath(path), t.Name())
+}
+
+// epochValue returns the integer value of raw if it is an integer, a float with no fractional
+// part or a string representing an integer.
+func epochValue(raw interface{}) (int64, bool) {
+	switch v := raw.(type) {
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > maxExactFloat {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	rv := reflect.ValueOf(raw)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	}
+	return 0, false
+}
+
+// maxExactFloat is the largest integer value that float64 values represent exactly (2^53).
+const maxExactFloat = 1 << 53
+
+// hashValues returns the content of raw as a map indexed by string keys. raw may be a map with
+// string keys of any value type or a string containing a JSON object.
+func hashValues(raw interface{}) (map[string]interface{}, bool) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		return v, true
+	case string:
+		var res map[string]interface{}
+		dec := json.NewDecoder(strings.NewReader(v))
+		dec.UseNumber()
+		if err := dec.Decode(&res); err != nil || res == nil {
+			return nil, false
+		}
+		return res, true
+	}
+	rv := reflect.ValueOf(raw)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	res := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		res[k.String()] = rv.MapIndex(k).Interface()
+	}
+	return res, true
+}
+
+// stringsToValues returns the given strings as a slice of interface{} values.
+func stringsToValues(strs []string) []interface{} {
+	res := make([]interface{}, len(strs))
+	for i, s := range strs {
+		res[i] = s
+	}
+	return res
+}
+
+// arrayValues returns the content of raw as a slice of interface{} values. raw may be a slice of
+// any element type such as the []map[string]interface{} produced by goa.BracketedParams.
+func arrayValues(raw interface{}) ([]interface{}, bool) {
+	if v, ok := raw.([]interface{}); ok {
+		return v, true
+	}
+	rv := reflect.ValueOf(raw)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	res := make([]interface{}, rv.Len())
+	for i := range res {
+		res[i] = rv.Index(i).Interface()
+	}
+	return res, true
+}
+
+// additionalProps returns the attribute defining how additional properties are handled: att if
+// it overrides the default behavior, the user type attribute ut otherwise.
+func additionalProps(att, ut *AttributeDefinition) *AttributeDefinition {
+	if att.AdditionalProperties != AdditionalPropertiesAllow || att.AdditionalPropertiesType != nil {
+		return att
+	}
+	return ut
+}
+
+// coercePrimitive coerces raw into a value of the given primitive type. Integers are always
+// coerced to int64 so that large values such as IDs are not narrowed. Use a json.Decoder with
+// UseNumber to decode JSON values given to Coerce so that integers larger than 2^53 do not lose
+// precision by being decoded as float64.
+func coercePrimitive(t Primitive, raw interface{}, path string) (interface{}, error) {
+	switch t.Kind() {
+	case BooleanKind:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	case IntegerKind:
+		switch v := raw.(type) {
+		case int:
+			return int64(v), nil
+		case int32:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= maxExactFloat {
+				return int64(v), nil
+			}
+		case json.Number:
+			if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				return i, nil
+			}
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+	case NumberKind:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return f, nil
+			}
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, nil
+			}
+		}
+	case StringKind:
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+	case DateTimeKind:
+		switch v := raw.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			if d, err := time.Parse(time.RFC3339, v); err == nil {
+				return d, nil
+			}
+		}
+	case UUIDKind:
+		switch v := raw.(type) {
+		case uuid.UUID:
+			return v, nil
+		case goauuid.UUID:
+			return uuid.UUID(v), nil
+		case [16]byte:
+			return uuid.UUID(v), nil
+		case []byte:
+			if u, err := goauuid.FromBytes(v); err == nil {
+				return uuid.UUID(u), nil
+			}
+		case string:
+			if u, err := uuid.FromString(v); err == nil {
+				return u, nil
+			}
+		}
+	case DecimalKind:
+		if d, err := goa.NewDecimal(raw); err == nil {
+			return d, nil
+		}
+	case AnyKind:
+		return raw, nil
+	}
+	return nil, coerceError(path, raw, t)
+}
+
+// validate runs the validations on val and records the applied rules in step.
+func (r *CoerceReport) validate(v *dslengine.ValidationDefinition, val interface{}, path string, step *CoerceStep) {
+	if v == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		r.Errors = append(r.Errors, fmt.Errorf("%s: %s", displayPath(path), fmt.Sprintf(format, args...)))
+	}
+	if v.Values != nil {
+		step.Rules = append(step.Rules, "enum")
+		found := false
+		for _, e := range v.Values {
+			if reflect.DeepEqual(e, val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %#v is not one of %v", val, v.Values)
+		}
+	}
+	if v.Pattern != "" {
+		if s, ok := val.(string); ok {
+			step.Rules = append(step.Rules, "pattern")
+			if re, err := regexp.Compile(v.Pattern); err == nil && !re.MatchString(s) {
+				fail("value %#v does not match the regexp %#v", s, v.Pattern)
+			}
+		}
+	}
+	if f, ok := toFloat(val); ok {
+		if v.Minimum != nil {
+			step.Rules = append(step.Rules, "minimum")
+			if v.ExclusiveMinimum && f <= *v.Minimum {
+				fail("value %v is not greater than the exclusive minimum %v", val, *v.Minimum)
+			} else if f < *v.Minimum {
+				fail("value %v is lower than the minimum %v", val, *v.Minimum)
+			}
+		}
+		if v.Maximum != nil {
+			step.Rules = append(step.Rules, "maximum")
+			if v.ExclusiveMaximum && f >= *v.Maximum {
+				fail("value %v is not lesser than the exclusive maximum %v", val, *v.Maximum)
+			} else if f > *v.Maximum {
+				fail("value %v is greater than the maximum %v", val, *v.Maximum)
+			}
+		}
+		if v.MultipleOf != nil {
+			step.Rules = append(step.Rules, "multipleOf")
+			if !isMultipleOf(val, f, *v.MultipleOf) {
+				fail("value %v is not a multiple of %v", val, *v.MultipleOf)
+			}
+		}
+	}
+	if v.UniqueItems {
+		step.Rules = append(step.Rules, "uniqueItems")
+		if dup, ok := goa.ValidateUniqueItems(val); !ok {
+			fail("duplicate item %v", dup)
+		}
+	}
+	if l, ok := length(val); ok {
+		if v.MinLength != nil {
+			step.Rules = append(step.Rules, "minLength")
+			if l < *v.MinLength {
+				fail("length %d is lower than the minimum length %d", l, *v.MinLength)
+			}
+		}
+		if v.MaxLength != nil {
+			step.Rules = append(step.Rules, "maxLength")
+			if l > *v.MaxLength {
+				fail("length %d is greater than the maximum length %d", l, *v.MaxLength)
+			}
+		}
+	}
+	if len(v.Required) > 0 {
+		if m, ok := val.(map[string]interface{}); ok {
+			step.Rules = append(step.Rules, "required")
+			for _, req := range v.Required {
+				if _, ok := m[req]; !ok {
+					fail("missing required attribute %#v", req)
+				}
+			}
+		}
+	}
+}
+
+// coerceError returns the error reported when raw cannot be coerced to t.
+func coerceError(path string, raw interface{}, t DataType) error {
+	return fmt.Errorf("%s: cannot coerce %#v to %s", displayPath(path), raw, t.Name())
+}
+
+// displayPath returns the path used in error messages.
+func displayPath(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
+
+// toFloat returns the value of numbers as a float64.
+func toFloat(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// isMultipleOf returns true if val, whose float64 value is f, is a multiple of divisor. Integers
+// are checked exactly when the divisor is integral as f may not represent them precisely.
+func isMultipleOf(val interface{}, f, divisor float64) bool {
+	if i, ok := val.(int64); ok && divisor != 0 && divisor == math.Trunc(divisor) && math.Abs(divisor) < math.MaxInt64 {
+		return i%int64(divisor) == 0
+	}
+	return goa.ValidateMultipleOf(f, divisor)
+}
+
+// length returns the length of strings, arrays and maps.
+func length(val interface{}) (int, bool) {
+	switch v := val.(type) {
+	case string:
+		return utf8.RuneCountInString(v), true
+	case []interface{}:
+		return len(v), true
+	case map[string]interface{}:
+		return len(v), true
+	case map[interface{}]interface{}:
+		return len(v), true
+	}
+	return 0, false
+}
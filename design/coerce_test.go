@@ -0,0 +1,586 @@
+package design_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/satori/go.uuid"
+)
+
+var _ = Describe("Coerce", func() {
+	var t DataType
+	var raw interface{}
+	var val interface{}
+	var report *CoerceReport
+
+	BeforeEach(func() {
+		min := 1.0
+		t = Object{
+			"post": &AttributeDefinition{
+				Type: Object{
+					"count": &AttributeDefinition{
+						Type:       Integer,
+						Validation: &dslengine.ValidationDefinition{Minimum: &min},
+					},
+				},
+			},
+		}
+		raw = map[string]interface{}{"post": map[string]interface{}{"count": "5"}}
+	})
+
+	JustBeforeEach(func() {
+		val, report = Coerce(t, raw)
+	})
+
+	It("coerces nested values", func() {
+		Ω(report.HasErrors()).Should(BeFalse())
+		Ω(val).Should(Equal(map[string]interface{}{"post": map[string]interface{}{"count": int64(5)}}))
+	})
+
+	It("reports the coercion steps and applied rules", func() {
+		Ω(report.Steps).Should(HaveLen(3))
+		Ω(*report.Steps[2]).Should(Equal(CoerceStep{
+			Path:  "post.count",
+			From:  "string",
+			To:    "integer",
+			Rules: []string{"minimum"},
+		}))
+	})
+
+	Context("with a value violating a validation", func() {
+		BeforeEach(func() {
+			raw = map[string]interface{}{"post": map[string]interface{}{"count": "0"}}
+		})
+
+		It("reports the error", func() {
+			Ω(report.Errors).Should(HaveLen(1))
+			Ω(report.Errors[0].Error()).Should(ContainSubstring("post.count: value 0 is lower than the minimum 1"))
+			Ω(report.Err()).Should(Equal(report.Errors[0]))
+		})
+	})
+
+	Context("with exclusive bounds", func() {
+		BeforeEach(func() {
+			min, max := 0.0, 10.0
+			t = Object{
+				"price": &AttributeDefinition{
+					Type: Number,
+					Validation: &dslengine.ValidationDefinition{
+						Minimum:          &min,
+						Maximum:          &max,
+						ExclusiveMinimum: true,
+						ExclusiveMaximum: true,
+					},
+				},
+			}
+		})
+
+		Context("with a value at the minimum", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"price": 0.0}
+			})
+
+			It("reports the error", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(ContainSubstring("value 0 is not greater than the exclusive minimum 0"))
+			})
+		})
+
+		Context("with a value at the maximum", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"price": 10.0}
+			})
+
+			It("reports the error", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(ContainSubstring("value 10 is not lesser than the exclusive maximum 10"))
+			})
+		})
+
+		Context("with a value within the bounds", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"price": 0.01}
+			})
+
+			It("coerces the value", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"price": 0.01}))
+			})
+		})
+	})
+
+	Context("with a date time attribute with a time unit", func() {
+		var unit string
+
+		BeforeEach(func() {
+			unit = "milliseconds"
+			raw = map[string]interface{}{"since": float64(1700000000000)}
+		})
+
+		JustBeforeEach(func() {
+			t = Object{"since": &AttributeDefinition{Type: DateTime, TimeUnit: unit}}
+			val, report = Coerce(t, raw)
+		})
+
+		It("loads integers as milliseconds", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			Ω(val).Should(Equal(map[string]interface{}{"since": time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)}))
+		})
+
+		Context("in seconds", func() {
+			BeforeEach(func() {
+				unit = "seconds"
+				raw = map[string]interface{}{"since": "1700000000"}
+			})
+
+			It("loads integers as seconds", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"since": time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)}))
+			})
+		})
+
+		Context("with a RFC3339 value", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"since": "2016-03-15T10:00:00Z"}
+			})
+
+			It("parses the value", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"since": time.Date(2016, 3, 15, 10, 0, 0, 0, time.UTC)}))
+			})
+		})
+	})
+
+	Context("with a date time attribute with a time format", func() {
+		var layout string
+
+		BeforeEach(func() {
+			layout = "2006-01-02"
+		})
+
+		JustBeforeEach(func() {
+			t = Object{"due": &AttributeDefinition{Type: DateTime, TimeFormat: layout}}
+			val, report = Coerce(t, raw)
+		})
+
+		Context("with a date only value", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"due": "2016-03-15"}
+			})
+
+			It("parses the value using the layout", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"due": time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC)}))
+			})
+		})
+
+		Context("with a value that does not match the layout", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"due": "2016-03-15T10:00:00Z"}
+			})
+
+			It("reports the error", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(Equal(`due: cannot parse "2016-03-15T10:00:00Z" using time layout "2006-01-02"`))
+			})
+		})
+
+		Context("with an explicit RFC3339 layout", func() {
+			BeforeEach(func() {
+				layout = time.RFC3339
+				raw = map[string]interface{}{"due": "2016-03-15T10:00:00+02:00"}
+			})
+
+			It("parses the value using the layout", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				due := val.(map[string]interface{})["due"].(time.Time)
+				Ω(due.UTC()).Should(Equal(time.Date(2016, 3, 15, 8, 0, 0, 0, time.UTC)))
+			})
+		})
+	})
+
+	Context("with a multipleOf validation", func() {
+		BeforeEach(func() {
+			multipleOf := 5.0
+			t = Object{
+				"quantity": &AttributeDefinition{
+					Type:       Integer,
+					Validation: &dslengine.ValidationDefinition{MultipleOf: &multipleOf},
+				},
+			}
+		})
+
+		Context("with a negative multiple", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"quantity": "-10"}
+			})
+
+			It("coerces the value", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"quantity": int64(-10)}))
+			})
+		})
+
+		Context("with a value that is not a multiple", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"quantity": "12"}
+			})
+
+			It("reports the error", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(ContainSubstring("value 12 is not a multiple of 5"))
+			})
+		})
+
+		Context("with a large value that is not a multiple", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"quantity": "9007199254741001"}
+			})
+
+			It("reports the error", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(ContainSubstring("value 9007199254741001 is not a multiple of 5"))
+			})
+		})
+	})
+
+	Context("with an array attribute using the csv collection format", func() {
+		BeforeEach(func() {
+			t = Object{
+				"label": &AttributeDefinition{
+					Type:             &Array{ElemType: &AttributeDefinition{Type: Integer}},
+					CollectionFormat: "csv",
+				},
+			}
+			raw = map[string]interface{}{"label": "1,2"}
+		})
+
+		It("splits and coerces the elements", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			Ω(val).Should(Equal(map[string]interface{}{"label": []interface{}{int64(1), int64(2)}}))
+		})
+
+		Context("given repeated values", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"label": []string{"1,2", "3"}}
+			})
+
+			It("splits each value", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"label": []interface{}{int64(1), int64(2), int64(3)}}))
+			})
+		})
+	})
+
+	Context("with a uniqueItems validation", func() {
+		BeforeEach(func() {
+			t = Object{
+				"tags": &AttributeDefinition{
+					Type:       &Array{ElemType: &AttributeDefinition{Type: String}},
+					Validation: &dslengine.ValidationDefinition{UniqueItems: true},
+				},
+				"items": &AttributeDefinition{
+					Type: &Array{ElemType: &AttributeDefinition{Type: Object{
+						"name": &AttributeDefinition{Type: String},
+						"qty":  &AttributeDefinition{Type: Integer},
+					}}},
+					Validation: &dslengine.ValidationDefinition{UniqueItems: true},
+				},
+			}
+		})
+
+		Context("with unique elements", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{
+					"tags":  []interface{}{"a", "b"},
+					"items": []interface{}{map[string]interface{}{"name": "a", "qty": "1"}, map[string]interface{}{"name": "a", "qty": 2}},
+				}
+			})
+
+			It("coerces the value", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+			})
+		})
+
+		Context("with duplicate primitive elements", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"tags": []interface{}{"a", "b", "a"}}
+			})
+
+			It("reports the duplicate", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(ContainSubstring("tags: duplicate item a"))
+			})
+		})
+
+		Context("with duplicate object elements", func() {
+			BeforeEach(func() {
+				raw = map[string]interface{}{"items": []interface{}{
+					map[string]interface{}{"name": "a", "qty": "1"},
+					map[string]interface{}{"name": "a", "qty": 1},
+				}}
+			})
+
+			It("compares the coerced values", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(ContainSubstring("items: duplicate item"))
+			})
+		})
+	})
+
+	Context("with a value that cannot be coerced", func() {
+		BeforeEach(func() {
+			raw = map[string]interface{}{"post": map[string]interface{}{"count": "five"}}
+		})
+
+		It("reports the error", func() {
+			Ω(report.Errors).Should(HaveLen(1))
+			Ω(report.Errors[0].Error()).Should(Equal(`post.count: cannot coerce "five" to integer`))
+		})
+	})
+
+	Context("with integers near and above the 32-bit limit", func() {
+		BeforeEach(func() {
+			t = &Array{ElemType: &AttributeDefinition{Type: Integer}}
+			raw = []interface{}{
+				json.Number(strconv.FormatInt(math.MaxInt32, 10)),
+				json.Number(strconv.FormatInt(math.MaxInt32+1, 10)),
+				"9007199254740993",
+				json.Number("9223372036854775807"),
+			}
+		})
+
+		It("coerces them to int64 without losing precision", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			Ω(val).Should(Equal([]interface{}{
+				int64(math.MaxInt32),
+				int64(math.MaxInt32 + 1),
+				int64(9007199254740993),
+				int64(math.MaxInt64),
+			}))
+		})
+	})
+
+	Context("with a float too large to represent an integer exactly", func() {
+		BeforeEach(func() {
+			t = Integer
+			raw = float64(1 << 60)
+		})
+
+		It("reports the error", func() {
+			Ω(report.HasErrors()).Should(BeTrue())
+		})
+	})
+
+	Context("with UUIDs", func() {
+		const id = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+		var expected uuid.UUID
+
+		BeforeEach(func() {
+			expected = uuid.FromStringOrNil(id)
+			t = &Array{ElemType: &AttributeDefinition{Type: UUID}}
+			raw = []interface{}{
+				id,
+				strings.ToUpper(id),
+				"{" + id + "}",
+				"urn:uuid:" + id,
+				[16]byte(expected),
+				expected.Bytes(),
+			}
+		})
+
+		It("coerces the supported formats to the canonical UUID", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			for _, v := range val.([]interface{}) {
+				Ω(v).Should(Equal(expected))
+			}
+		})
+
+		Context("with the nil UUID", func() {
+			BeforeEach(func() {
+				raw = []interface{}{"00000000-0000-0000-0000-000000000000"}
+			})
+
+			It("coerces it", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal([]interface{}{uuid.Nil}))
+			})
+		})
+
+		Context("with a malformed UUID", func() {
+			BeforeEach(func() {
+				raw = []interface{}{"6ba7b810-9dad-11d1-80b4", []byte{1, 2, 3}}
+			})
+
+			It("reports the errors", func() {
+				Ω(report.Errors).Should(HaveLen(2))
+				Ω(report.Err()).Should(BeAssignableToTypeOf(goa.MultiError{}))
+				Ω(report.Err().(goa.MultiError).Errors()).Should(Equal(report.Errors))
+			})
+		})
+	})
+
+	Context("with decimals", func() {
+		BeforeEach(func() {
+			t = &Array{ElemType: &AttributeDefinition{Type: Decimal}}
+			raw = []interface{}{"19.99", 19.99, json.Number("19.99")}
+		})
+
+		It("coerces the strings and numbers to exact decimals", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			for _, v := range val.([]interface{}) {
+				Ω(v).Should(BeAssignableToTypeOf(goa.Decimal{}))
+				Ω(v.(goa.Decimal).String()).Should(Equal("19.99"))
+			}
+		})
+
+		Context("with a malformed decimal", func() {
+			BeforeEach(func() {
+				raw = []interface{}{"19,99", true}
+			})
+
+			It("reports the errors", func() {
+				Ω(report.Errors).Should(HaveLen(2))
+			})
+		})
+	})
+
+	Context("with additional properties in a nested object", func() {
+		var post *AttributeDefinition
+
+		BeforeEach(func() {
+			post = &AttributeDefinition{Type: Object{"title": &AttributeDefinition{Type: String}}}
+			t = Object{"post": post}
+			raw = map[string]interface{}{"post": map[string]interface{}{"title": "hello", "views": "42"}}
+		})
+
+		It("keeps them by default", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			Ω(val).Should(Equal(map[string]interface{}{"post": map[string]interface{}{"title": "hello", "views": "42"}}))
+		})
+
+		Context("when rejected", func() {
+			BeforeEach(func() {
+				post.AdditionalProperties = AdditionalPropertiesReject
+			})
+
+			It("reports the error", func() {
+				Ω(report.Errors).Should(HaveLen(1))
+				Ω(report.Errors[0].Error()).Should(Equal("post.views: additional property is not allowed"))
+			})
+		})
+
+		Context("when ignored", func() {
+			BeforeEach(func() {
+				post.AdditionalProperties = AdditionalPropertiesIgnore
+			})
+
+			It("drops them", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"post": map[string]interface{}{"title": "hello"}}))
+			})
+		})
+
+		Context("when typed", func() {
+			BeforeEach(func() {
+				post.AdditionalPropertiesType = Integer
+			})
+
+			It("coerces them to the type", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[string]interface{}{"post": map[string]interface{}{"title": "hello", "views": int64(42)}}))
+			})
+		})
+	})
+
+	Context("with hashes", func() {
+		BeforeEach(func() {
+			t = &Hash{
+				KeyType:  &AttributeDefinition{Type: String},
+				ElemType: &AttributeDefinition{Type: Integer},
+			}
+		})
+
+		expected := map[interface{}]interface{}{"a": int64(1), "b": int64(2)}
+		for _, c := range []struct {
+			desc string
+			raw  interface{}
+		}{
+			{"a map[string]interface{}", map[string]interface{}{"a": "1", "b": 2.0}},
+			{"a map[string]int", map[string]int{"a": 1, "b": 2}},
+			{"a JSON string", `{"a": 1, "b": "2"}`},
+		} {
+			c := c
+			Context("given "+c.desc, func() {
+				BeforeEach(func() {
+					raw = c.raw
+				})
+
+				It("coerces the values recursively", func() {
+					Ω(report.HasErrors()).Should(BeFalse())
+					Ω(val).Should(Equal(expected))
+				})
+			})
+		}
+
+		Context("given nested typed maps", func() {
+			BeforeEach(func() {
+				t = &Hash{
+					KeyType:  &AttributeDefinition{Type: String},
+					ElemType: &AttributeDefinition{Type: t},
+				}
+				raw = map[string]map[string]int{"x": {"a": 1, "b": 2}}
+			})
+
+			It("coerces the nested maps", func() {
+				Ω(report.HasErrors()).Should(BeFalse())
+				Ω(val).Should(Equal(map[interface{}]interface{}{"x": expected}))
+			})
+		})
+
+		Context("given a string that is not a JSON object", func() {
+			BeforeEach(func() {
+				raw = `[1, 2]`
+			})
+
+			It("reports the error", func() {
+				Ω(report.HasErrors()).Should(BeTrue())
+			})
+		})
+	})
+
+	Context("with an array of objects read from bracketed query params", func() {
+		BeforeEach(func() {
+			t = &Array{ElemType: &AttributeDefinition{Type: Object{
+				"name": &AttributeDefinition{Type: String},
+				"qty":  &AttributeDefinition{Type: Integer},
+			}}}
+			params := url.Values{
+				"items[0][name]": {"x"},
+				"items[0][qty]":  {"2"},
+				"items[1][name]": {"y"},
+				"items[1][qty]":  {"3"},
+			}
+			items, err := goa.BracketedParams(params, "items")
+			Ω(err).ShouldNot(HaveOccurred())
+			raw = items
+		})
+
+		It("coerces the elements", func() {
+			Ω(report.HasErrors()).Should(BeFalse())
+			Ω(val).Should(Equal([]interface{}{
+				map[string]interface{}{"name": "x", "qty": int64(2)},
+				map[string]interface{}{"name": "y", "qty": int64(3)},
+			}))
+		})
+	})
+})
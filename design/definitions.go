@@ -6,6 +6,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -73,6 +74,9 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// FailFast causes the generated validation code to return the first validation
+		// error instead of collecting all of them.
+		FailFast bool
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -203,6 +207,16 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Standard is true if the response definition comes from the goa default responses
 		Standard bool
+		// StatusRange if not nil defines the range of status codes accepted by the response
+		StatusRange *StatusRange
+	}
+
+	// StatusRange defines an inclusive range of HTTP status codes, e.g. StatusRange{Min: 200, Max: 299}.
+	StatusRange struct {
+		// Min is the lowest status code in the range
+		Min int
+		// Max is the highest status code in the range
+		Max int
 	}
 
 	// ResponseTemplateDefinition defines a response template.
@@ -244,6 +258,24 @@ type (
 		Payload *UserTypeDefinition
 		// PayloadOptional is true if the request payload is optional, false otherwise.
 		PayloadOptional bool
+		// MaxPayloadBytes is the maximum length of the request body in bytes if greater than
+		// 0. Longer request bodies are rejected with a 413 response.
+		MaxPayloadBytes int64
+		// Compress overrides the service response compression settings if not empty, one of
+		// "auto", "always" or "never".
+		Compress string
+		// Timeout is the maximum duration of the action if greater than 0. Actions that do
+		// not respond in time are given a 503 response.
+		Timeout time.Duration
+		// Idempotent is true if the action was explicitly declared idempotent so that clients
+		// may safely retry requests made to it.
+		Idempotent bool
+		// Coalesce is true if concurrent identical GET requests made to the action may share
+		// a single execution of the action.
+		Coalesce bool
+		// LinkRelations lists the relation types of the links the action may add to its
+		// responses "Link" header, any relation type is accepted if empty.
+		LinkRelations []string
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
 		// Metadata is a list of key/value pairs
@@ -330,9 +362,32 @@ type (
 		// NonZeroAttributes lists the names of the child attributes that cannot have a
 		// zero value (and thus whose presence does not need to be validated).
 		NonZeroAttributes map[string]bool
+		// RequiredFor lists the HTTP methods of the requests whose payload must include the
+		// attribute. It only applies to the top level attributes of action payloads.
+		RequiredFor []string
 		// DSLFunc contains the initialization DSL. This is used for user types.
 		DSLFunc func()
-	}
+		// AdditionalProperties defines how keys that do not match any child attribute are
+		// handled when decoding payloads and coercing object values.
+		AdditionalProperties AdditionalPropertiesMode
+		// AdditionalPropertiesType is the type additional properties are coerced to if any.
+		AdditionalPropertiesType DataType
+		// TimeFormat is the layout used to parse date-time values as accepted by time.Parse.
+		// Values are parsed as RFC3339 timestamps if empty. It only applies to parameters,
+		// headers and Coerce: payloads and media types always use RFC3339.
+		TimeFormat string
+		// TimeUnit is the unit of integer date-time values given as the number of "seconds"
+		// or "milliseconds" elapsed since the Unix epoch. Integer values are rejected if
+		// empty. Like TimeFormat it only applies to parameters, headers and Coerce.
+		TimeUnit string
+		// CollectionFormat is the format of array parameters values as defined by
+		// goa.SplitCollectionParam: "csv", "ssv", "tsv", "pipes" or "multi". The elements of
+		// array parameters are read from repeated keys ("multi") if empty.
+		CollectionFormat string
+	}
+
+	// AdditionalPropertiesMode defines how additional properties of objects are handled.
+	AdditionalPropertiesMode int
 
 	// ContainerDefinition defines a generic container definition that contains attributes.
 	// This makes it possible for plugins to use attributes in their own data structures.
@@ -832,6 +887,12 @@ func (r *ResourceDefinition) DSL() func() {
 // parameters, initializes querystring parameters, sets path parameters as non zero attributes
 // and sets the fallbacks for security schemes.
 func (r *ResourceDefinition) Finalize() {
+	for _, resp := range r.Responses {
+		if resp.Status == 200 && resp.MediaType == "" {
+			resp.MediaType = r.MediaType
+			resp.ViewName = r.DefaultViewName
+		}
+	}
 	meta := r.Metadata["swagger:generate"]
 	r.IterateFileServers(func(f *FileServerDefinition) error {
 		if meta != nil {
@@ -982,6 +1043,25 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 	return false
 }
 
+// IsFile returns true if the attribute type is File or an array of File.
+func (a *AttributeDefinition) IsFile() bool {
+	if a.Type.IsArray() {
+		return a.Type.ToArray().ElemType.Type.Kind() == FileKind
+	}
+	return a.Type.Kind() == FileKind
+}
+
+// HasFiles returns true if the attribute is an object with at least one attribute of type File or
+// array of File. The payloads with files are sent in multipart/form-data request bodies.
+func (a *AttributeDefinition) HasFiles() bool {
+	for _, att := range a.Type.ToObject() {
+		if att.IsFile() {
+			return true
+		}
+	}
+	return false
+}
+
 // SetExample sets the custom example. SetExample also handles the case when the user doesn't
 // want any example or any auto-generated example.
 func (a *AttributeDefinition) SetExample(example interface{}) bool {
@@ -1249,8 +1329,12 @@ func (r *ResponseDefinition) Context() string {
 }
 
 // Finalize sets the response media type from its type if the type is a media type and no media
-// type is already specified.
+// type is already specified. It also defaults the status of responses that define a status range
+// to the lowest code in the range.
 func (r *ResponseDefinition) Finalize() {
+	if r.Status == 0 && r.StatusRange != nil {
+		r.Status = r.StatusRange.Min
+	}
 	if r.Type == nil {
 		return
 	}
@@ -1264,6 +1348,23 @@ func (r *ResponseDefinition) Finalize() {
 	r.MediaType = mt.Identifier
 }
 
+// AcceptsStatus returns true if the given status code is valid for the response: either it matches
+// the response status or it falls within the response status range.
+func (r *ResponseDefinition) AcceptsStatus(status int) bool {
+	if r.StatusRange != nil {
+		return r.StatusRange.Contains(status)
+	}
+	return r.Status == status
+}
+
+// lowestStatus returns the lowest status code accepted by the response.
+func (r *ResponseDefinition) lowestStatus() int {
+	if r.StatusRange != nil {
+		return r.StatusRange.Min
+	}
+	return r.Status
+}
+
 // Dup returns a copy of the response definition.
 func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	res := ResponseDefinition{
@@ -1276,6 +1377,10 @@ func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
 	}
+	if r.StatusRange != nil {
+		rng := *r.StatusRange
+		res.StatusRange = &rng
+	}
 	return &res
 }
 
@@ -1290,6 +1395,10 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 	if r.Status == 0 {
 		r.Status = other.Status
 	}
+	if r.StatusRange == nil && other.StatusRange != nil {
+		rng := *other.StatusRange
+		r.StatusRange = &rng
+	}
 	if r.Description == "" {
 		r.Description = other.Description
 	}
@@ -1313,6 +1422,16 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 	}
 }
 
+// Contains returns true if the given status code is within the range.
+func (r *StatusRange) Contains(status int) bool {
+	return status >= r.Min && status <= r.Max
+}
+
+// String returns a human friendly representation of the range, e.g. "200-299".
+func (r *StatusRange) String() string {
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
 // Context returns the generic definition name used in error messages.
 func (r *ResponseTemplateDefinition) Context() string {
 	if r.Name != "" {
@@ -1432,6 +1551,23 @@ func (a *ActionDefinition) WebSocket() bool {
 	return true
 }
 
+// Retryable returns true if requests made to the action may safely be retried by clients, that is
+// if the action was declared idempotent or if all its routes use safe HTTP methods (GET or HEAD).
+func (a *ActionDefinition) Retryable() bool {
+	if a.Idempotent {
+		return true
+	}
+	if len(a.Routes) == 0 {
+		return false
+	}
+	for _, r := range a.Routes {
+		if r.Verb != "GET" && r.Verb != "HEAD" {
+			return false
+		}
+	}
+	return true
+}
+
 // Finalize inherits security scheme and action responses from parent and top level design.
 func (a *ActionDefinition) Finalize() {
 	// Inherit security scheme
@@ -1534,6 +1670,12 @@ func (a *ActionDefinition) mergeResponses() {
 		if dr, ok := Design.DefaultResponses[name]; ok {
 			resp.Merge(dr)
 		}
+		if resp.Status == 200 && resp.MediaType == "" {
+			// Inherit the resource media type once the resource DSL has run so that the
+			// order of the Response and DefaultMedia declarations does not matter.
+			resp.MediaType = a.Parent.MediaType
+			resp.ViewName = a.Parent.DefaultViewName
+		}
 	}
 }
 
@@ -47,9 +47,16 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 		Metadata:          att.Metadata,
 		DefaultValue:      att.DefaultValue,
 		NonZeroAttributes: att.NonZeroAttributes,
+		RequiredFor:       att.RequiredFor,
 		View:              att.View,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
+
+		AdditionalProperties:     att.AdditionalProperties,
+		AdditionalPropertiesType: att.AdditionalPropertiesType,
+		TimeFormat:               att.TimeFormat,
+		TimeUnit:                 att.TimeUnit,
+		CollectionFormat:         att.CollectionFormat,
 	}
 	return &dup
 }
@@ -3,9 +3,11 @@ package design
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"regexp"
 	"time"
 
+	"github.com/goadesign/goa"
 	regen "github.com/zach-klippenstein/goregen"
 )
 
@@ -25,6 +27,14 @@ const maxAttempts = 500
 
 // Generate generates a random value based on the given validations.
 func (eg *exampleGenerator) Generate(seen []string) interface{} {
+	if eg.hasUniqueItemsValidation() {
+		return eg.generateUniqueItemsExample(seen)
+	}
+	return eg.generate(seen)
+}
+
+// generate generates a random value based on the validations other than uniqueItems.
+func (eg *exampleGenerator) generate(seen []string) interface{} {
 	// Randomize array length first, since that's from higher level
 	if eg.hasLengthValidation() {
 		return eg.generateValidatedLengthExample(seen)
@@ -58,6 +68,15 @@ func (eg *exampleGenerator) Generate(seen []string) interface{} {
 				continue
 			}
 		}
+		if eg.hasMultipleOfValidation() {
+			if example == nil {
+				example = eg.a.Type.GenerateExample(eg.r, seen)
+			}
+			example = eg.roundToMultipleOf(example)
+			if hasMinMax && !eg.checkMinMaxValueValidation(example) {
+				continue
+			}
+		}
 		if example == nil {
 			example = eg.a.Type.GenerateExample(eg.r, seen)
 		}
@@ -111,6 +130,39 @@ func (eg *exampleGenerator) hasLengthValidation() bool {
 
 const maxExampleLength = 10
 
+func (eg *exampleGenerator) hasUniqueItemsValidation() bool {
+	return eg.a.Validation != nil && eg.a.Validation.UniqueItems && eg.a.Type.IsArray()
+}
+
+// generateUniqueItemsExample generates an array example whose elements are all different. It
+// removes the duplicates of the last generated example if no attempt succeeds, for example
+// because the element type does not have enough distinct values.
+func (eg *exampleGenerator) generateUniqueItemsExample(seen []string) interface{} {
+	var example interface{}
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		example = eg.generate(seen)
+		if _, ok := goa.ValidateUniqueItems(example); ok {
+			return example
+		}
+	}
+	v := reflect.ValueOf(example)
+	res := reflect.MakeSlice(v.Type(), 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		dup := false
+		for j := 0; j < res.Len(); j++ {
+			if reflect.DeepEqual(res.Index(j).Interface(), elem.Interface()) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			res = reflect.Append(res, elem)
+		}
+	}
+	return res.Interface()
+}
+
 // generateValidatedLengthExample generates a random size array of examples based on what's given.
 func (eg *exampleGenerator) generateValidatedLengthExample(seen []string) interface{} {
 	count := eg.ExampleLength()
@@ -218,9 +270,7 @@ func (eg *exampleGenerator) checkMinMaxValueValidation(example interface{}) bool
 	}
 	valid := true
 	if min := eg.a.Validation.Minimum; min != nil {
-		if v, ok := example.(int); ok && float64(v) < *min {
-			valid = false
-		} else if v, ok := example.(float64); ok && v < *min {
+		if v, ok := toFloat(example); ok && (v < *min || eg.a.Validation.ExclusiveMinimum && v == *min) {
 			valid = false
 		}
 	}
@@ -228,15 +278,30 @@ func (eg *exampleGenerator) checkMinMaxValueValidation(example interface{}) bool
 		return false
 	}
 	if max := eg.a.Validation.Maximum; max != nil {
-		if v, ok := example.(int); ok && float64(v) > *max {
-			return false
-		} else if v, ok := example.(float64); ok && v > *max {
+		if v, ok := toFloat(example); ok && (v > *max || eg.a.Validation.ExclusiveMaximum && v == *max) {
 			return false
 		}
 	}
 	return true
 }
 
+func (eg *exampleGenerator) hasMultipleOfValidation() bool {
+	return eg.a.Validation != nil && eg.a.Validation.MultipleOf != nil
+}
+
+// roundToMultipleOf rounds the given numerical example up to the closest multiple of the
+// multipleOf validation divisor.
+func (eg *exampleGenerator) roundToMultipleOf(example interface{}) interface{} {
+	d := *eg.a.Validation.MultipleOf
+	switch v := example.(type) {
+	case int:
+		return int(math.Ceil(float64(v)/d) * d)
+	case float64:
+		return math.Ceil(v/d) * d
+	}
+	return example
+}
+
 func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 	if !eg.hasMinMaxValidation() {
 		return nil
@@ -244,9 +309,23 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 	min, max := math.Inf(1), math.Inf(-1)
 	if eg.a.Validation.Minimum != nil {
 		min = *eg.a.Validation.Minimum
+		if eg.a.Validation.ExclusiveMinimum {
+			if eg.a.Type.Kind() == IntegerKind {
+				min = math.Floor(min) + 1
+			} else {
+				min = math.Nextafter(min, math.Inf(1))
+			}
+		}
 	}
 	if eg.a.Validation.Maximum != nil {
 		max = *eg.a.Validation.Maximum
+		if eg.a.Validation.ExclusiveMaximum {
+			if eg.a.Type.Kind() == IntegerKind {
+				max = math.Ceil(max) - 1
+			} else {
+				max = math.Nextafter(max, math.Inf(-1))
+			}
+		}
 	}
 	if math.IsInf(min, 1) {
 		if eg.a.Type.Kind() == IntegerKind {
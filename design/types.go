@@ -14,9 +14,11 @@ import (
 	"mime"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/satori/go.uuid"
 )
@@ -135,6 +137,9 @@ type (
 		Views map[string]*ViewDefinition
 		// Resource this media type is the canonical representation for if any
 		Resource *ResourceDefinition
+		// Base is the media type whose members, links and views this media type inherits
+		// if any, see Extends.
+		Base *MediaTypeDefinition
 	}
 )
 
@@ -163,6 +168,10 @@ const (
 	UserTypeKind
 	// MediaTypeKind represents a media type.
 	MediaTypeKind
+	// FileKind represents a file uploaded in a multipart request body.
+	FileKind
+	// DecimalKind represents a JSON string or number that is parsed as a goa.Decimal.
+	DecimalKind
 )
 
 const (
@@ -188,6 +197,14 @@ const (
 
 	// Any is the type for an arbitrary JSON value (interface{} in Go).
 	Any = Primitive(AnyKind)
+
+	// File is the type for a file uploaded in a multipart/form-data request body
+	// (multipart.FileHeader in Go). File attributes may only be used in payloads.
+	File = Primitive(FileKind)
+
+	// Decimal is the type for an exact decimal number such as an amount of money (goa.Decimal
+	// in Go). Decimal values are rendered as JSON strings and accept strings and numbers.
+	Decimal = Primitive(DecimalKind)
 )
 
 // DataType implementation
@@ -204,10 +221,12 @@ func (p Primitive) Name() string {
 		return "integer"
 	case Number:
 		return "number"
-	case String, DateTime, UUID:
+	case String, DateTime, UUID, Decimal:
 		return "string"
 	case Any:
 		return "any"
+	case File:
+		return "file"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -248,7 +267,7 @@ func (p Primitive) CanHaveDefault() (ok bool) {
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any && p != File && p != Decimal {
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -258,11 +277,11 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 	case bool:
 		return p == Boolean
 	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-		return p == Integer || p == Number
+		return p == Integer || p == Number || p == Decimal
 	case float32, float64:
-		return p == Number
+		return p == Number || p == Decimal
 	case string:
-		if p == String {
+		if p == String || p == File {
 			return true
 		}
 		if p == DateTime {
@@ -273,6 +292,10 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 			_, err := uuid.FromString(val.(string))
 			return err == nil
 		}
+		if p == Decimal {
+			_, err := goa.ParseDecimal(val.(string))
+			return err == nil
+		}
 	}
 	return false
 }
@@ -297,6 +320,10 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 	case Any:
 		// to not make it too complicated, pick one of the primitive types
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
+	case File:
+		return r.String() + ".txt"
+	case Decimal:
+		return strconv.FormatFloat(r.Float64(), 'f', 2, 64)
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -351,7 +378,7 @@ func (a *Array) IsCompatible(val interface{}) bool {
 	}
 	v := reflect.ValueOf(val)
 	for i := 0; i < v.Len(); i++ {
-		compat := (a.ElemType.Type != nil) && a.ElemType.Type.IsCompatible(v.Index(i).Interface())
+		compat := (a.ElemType.Type != nil) && isCompatibleValue(a.ElemType.Type, v.Index(i).Interface())
 		if !compat {
 			return false
 		}
@@ -419,10 +446,66 @@ func (o Object) Merge(other Object) {
 	}
 }
 
-// IsCompatible returns true if val is compatible with p.
+// IsCompatible returns true if val is compatible with p. The values of map keys and struct fields
+// that match the name of an attribute must be compatible with the attribute type. The Go types of
+// struct fields are checked as well so that mismatches are detected even for fields holding empty
+// collections.
 func (o Object) IsCompatible(val interface{}) bool {
-	k := reflect.TypeOf(val).Kind()
-	return k == reflect.Map || k == reflect.Struct
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			name, ok := key.Interface().(string)
+			if !ok {
+				continue
+			}
+			if att, ok := o[name]; ok && !isCompatibleValue(att.Type, v.MapIndex(key).Interface()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue // unexported field
+			}
+			name := f.Name
+			if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			att := o.fieldAttribute(name)
+			if att == nil {
+				continue
+			}
+			if !isCompatibleType(att.Type, f.Type) {
+				return false
+			}
+			if fv := v.Field(i); !fv.IsZero() && !isCompatibleValue(att.Type, fv.Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// fieldAttribute returns the attribute matching the struct field with the given name, the
+// comparison falls back to a case insensitive match so that Go field names match the
+// corresponding attributes. fieldAttribute returns nil if there is no such attribute.
+func (o Object) fieldAttribute(name string) *AttributeDefinition {
+	if att, ok := o[name]; ok {
+		return att
+	}
+	for n, att := range o {
+		if strings.EqualFold(n, name) {
+			return att
+		}
+	}
+	return nil
 }
 
 // GenerateExample returns a random value of the object.
@@ -490,8 +573,8 @@ func (h *Hash) IsCompatible(val interface{}) bool {
 	}
 	v := reflect.ValueOf(val)
 	for _, key := range v.MapKeys() {
-		keyCompat := h.KeyType.Type == nil || h.KeyType.Type.IsCompatible(key.Interface())
-		elemCompat := h.ElemType.Type == nil || h.ElemType.Type.IsCompatible(v.MapIndex(key).Interface())
+		keyCompat := h.KeyType.Type == nil || isCompatibleValue(h.KeyType.Type, key.Interface())
+		elemCompat := h.ElemType.Type == nil || isCompatibleValue(h.ElemType.Type, v.MapIndex(key).Interface())
 		if !keyCompat || !elemCompat {
 			return false
 		}
@@ -728,6 +811,54 @@ func (m *MediaTypeDefinition) Finalize() {
 	m.UserTypeDefinition.Finalize()
 }
 
+// Extends merges the members, links and views of base into m. Members, links and views defined
+// by m take precedence over the ones of base with the same name. Extends must be called once the
+// DSL of base has been executed. It sets Base and returns m.
+func (m *MediaTypeDefinition) Extends(base *MediaTypeDefinition) *MediaTypeDefinition {
+	if base == nil || base.AttributeDefinition == nil || !base.Type.IsObject() {
+		return m
+	}
+	m.Base = base
+	if m.AttributeDefinition == nil {
+		m.AttributeDefinition = &AttributeDefinition{}
+	}
+	if m.Type == nil {
+		m.Type = Object{}
+	}
+	o := m.Type.ToObject()
+	if o == nil {
+		return m
+	}
+	for n, att := range base.Type.ToObject() {
+		if _, ok := o[n]; !ok {
+			o[n] = DupAtt(att)
+		}
+	}
+	if base.Validation != nil {
+		if m.Validation == nil {
+			m.Validation = &dslengine.ValidationDefinition{}
+		}
+		m.Validation.AddRequired(base.Validation.Required)
+	}
+	for n, l := range base.Links {
+		if m.Links == nil {
+			m.Links = make(map[string]*LinkDefinition)
+		}
+		if _, ok := m.Links[n]; !ok {
+			m.Links[n] = &LinkDefinition{Name: l.Name, View: l.View, URITemplate: l.URITemplate, Parent: m}
+		}
+	}
+	for n, v := range base.Views {
+		if m.Views == nil {
+			m.Views = make(map[string]*ViewDefinition)
+		}
+		if _, ok := m.Views[n]; !ok {
+			m.Views[n] = &ViewDefinition{AttributeDefinition: DupAtt(v.AttributeDefinition), Name: v.Name, Parent: m}
+		}
+	}
+	return m
+}
+
 // ViewIterator is the type of the function given to IterateViews.
 type ViewIterator func(*ViewDefinition) error
 
@@ -1048,3 +1179,82 @@ func toReflectType(dtype DataType) reflect.Type {
 		return reflect.TypeOf([]interface{}{}).Elem()
 	}
 }
+
+// isCompatibleValue returns true if val is compatible with dt. Contrary to IsCompatible it
+// accepts nil values and dereferences pointers so that it can be used on the elements of
+// collections and on struct fields.
+func isCompatibleValue(dt DataType, val interface{}) bool {
+	if dt == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Interface().(type) {
+	case time.Time:
+		return dt.Kind() == DateTimeKind || dt.Kind() == AnyKind
+	case uuid.UUID:
+		return dt.Kind() == UUIDKind || dt.Kind() == AnyKind
+	case goa.Decimal:
+		return dt.Kind() == DecimalKind || dt.Kind() == AnyKind
+	}
+	return dt.IsCompatible(v.Interface())
+}
+
+// isCompatibleType returns true if values of the Go type t may be compatible with dt. It makes it
+// possible to detect mismatches in struct fields holding empty collections where there is no
+// element value to check.
+func isCompatibleType(dt DataType, t reflect.Type) bool {
+	if dt == nil {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	k := t.Kind()
+	if k == reflect.Interface {
+		return true
+	}
+	switch u := dt.(type) {
+	case *MediaTypeDefinition:
+		return u.UserTypeDefinition == nil || u.Type == nil || isCompatibleType(u.Type, t)
+	case *UserTypeDefinition:
+		return u.Type == nil || isCompatibleType(u.Type, t)
+	}
+	switch dt.Kind() {
+	case AnyKind:
+		return true
+	case BooleanKind:
+		return k == reflect.Bool
+	case IntegerKind:
+		return k >= reflect.Int && k <= reflect.Uint64
+	case NumberKind:
+		return k >= reflect.Int && k <= reflect.Float64
+	case StringKind:
+		return k == reflect.String
+	case DateTimeKind, UUIDKind:
+		return k == reflect.String || k == reflect.Struct || k == reflect.Array
+	case DecimalKind:
+		return k == reflect.String || k == reflect.Struct || (k >= reflect.Int && k <= reflect.Float64)
+	case ArrayKind:
+		return (k == reflect.Slice || k == reflect.Array) &&
+			isCompatibleType(dt.ToArray().ElemType.Type, t.Elem())
+	case HashKind:
+		h := dt.ToHash()
+		return k == reflect.Map &&
+			isCompatibleType(h.KeyType.Type, t.Key()) && isCompatibleType(h.ElemType.Type, t.Elem())
+	case ObjectKind:
+		if k == reflect.Map {
+			return t.Key().Kind() == reflect.String || t.Key().Kind() == reflect.Interface
+		}
+		return k == reflect.Struct
+	}
+	return true
+}
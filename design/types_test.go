@@ -4,6 +4,7 @@ import (
 	"errors"
 	"mime"
 	"sync"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -74,6 +75,95 @@ var _ = Describe("IsObject", func() {
 	})
 })
 
+var _ = Describe("IsCompatible", func() {
+	type Task struct {
+		Title string
+	}
+
+	var dt DataType
+	var val interface{}
+	var compatible bool
+
+	BeforeEach(func() {
+		dt = Object{
+			"name": &AttributeDefinition{Type: String},
+			"tags": &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: String}}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		compatible = dt.IsCompatible(val)
+	})
+
+	Context("with a struct whose fields match the attribute types", func() {
+		BeforeEach(func() {
+			dt.(Object)["created"] = &AttributeDefinition{Type: DateTime}
+			val = struct {
+				Name    string
+				Tags    []string `json:"tags,omitempty"`
+				Created time.Time
+			}{Name: "goa", Tags: []string{"design"}, Created: time.Now()}
+		})
+
+		It("returns true", func() {
+			Ω(compatible).Should(BeTrue())
+		})
+	})
+
+	Context("with a struct slice field whose element type mismatches", func() {
+		BeforeEach(func() {
+			val = struct {
+				Name string
+				Tags []Task
+			}{Name: "goa", Tags: []Task{{Title: "design"}}}
+		})
+
+		It("returns false", func() {
+			Ω(compatible).Should(BeFalse())
+		})
+	})
+
+	Context("with an empty struct slice field whose element type mismatches", func() {
+		BeforeEach(func() {
+			val = struct {
+				Tags []Task
+			}{}
+		})
+
+		It("returns false", func() {
+			Ω(compatible).Should(BeFalse())
+		})
+	})
+
+	Context("with a map value whose element type mismatches", func() {
+		BeforeEach(func() {
+			val = map[string]interface{}{"tags": []interface{}{"design", 42}}
+		})
+
+		It("returns false", func() {
+			Ω(compatible).Should(BeFalse())
+		})
+	})
+
+	Context("with an empty map field of arrays whose element type mismatches", func() {
+		BeforeEach(func() {
+			dt = Object{
+				"index": &AttributeDefinition{Type: &Hash{
+					KeyType:  &AttributeDefinition{Type: String},
+					ElemType: &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: String}}},
+				}},
+			}
+			val = struct {
+				Index map[string][]Task
+			}{Index: map[string][]Task{}}
+		})
+
+		It("returns false", func() {
+			Ω(compatible).Should(BeFalse())
+		})
+	})
+})
+
 var _ = Describe("Project", func() {
 	var mt *MediaTypeDefinition
 	var view string
@@ -434,6 +524,60 @@ var _ = Describe("MediaTypeDefinition", func() {
 			})
 		})
 	})
+
+	Describe("Extends", func() {
+		var base, m *MediaTypeDefinition
+
+		BeforeEach(func() {
+			base = &MediaTypeDefinition{
+				UserTypeDefinition: &UserTypeDefinition{
+					AttributeDefinition: &AttributeDefinition{
+						Type: Object{
+							"id":   {Type: Integer},
+							"href": {Type: String},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"id"}},
+					},
+				},
+				Links: map[string]*LinkDefinition{"self": {Name: "self"}},
+				Views: map[string]*ViewDefinition{
+					"link": {Name: "link", AttributeDefinition: &AttributeDefinition{Type: Object{"href": {Type: String}}}},
+				},
+			}
+			m = &MediaTypeDefinition{
+				UserTypeDefinition: &UserTypeDefinition{
+					AttributeDefinition: &AttributeDefinition{
+						Type: Object{
+							"id":   {Type: String},
+							"name": {Type: String},
+						},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			m.Extends(base)
+		})
+
+		It("inherits the base members", func() {
+			o := m.Type.ToObject()
+			Ω(o).Should(HaveKey("href"))
+			Ω(o).Should(HaveKey("name"))
+			Ω(m.Validation.Required).Should(Equal([]string{"id"}))
+		})
+
+		It("keeps the members it overrides", func() {
+			Ω(m.Type.ToObject()["id"].Type).Should(Equal(String))
+		})
+
+		It("inherits the base links and views", func() {
+			Ω(m.Links).Should(HaveKey("self"))
+			Ω(m.Links["self"].Parent).Should(Equal(m))
+			Ω(m.Views).Should(HaveKey("link"))
+			Ω(m.Views["link"].Parent).Should(Equal(m))
+		})
+	})
 })
 
 var _ = Describe("Walk", func() {
@@ -322,8 +322,8 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	}
 	for i, r := range a.Responses {
 		for j, r2 := range a.Responses {
-			if i != j && r.Status == r2.Status {
-				verr.Add(r, "Multiple response definitions with status code %d", r.Status)
+			if i != j && r.AcceptsStatus(r2.lowestStatus()) {
+				verr.Add(r, "Multiple response definitions with status code %d", r2.lowestStatus())
 			}
 		}
 		verr.Merge(r.Validate())
@@ -331,12 +331,19 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	verr.Merge(a.ValidateParams())
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
+		if n := timeLayoutAttribute(a.Payload.AttributeDefinition, "", make(map[string]bool)); n != "" {
+			verr.Add(a, "Payload attribute %s uses TimeFormat or TimeUnit which only apply to params and headers", n)
+		}
 	}
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
+			if p.IsFile() {
+				verr.Add(a, "Param %s has an invalid type, file attributes may only be used in payloads", n)
+				continue
+			}
 			if p.Type.IsPrimitive() {
 				continue
 			}
@@ -352,6 +359,49 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
+// timeLayoutAttribute returns the path to the first attribute of att, or att itself, that defines
+// a time format or a time unit, the empty string if there is none.
+func timeLayoutAttribute(att *AttributeDefinition, path string, seen map[string]bool) string {
+	if att.TimeFormat != "" || att.TimeUnit != "" {
+		if path == "" {
+			return "<root>"
+		}
+		return path
+	}
+	switch actual := att.Type.(type) {
+	case *UserTypeDefinition:
+		if seen[actual.TypeName] {
+			return ""
+		}
+		seen[actual.TypeName] = true
+		return timeLayoutAttribute(actual.AttributeDefinition, path, seen)
+	case *MediaTypeDefinition:
+		if seen[actual.TypeName] {
+			return ""
+		}
+		seen[actual.TypeName] = true
+		return timeLayoutAttribute(actual.AttributeDefinition, path, seen)
+	case *Array:
+		return timeLayoutAttribute(actual.ElemType, path+"[]", seen)
+	case Object:
+		names := make([]string, 0, len(actual))
+		for n := range actual {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			p := n
+			if path != "" {
+				p = path + "." + n
+			}
+			if res := timeLayoutAttribute(actual[n], p, seen); res != "" {
+				return res
+			}
+		}
+	}
+	return ""
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -460,6 +510,16 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Add(parent, "%sdefault value %#v is not one of the accepted values: %#v", ctx, a.DefaultValue, a.Validation.Values)
 		}
 	}
+	// Make sure the Enum values can be loaded into the attribute, the Enum DSL cannot check them
+	// when the attribute type is not known yet.
+	if a.Validation != nil {
+		for i, v := range a.Validation.Values {
+			if !a.Type.IsCompatible(v) {
+				verr.Add(parent, "%senum value %#v at index %d is incompatible with attribute of type %s",
+					ctx, v, i, a.Type.Name())
+			}
+		}
+	}
 	o := a.Type.ToObject()
 	if o != nil {
 		for _, n := range a.AllRequired() {
@@ -495,7 +555,13 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Headers != nil {
 		verr.Merge(r.Headers.Validate("response headers", r))
 	}
-	if r.Status == 0 {
+	if r.StatusRange != nil {
+		if r.StatusRange.Min < 100 || r.StatusRange.Max > 599 || r.StatusRange.Min > r.StatusRange.Max {
+			verr.Add(r, "invalid response status range %s", r.StatusRange)
+		} else if r.Status != 0 && !r.StatusRange.Contains(r.Status) {
+			verr.Add(r, "response status %d is not in status range %s", r.Status, r.StatusRange)
+		}
+	} else if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
 	return verr.AsError()
@@ -615,13 +681,29 @@ func (l *LinkDefinition) Validate() *dslengine.ValidationErrors {
 	return verr.AsError()
 }
 
-// Validate checks that the view definition is consistent: it has a  parent media type and the
-// underlying definition type is consistent.
+// Validate checks that the view definition is consistent: it has a  parent media type, the
+// underlying definition type is consistent and it only lists members of the parent media type.
 func (v *ViewDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
 	if v.Parent == nil {
 		verr.Add(v, "View must have a parent media type")
 	}
 	verr.Merge(v.AttributeDefinition.Validate("", v))
+	if v.Parent != nil && v.Parent.Type != nil && v.Type != nil {
+		members := v.Parent.Type.ToObject()
+		if a := v.Parent.Type.ToArray(); a != nil && a.ElemType != nil && a.ElemType.Type != nil {
+			members = a.ElemType.Type.ToObject()
+		}
+		var names []string
+		for n := range v.Type.ToObject() {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			if _, ok := members[n]; !ok && n != "links" {
+				verr.Add(v, "unknown member %#v, view members must be attributes of the media type", n)
+			}
+		}
+	}
 	return verr.AsError()
 }
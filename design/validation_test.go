@@ -62,6 +62,22 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("with enum values incompatible with the inferred type", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, func() {
+						Enum(1, 2)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(
+					`field attName - enum value 1 at index 0 is incompatible with attribute of type string`))
+			})
+		})
+
 		Context("with a default value that doesn't exist in enum", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -287,4 +303,184 @@ var _ = Describe("Validation", func() {
 			})
 		})
 	})
+
+	Context("with resources sharing a default media type", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			mt := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				DefaultMedia(mt)
+			})
+			Resource("wine", func() {
+				DefaultMedia("application/vnd.bottle")
+			})
+			dslengine.Run()
+		})
+
+		It("accepts them", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["bottle"].MediaType).Should(Equal(Design.Resources["wine"].MediaType))
+		})
+	})
+
+	Context("with file attributes", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("avatar", func() {
+				Action("upload", func() {
+					Routing(POST("/:id"))
+					Payload(func() {
+						Member("avatar", File)
+						Required("avatar")
+					})
+				})
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("avatar", File)
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("only accepts them in payloads", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("Param avatar has an invalid type, file attributes may only be used in payloads"))
+			Ω(dslengine.Errors.Error()).ShouldNot(ContainSubstring("upload"))
+		})
+	})
+
+	Context("with time layouts", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("event", func() {
+				Action("list", func() {
+					Routing(GET(""))
+					Params(func() {
+						Param("since", DateTime, func() {
+							TimeUnit("milliseconds")
+						})
+					})
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(func() {
+						Member("schedule", func() {
+							Attribute("due", DateTime, func() {
+								TimeFormat("2006-01-02")
+							})
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("only accepts them in params", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("Payload attribute schedule.due uses TimeFormat or TimeUnit which only apply to params and headers"))
+			Ω(dslengine.Errors.Error()).ShouldNot(ContainSubstring("since"))
+		})
+	})
+
+	Context("with response status ranges", func() {
+		var overlapping bool
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET(""))
+					Response("Success", StatusRange{Min: 200, Max: 299})
+					if overlapping {
+						Response("Partial", StatusRange{Min: 206, Max: 399})
+					} else {
+						Response("Redirect", StatusRange{Min: 300, Max: 399})
+						Response(NotFound)
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("that do not overlap", func() {
+			BeforeEach(func() {
+				overlapping = false
+			})
+
+			It("accepts them", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("that overlap", func() {
+			BeforeEach(func() {
+				overlapping = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("Multiple response definitions with status code 206"))
+			})
+		})
+	})
+
+	Context("with a view referencing a nonexistent member", func() {
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+				View("tiny", func() {
+					Attribute("vintage")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces an error naming the view and the member", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`view "tiny" references unknown attribute "vintage"`))
+		})
+	})
+
+	Context("with a view definition listing a nonexistent member", func() {
+		var mt *MediaTypeDefinition
+
+		BeforeEach(func() {
+			mt = &MediaTypeDefinition{
+				UserTypeDefinition: &UserTypeDefinition{
+					AttributeDefinition: &AttributeDefinition{Type: Object{"id": {Type: Integer}}},
+					TypeName:            "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+			}
+			mt.Views = map[string]*ViewDefinition{
+				"default": {
+					Name:                "default",
+					Parent:              mt,
+					AttributeDefinition: &AttributeDefinition{Type: Object{"id": {Type: Integer}, "vintage": {Type: Integer}}},
+				},
+			}
+		})
+
+		It("fails validation", func() {
+			err := mt.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`view "default"`))
+			Ω(err.Error()).Should(ContainSubstring(`unknown member "vintage"`))
+		})
+	})
 })
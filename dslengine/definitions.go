@@ -1,6 +1,9 @@
 package dslengine
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type (
 
@@ -94,12 +97,30 @@ type (
 		// Maximum represents a maximum value validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor17.
 		Maximum *float64
+		// ExclusiveMinimum indicates that the value must be strictly greater than Minimum as
+		// described at http://json-schema.org/latest/json-schema-validation.html#anchor21.
+		ExclusiveMinimum bool
+		// ExclusiveMaximum indicates that the value must be strictly lesser than Maximum as
+		// described at http://json-schema.org/latest/json-schema-validation.html#anchor17.
+		ExclusiveMaximum bool
+		// MinimumTime represents a minimum value validation of a date time attribute.
+		// ExclusiveMinimum indicates whether the value must be strictly after MinimumTime.
+		MinimumTime *time.Time
+		// MaximumTime represents a maximum value validation of a date time attribute.
+		// ExclusiveMaximum indicates whether the value must be strictly before MaximumTime.
+		MaximumTime *time.Time
+		// MultipleOf represents a multipleOf validation as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor14.
+		MultipleOf *float64
 		// MinLength represents an minimum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor29.
 		MinLength *int
 		// MaxLength represents an maximum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor26.
 		MaxLength *int
+		// UniqueItems indicates that the elements of an array value must be unique as described
+		// at http://json-schema.org/latest/json-schema-validation.html#anchor49.
+		UniqueItems bool
 		// Required list the required fields of object attributes as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 		Required []string
@@ -137,9 +158,38 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	}
 	if v.Minimum == nil || (other.Minimum != nil && *v.Minimum > *other.Minimum) {
 		v.Minimum = other.Minimum
+		if other.Minimum != nil {
+			v.ExclusiveMinimum = other.ExclusiveMinimum
+		}
+	} else if other.Minimum != nil && *v.Minimum == *other.Minimum && other.ExclusiveMinimum {
+		v.ExclusiveMinimum = true
 	}
 	if v.Maximum == nil || (other.Maximum != nil && *v.Maximum < *other.Maximum) {
 		v.Maximum = other.Maximum
+		if other.Maximum != nil {
+			v.ExclusiveMaximum = other.ExclusiveMaximum
+		}
+	} else if other.Maximum != nil && *v.Maximum == *other.Maximum && other.ExclusiveMaximum {
+		v.ExclusiveMaximum = true
+	}
+	if v.MinimumTime == nil || (other.MinimumTime != nil && v.MinimumTime.After(*other.MinimumTime)) {
+		v.MinimumTime = other.MinimumTime
+		if other.MinimumTime != nil {
+			v.ExclusiveMinimum = other.ExclusiveMinimum
+		}
+	} else if other.MinimumTime != nil && v.MinimumTime.Equal(*other.MinimumTime) && other.ExclusiveMinimum {
+		v.ExclusiveMinimum = true
+	}
+	if v.MaximumTime == nil || (other.MaximumTime != nil && v.MaximumTime.Before(*other.MaximumTime)) {
+		v.MaximumTime = other.MaximumTime
+		if other.MaximumTime != nil {
+			v.ExclusiveMaximum = other.ExclusiveMaximum
+		}
+	} else if other.MaximumTime != nil && v.MaximumTime.Equal(*other.MaximumTime) && other.ExclusiveMaximum {
+		v.ExclusiveMaximum = true
+	}
+	if v.MultipleOf == nil {
+		v.MultipleOf = other.MultipleOf
 	}
 	if v.MinLength == nil || (other.MinLength != nil && *v.MinLength > *other.MinLength) {
 		v.MinLength = other.MinLength
@@ -147,6 +197,7 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	if v.MaxLength == nil || (other.MaxLength != nil && *v.MaxLength < *other.MaxLength) {
 		v.MaxLength = other.MaxLength
 	}
+	v.UniqueItems = v.UniqueItems || other.UniqueItems
 	v.AddRequired(other.Required)
 }
 
@@ -174,7 +225,10 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if v.Format != "" || v.Pattern != "" {
 		return false
 	}
-	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MaxLength != nil) {
+	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MultipleOf != nil) || (v.MaxLength != nil) || v.UniqueItems {
+		return false
+	}
+	if (v.MinimumTime != nil) || (v.MaximumTime != nil) {
 		return false
 	}
 	return true
@@ -183,13 +237,19 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 // Dup makes a shallow dup of the validation.
 func (v *ValidationDefinition) Dup() *ValidationDefinition {
 	return &ValidationDefinition{
-		Values:    v.Values,
-		Format:    v.Format,
-		Pattern:   v.Pattern,
-		Minimum:   v.Minimum,
-		Maximum:   v.Maximum,
-		MinLength: v.MinLength,
-		MaxLength: v.MaxLength,
-		Required:  v.Required,
+		Values:           v.Values,
+		Format:           v.Format,
+		Pattern:          v.Pattern,
+		Minimum:          v.Minimum,
+		Maximum:          v.Maximum,
+		ExclusiveMinimum: v.ExclusiveMinimum,
+		ExclusiveMaximum: v.ExclusiveMaximum,
+		MinimumTime:      v.MinimumTime,
+		MaximumTime:      v.MaximumTime,
+		MultipleOf:       v.MultipleOf,
+		MinLength:        v.MinLength,
+		MaxLength:        v.MaxLength,
+		UniqueItems:      v.UniqueItems,
+		Required:         v.Required,
 	}
 }
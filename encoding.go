@@ -1,12 +1,15 @@
 package goa
 
 import (
+	"bytes"
 	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"mime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -62,6 +65,15 @@ type (
 		pools map[string]*decoderPool // Registered decoders
 	}
 
+	// xmlDecoder decodes XML documents. It decodes into structs using the encoding/xml package
+	// and into generic values (interface{} or map[string]interface{}) by walking the document.
+	xmlDecoder struct {
+		dec *xml.Decoder
+		// limit bounds the nesting depth of the decoded document if the decoder reads a
+		// request body whose depth is limited, see Controller.MaxPayloadDepth.
+		limit *depthReader
+	}
+
 	// HTTPEncoder is a Encoder that encodes HTTP request or response bodies given a set of
 	// known Content-Type to encoder mapping.
 	HTTPEncoder struct {
@@ -79,8 +91,17 @@ func NewJSONDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
 // NewXMLEncoder is an adapter for the encoding package XML encoder.
 func NewXMLEncoder(w io.Writer) Encoder { return xml.NewEncoder(w) }
 
-// NewXMLDecoder is an adapter for the encoding package XML decoder.
-func NewXMLDecoder(r io.Reader) Decoder { return xml.NewDecoder(r) }
+// NewXMLDecoder is an adapter for the encoding package XML decoder. The decoder also supports
+// decoding into generic values: the content of the root element is then decoded into a
+// map[string]interface{} where each child element and attribute produces a key. Elements that
+// only contain text produce string values and repeated elements produce slices.
+func NewXMLDecoder(r io.Reader) Decoder {
+	d := &xmlDecoder{dec: xml.NewDecoder(r)}
+	if dr, ok := r.(*depthReader); ok && dr.xml {
+		d.limit = dr
+	}
+	return d
+}
 
 // NewGobEncoder is an adapter for the encoding package gob encoder.
 func NewGobEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
@@ -104,6 +125,12 @@ func NewHTTPDecoder() *HTTPDecoder {
 
 // Decode uses registered Decoders to unmarshal a body based on the contentType.
 func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType string) error {
+	return decoder.decode(v, body, contentType, false)
+}
+
+// decode unmarshals body like Decode. useNumber causes the decoders that support it such as the
+// encoding/json decoder to decode the numbers held in interface{} values as json.Number.
+func (decoder *HTTPDecoder) decode(v interface{}, body io.Reader, contentType string, useNumber bool) error {
 	now := time.Now()
 	defer MeasureSince([]string{"goa", "decode", contentType}, now)
 	var p *decoderPool
@@ -116,6 +143,10 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 		}
 	}
 	p = decoder.pools[contentType]
+	if p == nil && strings.Contains(contentType, "xml") {
+		// Use the XML decoder for variants such as text/xml or application/vnd.foo+xml.
+		p = decoder.pools["application/xml"]
+	}
 	if p == nil {
 		p = decoder.pools["*/*"]
 	}
@@ -126,6 +157,13 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 	// the decoderPool will handle whether or not a pool is actually in use
 	d := p.Get(body)
 	defer p.Put(d)
+	if useNumber {
+		if n, ok := d.(interface {
+			UseNumber()
+		}); ok {
+			n.UseNumber()
+		}
+	}
 	if err := d.Decode(v); err != nil {
 		return err
 	}
@@ -186,25 +224,142 @@ func (p *decoderPool) Put(d Decoder) {
 	p.pool.Put(d)
 }
 
+// Decode unmarshals the XML document into v. Decoding into a struct with a XMLName field fails
+// if the name of the root element does not match.
+func (d *xmlDecoder) Decode(v interface{}) error {
+	switch t := v.(type) {
+	case *interface{}:
+		m, err := d.decodeMap()
+		if err != nil {
+			return err
+		}
+		*t = m
+	case *map[string]interface{}:
+		m, err := d.decodeMap()
+		if err != nil {
+			return err
+		}
+		*t = m
+	default:
+		return d.dec.Decode(v)
+	}
+	return nil
+}
+
+// decodeMap decodes the content of the document root element into a map.
+func (d *xmlDecoder) decodeMap() (map[string]interface{}, error) {
+	root, err := d.nextStart()
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, fmt.Errorf("missing XML root element")
+	}
+	val, err := d.decodeElement(root, 1)
+	if err != nil {
+		return nil, err
+	}
+	next, err := d.nextStart()
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		return nil, fmt.Errorf("unexpected element <%s> after root element <%s>",
+			next.Name.Local, root.Name.Local)
+	}
+	if m, ok := val.(map[string]interface{}); ok {
+		return m, nil
+	}
+	if val == "" {
+		return make(map[string]interface{}), nil
+	}
+	return nil, fmt.Errorf("root element <%s> must contain elements, got text %#v",
+		root.Name.Local, val)
+}
+
+// nextStart skips tokens until the next start element and returns it. It returns nil at the end
+// of the document.
+func (d *xmlDecoder) nextStart() (*xml.StartElement, error) {
+	for {
+		tok, err := d.dec.Token()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return &se, nil
+		}
+	}
+}
+
+// decodeElement decodes the content of the given element located at the given depth, the root
+// element being at depth 1. The result is a string if the element has no attribute nor child
+// element, a map otherwise.
+func (d *xmlDecoder) decodeElement(start *xml.StartElement, depth int) (interface{}, error) {
+	if d.limit != nil && depth > d.limit.max {
+		d.limit.exceeded = true
+		return nil, errPayloadTooDeep
+	}
+	var (
+		m    map[string]interface{}
+		text bytes.Buffer
+	)
+	add := func(key string, val interface{}) {
+		if m == nil {
+			m = make(map[string]interface{})
+		}
+		if existing, ok := m[key]; ok {
+			if s, ok := existing.([]interface{}); ok {
+				m[key] = append(s, val)
+			} else {
+				m[key] = []interface{}{existing, val}
+			}
+			return
+		}
+		m[key] = val
+	}
+	for _, attr := range start.Attr {
+		add(attr.Name.Local, attr.Value)
+	}
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("unexpected end of document in element <%s>", start.Name.Local)
+			}
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			val, err := d.decodeElement(&t, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			add(t.Name.Local, val)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if m != nil {
+				return m, nil
+			}
+			return strings.TrimSpace(text.String()), nil
+		}
+	}
+}
+
 // Encode uses the registered encoders and given content type to marshal and write the given value
-// using the given writer.
+// using the given writer. The encoder is selected with Negotiate so that accept may be a complete
+// Accept header value.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
 	now := time.Now()
-	if accept == "" {
-		accept = "*/*"
-	}
-	var contentType string
-	for _, t := range encoder.contentTypes {
-		if accept == "*/*" || accept == t {
-			contentType = accept
-			break
-		}
+	contentType, err := encoder.Negotiate(accept)
+	if err != nil {
+		return err
 	}
 	defer MeasureSince([]string{"goa", "encode", contentType}, now)
 	p := encoder.pools[contentType]
-	if p == nil && contentType != "*/*" {
-		p = encoder.pools["*/*"]
-	}
 	if p == nil {
 		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
 	}
@@ -219,6 +374,39 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 	return nil
 }
 
+// Negotiate returns the registered content type whose encoder best serves the media ranges
+// listed in the given Accept header value. Media ranges are considered in decreasing order of
+// quality. A range matches the content types it lists directly or through wildcards, a range
+// that uses a structured syntax suffix (e.g. "application/vnd.goa.example+json") also matches
+// the content type named after the suffix (e.g. "application/json").
+//
+// Negotiate returns "*/*" when the default encoder should be used: if accept is empty, if it
+// accepts any media type or if no media range matches a registered content type. It returns a
+// NotAcceptableError if no media range matches and only non-default encoders are registered.
+func (encoder *HTTPEncoder) Negotiate(accept string) (string, error) {
+	_, hasDefault := encoder.pools["*/*"]
+	var offered []string
+	if hasDefault {
+		// The "*/*" media range selects the default encoder.
+		offered = append(offered, "*/*")
+	}
+	for _, ct := range encoder.contentTypes {
+		if ct != "*/*" {
+			offered = append(offered, ct)
+		}
+	}
+	if accept == "" {
+		accept = "*/*"
+	}
+	if ct := negotiateMediaType(accept, offered); ct != "" {
+		return ct, nil
+	}
+	if !hasDefault && len(offered) > 0 {
+		return "", NotAcceptableError(accept, offered)
+	}
+	return "*/*", nil
+}
+
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
 // already registered, it is overwritten.
 func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
@@ -236,6 +424,7 @@ func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 	for contentType := range encoder.pools {
 		encoder.contentTypes = append(encoder.contentTypes, contentType)
 	}
+	sort.Strings(encoder.contentTypes)
 }
 
 // newEncodePool checks to see if the EncoderFactory returns reusable encoders and if so, creates
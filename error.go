@@ -16,8 +16,9 @@ interface is leveraged by the error handler middleware to produce the error resp
 
 The code generated by goagen calls the helper functions exposed in this file when it encounters
 invalid data (wrong type, validation errors etc.) such as InvalidParamTypeError,
-InvalidAttributeTypeError etc. These methods return errors that get merged with any previously
-encountered error via the Error Merge method. The helper functions are error classes stored in
+InvalidAttributeTypeError etc. These methods return errors that get appended to any previously
+encountered error via AppendError so that the resulting MultiError lists all the validation
+errors. The helper functions are error classes stored in
 global variable. This means your code can override their values to produce arbitrary error
 responses.
 
@@ -32,8 +33,11 @@ package goa
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 )
 
@@ -41,6 +45,10 @@ var (
 	// ErrorMediaIdentifier is the media type identifier used for error responses.
 	ErrorMediaIdentifier = "application/vnd.goa.error"
 
+	// ProblemMediaIdentifier is the media type identifier used for error responses when the
+	// service ProblemDetails option is set.
+	ProblemMediaIdentifier = "application/problem+json"
+
 	// ErrBadRequest is a generic bad request error.
 	ErrBadRequest = NewErrorClass("bad_request", 400)
 
@@ -54,10 +62,27 @@ var (
 	// ErrInvalidEncoding is the error produced when a request body fails to be decoded.
 	ErrInvalidEncoding = NewErrorClass("invalid_encoding", 400)
 
+	// ErrUnprocessableEntity is the class of errors produced when a request is syntactically
+	// valid but violates semantic rules such as constraints spanning multiple fields. Contrast
+	// with ErrInvalidRequest which is used for requests that fail the design validations.
+	ErrUnprocessableEntity = NewErrorClass("unprocessable_entity", 422)
+
+	// ErrNotAcceptable is the error produced when none of the media types an action responds
+	// with matches the request Accept header.
+	ErrNotAcceptable = NewErrorClass("not_acceptable", 406)
+
 	// ErrRequestBodyTooLarge is the error produced when the size of a request body exceeds
 	// MaxRequestBodyLength bytes.
 	ErrRequestBodyTooLarge = NewErrorClass("request_too_large", 413)
 
+	// ErrRequestTimeout is the error produced when the request body is not read within the
+	// time allotted by the service request budget.
+	ErrRequestTimeout = NewErrorClass("request_timeout", 408)
+
+	// ErrActionTimeout is the error produced when a controller action does not respond within
+	// the timeout defined in the design, see ActionTimeout.
+	ErrActionTimeout = NewErrorClass("action_timeout", 503)
+
 	// ErrNoAuthMiddleware is the error produced when no auth middleware is mounted for a
 	// security scheme defined in the design.
 	ErrNoAuthMiddleware = NewErrorClass("no_auth_middleware", 500)
@@ -69,6 +94,14 @@ var (
 	// ErrNotFound is the error returned to requests that don't match a registered handler.
 	ErrNotFound = NewErrorClass("not_found", 404)
 
+	// ErrMethodNotAllowed is the error returned to requests made to a registered path with a
+	// method that has no handler.
+	ErrMethodNotAllowed = NewErrorClass("method_not_allowed", 405)
+
+	// ErrInvalidResponse is the error produced by the generated code when a controller
+	// attempts to send a response that does not conform to the design.
+	ErrInvalidResponse = NewErrorClass("invalid_response", 500)
+
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
 )
@@ -108,6 +141,79 @@ type (
 		Detail string `json:"detail" xml:"detail" form:"detail"`
 		// Meta contains additional key/value pairs useful to clients.
 		Meta []map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+		// Errors lists the validation failures that caused the error if any.
+		Errors []*ValidationError `json:"errors,omitempty" xml:"errors,omitempty" form:"errors,omitempty"`
+	}
+
+	// ProblemDetails is the RFC 7807 representation of error responses used when the service
+	// ProblemDetails option is set. Code, ID, Meta and Errors are extension members that carry
+	// the corresponding ErrorResponse fields.
+	ProblemDetails struct {
+		// Type is a URI reference that identifies the problem type.
+		Type string `json:"type" xml:"type" form:"type"`
+		// Title is a short summary of the problem type.
+		Title string `json:"title" xml:"title" form:"title"`
+		// Status is the HTTP status code of the response.
+		Status int `json:"status" xml:"status" form:"status"`
+		// Detail describes the specific problem occurrence.
+		Detail string `json:"detail,omitempty" xml:"detail,omitempty" form:"detail,omitempty"`
+		// Code identifies the class of errors.
+		Code string `json:"code,omitempty" xml:"code,omitempty" form:"code,omitempty"`
+		// ID is the unique error instance identifier.
+		ID string `json:"id,omitempty" xml:"id,omitempty" form:"id,omitempty"`
+		// Meta contains additional key/value pairs useful to clients.
+		Meta []map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+		// Errors lists the validation failures that caused the error if any.
+		Errors []*ValidationError `json:"errors,omitempty" xml:"errors,omitempty" form:"errors,omitempty"`
+	}
+
+	// ValidationError describes a single validation failure with a code that clients can rely
+	// on rather than parsing the message. The codes are "required", "type", "repeated",
+	// "enum", "format", "pattern", "minimum", "maximum", "exclusive_minimum",
+	// "exclusive_maximum", "multiple_of", "unique_items", "min_length" and "max_length".
+	ValidationError struct {
+		// Code identifies the validation that failed.
+		Code string `json:"code" xml:"code" form:"code"`
+		// Field is the name of the parameter or header or the path to the payload field
+		// that failed validation, empty if the error applies to the whole payload.
+		Field string `json:"field,omitempty" xml:"field,omitempty" form:"field,omitempty"`
+		// Message describes the validation failure.
+		Message string `json:"message" xml:"message" form:"message"`
+	}
+
+	// FieldError describes a semantic validation failure for a single request field.
+	FieldError struct {
+		// Field is the name of the field that failed validation.
+		Field string `json:"field" xml:"field" form:"field"`
+		// Message describes the validation failure.
+		Message string `json:"message" xml:"message" form:"message"`
+	}
+
+	// MultiError collects multiple errors, for example all the validation errors produced while
+	// loading a request, so that they may be reported at once. It implements ServiceError and
+	// serializes as the list of the individual errors.
+	MultiError []error
+
+	// ErrorResponses is the list of error responses that a MultiError serializes to. Responses
+	// whose body is a MultiError encode the corresponding ErrorResponses so that any encoder
+	// may serialize them. The XML representation wraps the errors in an "errors" element.
+	ErrorResponses []*ErrorResponse
+
+	// xmlErrorResponse is the XML representation of ErrorResponse, encoding/xml cannot
+	// serialize the metadata maps.
+	xmlErrorResponse struct {
+		ID     string             `xml:"id"`
+		Code   string             `xml:"code"`
+		Status int                `xml:"status"`
+		Detail string             `xml:"detail"`
+		Meta   []xmlMeta          `xml:"meta,omitempty"`
+		Errors []*ValidationError `xml:"errors,omitempty"`
+	}
+
+	// xmlMeta is the XML representation of an error metadata key/value pair.
+	xmlMeta struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
 	}
 )
 
@@ -141,40 +247,75 @@ func NewErrorClass(code string, status int) ErrorClass {
 
 // MissingPayloadError is the error produced when a request is missing a required payload.
 func MissingPayloadError() error {
-	return ErrInvalidRequest("missing required payload")
+	return validationError("required", "", "missing required payload")
 }
 
 // InvalidParamTypeError is the error produced when the type of a parameter does not match the type
 // defined in the design.
 func InvalidParamTypeError(name string, val interface{}, expected string) error {
 	msg := fmt.Sprintf("invalid value %#v for parameter %#v, must be a %s", val, name, expected)
-	return ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected)
+	return validationError("type", name, msg, "param", name, "value", val, "expected", expected)
+}
+
+// NotAcceptableError is the error produced when the request Accept header does not match any of
+// the media types offered by the action.
+func NotAcceptableError(accept string, offered []string) error {
+	msg := fmt.Sprintf("none of the media types %s matches the Accept header %#v", strings.Join(offered, ", "), accept)
+	return ErrNotAcceptable(msg, "accept", accept, "offered", offered)
+}
+
+// RepeatedParamError is the error produced when a parameter that accepts a single value is
+// given multiple times and the service RepeatedParams policy is ParamError.
+func RepeatedParamError(name string, vals []string) error {
+	msg := fmt.Sprintf("parameter %#v must be given once, got %d values", name, len(vals))
+	return validationError("repeated", name, msg, "param", name, "values", vals)
 }
 
 // MissingParamError is the error produced for requests that are missing path or querystring
 // parameters.
 func MissingParamError(name string) error {
 	msg := fmt.Sprintf("missing required parameter %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return validationError("required", name, msg, "name", name)
 }
 
 // InvalidAttributeTypeError is the error produced when the type of payload field does not match
 // the type defined in the design.
 func InvalidAttributeTypeError(ctx string, val interface{}, expected string) error {
 	msg := fmt.Sprintf("type of %s must be %s but got value %#v", ctx, expected, val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected)
+	return validationError("type", ctx, msg, "attribute", ctx, "value", val, "expected", expected)
 }
 
 // MissingAttributeError is the error produced when a request payload is missing a required field.
 func MissingAttributeError(ctx, name string) error {
 	msg := fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx)
-	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx)
+	return validationError("required", ctx+"."+name, msg, "attribute", name, "parent", ctx)
+}
+
+// AdditionalPropertyError is the error produced when a request payload object contains a field
+// that does not match any attribute and the design rejects additional properties.
+func AdditionalPropertyError(ctx, name string) error {
+	msg := fmt.Sprintf("attribute %#v of %s is not allowed", name, ctx)
+	return validationError("additional_properties", ctx+"."+name, msg, "attribute", name, "parent", ctx)
 }
 
 // MissingHeaderError is the error produced when a request is missing a required header.
 func MissingHeaderError(name string) error {
 	msg := fmt.Sprintf("missing required HTTP header %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return validationError("required", name, msg, "name", name)
+}
+
+// MissingResponseHeaderError is the error produced when a controller sends a response without
+// setting a header that the design defines as required.
+func MissingResponseHeaderError(action, name string) error {
+	msg := fmt.Sprintf("response of action %#v is missing required HTTP header %#v", action, name)
+	return ErrInvalidResponse(msg, "action", action, "name", name)
+}
+
+// InvalidResponseHeaderError is the error produced when a controller sends a response with a
+// header value that does not match the pattern defined in the design.
+func InvalidResponseHeaderError(action, name, val, pattern string) error {
+	msg := fmt.Sprintf("response of action %#v has invalid HTTP header %#v value %#v, must match the regexp %#v", action, name, val, pattern)
+	return ErrInvalidResponse(msg, "action", action, "name", name, "value", val, "regexp", pattern)
 }
 
 // InvalidEnumValueError is the error produced when the value of a parameter or payload field does
@@ -185,43 +326,88 @@ func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}) e
 		elems[i] = fmt.Sprintf("%#v", a)
 	}
 	msg := fmt.Sprintf("value of %s must be one of %s but got value %#v", ctx, strings.Join(elems, ", "), val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	return validationError("enum", ctx, msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
 }
 
 // InvalidFormatError is the error produced when the value of a parameter or payload field does not
 // match the format validation defined in the design.
 func InvalidFormatError(ctx, target string, format Format, formatError error) error {
 	msg := fmt.Sprintf("%s must be formatted as a %s but got value %#v, %s", ctx, format, target, formatError.Error())
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	return validationError("format", ctx, msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
 }
 
 // InvalidPatternError is the error produced when the value of a parameter or payload field does
 // not match the pattern validation defined in the design.
 func InvalidPatternError(ctx, target string, pattern string) error {
 	msg := fmt.Sprintf("%s must match the regexp %#v but got value %#v", ctx, pattern, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern)
+	return validationError("pattern", ctx, msg, "attribute", ctx, "value", target, "regexp", pattern)
 }
 
 // InvalidRangeError is the error produced when the value of a parameter or payload field does
 // not match the range validation defined in the design. value may be a int or a float64.
 func InvalidRangeError(ctx string, target interface{}, value interface{}, min bool) error {
-	comp := "greater or equal"
+	comp, code := "greater or equal", "minimum"
+	if !min {
+		comp, code = "lesser or equal", "maximum"
+	}
+	msg := fmt.Sprintf("%s must be %s than %v but got value %#v", ctx, comp, value, target)
+	return validationError(code, ctx, msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+}
+
+// InvalidExclusiveRangeError is the error produced when the value of a parameter or payload field
+// does not match the exclusive range validation defined in the design, that is when the value is
+// lesser or equal to the exclusive minimum or greater or equal to the exclusive maximum. value may
+// be a int or a float64.
+func InvalidExclusiveRangeError(ctx string, target interface{}, value interface{}, min bool) error {
+	comp, code := "greater", "exclusive_minimum"
 	if !min {
-		comp = "lesser or equal"
+		comp, code = "lesser", "exclusive_maximum"
 	}
-	msg := fmt.Sprintf("%s must be %s than %d but got value %#v", ctx, comp, value, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	msg := fmt.Sprintf("%s must be %s than %v but got value %#v", ctx, comp, value, target)
+	return validationError(code, ctx, msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+}
+
+// InvalidMultipleOfError is the error produced when the value of a parameter or payload field is
+// not a multiple of the divisor given to the multipleOf validation defined in the design.
+func InvalidMultipleOfError(ctx string, target interface{}, divisor interface{}) error {
+	msg := fmt.Sprintf("%s must be a multiple of %v but got value %#v", ctx, divisor, target)
+	return validationError("multiple_of", ctx, msg, "attribute", ctx, "value", target, "multipleOf", divisor)
+}
+
+// InvalidUniqueItemsError is the error produced when an array parameter or payload field contains
+// duplicate elements and the design defines a uniqueItems validation.
+func InvalidUniqueItemsError(ctx string, dup interface{}) error {
+	msg := fmt.Sprintf("%s must contain unique items but %#v is repeated", ctx, dup)
+	return validationError("unique_items", ctx, msg, "attribute", ctx, "duplicate", dup)
 }
 
 // InvalidLengthError is the error produced when the value of a parameter or payload field does
 // not match the length validation defined in the design.
 func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool) error {
-	comp := "greater or equal"
+	comp, code := "greater or equal", "min_length"
 	if !min {
-		comp = "lesser or equal"
+		comp, code = "lesser or equal", "max_length"
 	}
 	msg := fmt.Sprintf("length of %s must be %s than %d but got value %#v (len=%d)", ctx, comp, value, target, ln)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	return validationError(code, ctx, msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+}
+
+// validationError creates an ErrInvalidRequest error that lists a single validation error with the
+// given code, field and message.
+func validationError(code, field, msg string, keyvals ...interface{}) error {
+	err := ErrInvalidRequest(msg, keyvals...).(*ErrorResponse)
+	err.Errors = []*ValidationError{{Code: code, Field: field, Message: msg}}
+	return err
+}
+
+// UnprocessableEntityError is the error produced when a request fails semantic validation. The
+// resulting error has status 422 and lists the field errors in its "errors" metadata.
+func UnprocessableEntityError(errors []FieldError) error {
+	msgs := make([]string, len(errors))
+	for i, e := range errors {
+		msgs[i] = fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	return ErrUnprocessableEntity(strings.Join(msgs, "; "), "errors", errors)
 }
 
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
@@ -242,6 +428,117 @@ func (e *ErrorResponse) Error() string {
 	return msg
 }
 
+// Error returns the messages of the errors separated by newlines.
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Errors returns the individual errors.
+func (m MultiError) Errors() []error {
+	return []error(m)
+}
+
+// ResponseStatus returns the status shared by all the errors if any, 500 if any error is not a
+// ServiceError or is an internal error and 400 otherwise.
+func (m MultiError) ResponseStatus() int {
+	status := 0
+	for _, err := range m {
+		serr, ok := err.(ServiceError)
+		if !ok || serr.ResponseStatus() >= 500 {
+			return http.StatusInternalServerError
+		}
+		if status == 0 {
+			status = serr.ResponseStatus()
+		} else if status != serr.ResponseStatus() {
+			status = http.StatusBadRequest
+		}
+	}
+	if status == 0 {
+		return http.StatusBadRequest
+	}
+	return status
+}
+
+// Token returns the token of the first error.
+func (m MultiError) Token() string {
+	for _, err := range m {
+		if serr, ok := err.(ServiceError); ok {
+			return serr.Token()
+		}
+	}
+	return ""
+}
+
+// Responses returns the error responses corresponding to the errors, errors that are not
+// ErrorResponse values become internal errors.
+func (m MultiError) Responses() ErrorResponses {
+	res := make(ErrorResponses, len(m))
+	for i, err := range m {
+		res[i] = asErrorResponse(err)
+	}
+	return res
+}
+
+// MarshalJSON serializes the errors as a list of error responses.
+func (m MultiError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]*ErrorResponse(m.Responses()))
+}
+
+// MarshalXML serializes the errors as a list of error responses.
+func (m MultiError) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return m.Responses().MarshalXML(e, start)
+}
+
+// MarshalXML serializes the error responses as "error" elements of an "errors" element.
+func (r ErrorResponses) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "errors"}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, res := range r {
+		if err := e.EncodeElement(res, xml.StartElement{Name: xml.Name{Local: "error"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// MarshalXML serializes the error, the metadata values are written as "meta" elements whose
+// "key" attribute holds the key.
+func (e *ErrorResponse) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	res := xmlErrorResponse{ID: e.ID, Code: e.Code, Status: e.Status, Detail: e.Detail, Errors: e.Errors}
+	for _, val := range e.Meta {
+		for k, v := range val {
+			res.Meta = append(res.Meta, xmlMeta{Key: k, Value: fmt.Sprintf("%v", v)})
+		}
+	}
+	return enc.EncodeElement(res, start)
+}
+
+// AppendError returns a MultiError listing the errors of err followed by the errors of other.
+// It returns err if other is nil and other if err is nil so that single errors are not wrapped.
+// The generated code uses AppendError to collect all the validation errors of a request.
+func AppendError(err, other error) error {
+	if other == nil {
+		return err
+	}
+	if err == nil {
+		return other
+	}
+	m, ok := err.(MultiError)
+	if !ok {
+		m = MultiError{err}
+	}
+	if o, ok := other.(MultiError); ok {
+		return append(m, o...)
+	}
+	return append(m, other)
+}
+
 // ResponseStatus is the status used to build responses.
 func (e *ErrorResponse) ResponseStatus() int { return e.Status }
 
@@ -285,6 +582,7 @@ func MergeErrors(err, other error) error {
 		e.Code = "bad_request"
 	}
 	e.Detail = e.Detail + "; " + o.Detail
+	e.Errors = append(e.Errors, o.Errors...)
 
 	for _, val := range o.Meta {
 		for k, v := range val {
@@ -295,6 +593,14 @@ func MergeErrors(err, other error) error {
 }
 
 func asErrorResponse(err error) *ErrorResponse {
+	if m, ok := err.(MultiError); ok && len(m) > 0 {
+		merged := *asErrorResponse(m[0])
+		var res error = &merged
+		for _, other := range m[1:] {
+			res = MergeErrors(res, other)
+		}
+		return res.(*ErrorResponse)
+	}
 	e, ok := err.(*ErrorResponse)
 	if !ok {
 		return &ErrorResponse{Status: 500, Code: "internal_error", Detail: err.Error()}
@@ -2,8 +2,10 @@ package goa
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -65,6 +67,14 @@ var _ = Describe("MissingParaerror", func() {
 		err := valErr.(*ErrorResponse)
 		Ω(err.Detail).Should(ContainSubstring(name))
 	})
+
+	It("records a required validation error", func() {
+		err := valErr.(*ErrorResponse)
+		Ω(err.Errors).Should(HaveLen(1))
+		Ω(err.Errors[0].Code).Should(Equal("required"))
+		Ω(err.Errors[0].Field).Should(Equal(name))
+		Ω(err.Errors[0].Message).Should(Equal(err.Detail))
+	})
 })
 
 var _ = Describe("InvalidAttributeTypeError", func() {
@@ -103,6 +113,13 @@ var _ = Describe("MissingAttributeError", func() {
 		Ω(err.Detail).Should(ContainSubstring(ctx))
 		Ω(err.Detail).Should(ContainSubstring(name))
 	})
+
+	It("records a required validation error for the attribute", func() {
+		err := valErr.(*ErrorResponse)
+		Ω(err.Errors).Should(HaveLen(1))
+		Ω(err.Errors[0].Code).Should(Equal("required"))
+		Ω(err.Errors[0].Field).Should(Equal(ctx + "." + name))
+	})
 })
 
 var _ = Describe("MissingHeaderError", func() {
@@ -121,6 +138,47 @@ var _ = Describe("MissingHeaderError", func() {
 	})
 })
 
+var _ = Describe("MissingResponseHeaderError", func() {
+	var valErr error
+	action := "show"
+	name := "X-Request-Id"
+
+	JustBeforeEach(func() {
+		valErr = MissingResponseHeaderError(action, name)
+	})
+
+	It("creates a descriptive internal error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(500))
+		Ω(err.Detail).Should(ContainSubstring(action))
+		Ω(err.Detail).Should(ContainSubstring(name))
+	})
+})
+
+var _ = Describe("InvalidResponseHeaderError", func() {
+	var valErr error
+	action := "show"
+	name := "X-Request-Id"
+	val := "foo"
+	pattern := "^[0-9]+$"
+
+	JustBeforeEach(func() {
+		valErr = InvalidResponseHeaderError(action, name, val, pattern)
+	})
+
+	It("creates a descriptive internal error", func() {
+		Ω(valErr).ShouldNot(BeNil())
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Status).Should(Equal(500))
+		Ω(err.Detail).Should(ContainSubstring(name))
+		Ω(err.Detail).Should(ContainSubstring(val))
+		Ω(err.Detail).Should(ContainSubstring(pattern))
+	})
+})
+
 var _ = Describe("InvalidEnumValueError", func() {
 	var valErr error
 	ctx := "ctx"
@@ -161,6 +219,13 @@ var _ = Describe("InvalidFormaerror", func() {
 		Ω(err.Detail).Should(ContainSubstring("date-time"))
 		Ω(err.Detail).Should(ContainSubstring(formatError.Error()))
 	})
+
+	It("records a format validation error", func() {
+		err := valErr.(*ErrorResponse)
+		Ω(err.Errors).Should(HaveLen(1))
+		Ω(err.Errors[0].Code).Should(Equal("format"))
+		Ω(err.Errors[0].Field).Should(Equal(ctx))
+	})
 })
 
 var _ = Describe("InvalidPatternError", func() {
@@ -228,6 +293,42 @@ var _ = Describe("InvalidRangeError", func() {
 	})
 })
 
+var _ = Describe("InvalidExclusiveRangeError", func() {
+	const ctx = "ctx"
+	const target = 0
+
+	var min bool
+	var valErr error
+
+	JustBeforeEach(func() {
+		valErr = InvalidExclusiveRangeError(ctx, target, 0, min)
+	})
+
+	Context("with an exclusive minimum", func() {
+		BeforeEach(func() {
+			min = true
+		})
+
+		It("creates a http error", func() {
+			Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+			err := valErr.(*ErrorResponse)
+			Ω(err.Detail).Should(Equal("ctx must be greater than 0 but got value 0"))
+		})
+	})
+
+	Context("with an exclusive maximum", func() {
+		BeforeEach(func() {
+			min = false
+		})
+
+		It("creates a http error", func() {
+			Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+			err := valErr.(*ErrorResponse)
+			Ω(err.Detail).Should(Equal("ctx must be lesser than 0 but got value 0"))
+		})
+	})
+})
+
 var _ = Describe("InvalidLengthError", func() {
 	const ctx = "ctx"
 	const value = 42
@@ -257,6 +358,19 @@ var _ = Describe("InvalidLengthError", func() {
 			Ω(err.Detail).Should(ContainSubstring(fmt.Sprintf("%#v", value)))
 			Ω(err.Detail).Should(ContainSubstring(target.(string)))
 		})
+
+		It("records a min_length validation error", func() {
+			err := valErr.(*ErrorResponse)
+			Ω(err.Errors).Should(HaveLen(1))
+			Ω(err.Errors[0].Code).Should(Equal("min_length"))
+			Ω(err.Errors[0].Field).Should(Equal(ctx))
+		})
+
+		It("serializes the validation errors", func() {
+			b, err := json.Marshal(valErr)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"errors":[{"code":"min_length","field":"ctx","message":`))
+		})
 	})
 
 	Context("on slices", func() {
@@ -365,6 +479,19 @@ var _ = Describe("Merge", func() {
 				Ω(mErr.Detail).Should(Equal(detail + "; " + mErr2.Detail))
 			})
 
+			Context("with validation errors", func() {
+				BeforeEach(func() {
+					err = MissingAttributeError("ctx", "foo")
+					err2 = InvalidFormatError("ctx.bar", "baz", FormatEmail, errors.New("boo"))
+				})
+
+				It("keeps both validation errors", func() {
+					Ω(mErr.Errors).Should(HaveLen(2))
+					Ω(mErr.Errors[0].Code).Should(Equal("required"))
+					Ω(mErr.Errors[1].Code).Should(Equal("format"))
+				})
+			})
+
 			It("uses the common status", func() {
 				Ω(mErr.Status).Should(Equal(status))
 			})
@@ -435,3 +562,80 @@ var _ = Describe("Merge", func() {
 	})
 
 })
+
+var _ = Describe("MultiError", func() {
+	var merr MultiError
+
+	BeforeEach(func() {
+		merr = MultiError{
+			InvalidAttributeTypeError("payload.name", 1, "string"),
+			MissingAttributeError("payload", "count"),
+		}
+	})
+
+	It("lists all the error messages", func() {
+		lines := strings.Split(merr.Error(), "\n")
+		Ω(lines).Should(HaveLen(2))
+		Ω(lines[0]).Should(ContainSubstring(`type of payload.name must be string`))
+		Ω(lines[1]).Should(ContainSubstring(`attribute "count" of payload is missing`))
+	})
+
+	It("exposes the individual errors", func() {
+		Ω(merr.Errors()).Should(HaveLen(2))
+		Ω(merr.Errors()[1]).Should(Equal(merr[1]))
+	})
+
+	It("uses the status shared by the errors", func() {
+		Ω(merr.ResponseStatus()).Should(Equal(400))
+		Ω(merr.Token()).Should(Equal(merr[0].(ServiceError).Token()))
+	})
+
+	It("responds with an internal error if any error is not a service error", func() {
+		merr = append(merr, errors.New("boom"))
+		Ω(merr.ResponseStatus()).Should(Equal(500))
+	})
+
+	It("serializes as a list of errors", func() {
+		b, err := json.Marshal(merr)
+		Ω(err).ShouldNot(HaveOccurred())
+		var decoded []*ErrorResponse
+		Ω(json.Unmarshal(b, &decoded)).ShouldNot(HaveOccurred())
+		Ω(decoded).Should(HaveLen(2))
+		for i, e := range decoded {
+			Ω(e.ID).Should(Equal(merr[i].(*ErrorResponse).ID))
+			Ω(e.Detail).Should(Equal(merr[i].(*ErrorResponse).Detail))
+		}
+	})
+
+	It("serializes to XML", func() {
+		b, err := xml.Marshal(merr)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(HavePrefix("<errors><error><id>"))
+		Ω(string(b)).Should(ContainSubstring(`<meta key="attribute">count</meta>`))
+		Ω(strings.Count(string(b), "<error>")).Should(Equal(2))
+	})
+
+	It("merges into a single error listing all the details", func() {
+		e := MergeErrors(merr, nil).(*ErrorResponse)
+		Ω(e.Status).Should(Equal(400))
+		Ω(e.Detail).Should(Equal(merr[0].(*ErrorResponse).Detail + "; " + merr[1].(*ErrorResponse).Detail))
+		Ω(merr[0].(*ErrorResponse).Detail).ShouldNot(ContainSubstring(";"))
+	})
+})
+
+var _ = Describe("AppendError", func() {
+	var err, other error
+
+	It("returns the non nil error", func() {
+		other = MissingAttributeError("payload", "count")
+		Ω(AppendError(nil, other)).Should(Equal(other))
+		Ω(AppendError(other, nil)).Should(Equal(other))
+	})
+
+	It("lists the errors", func() {
+		err = AppendError(MissingAttributeError("payload", "count"), MissingAttributeError("payload", "name"))
+		err = AppendError(err, MultiError{InvalidAttributeTypeError("payload.name", 1, "string")})
+		Ω(err).Should(BeAssignableToTypeOf(MultiError{}))
+		Ω(err.(MultiError)).Should(HaveLen(3))
+	})
+})
@@ -77,6 +77,7 @@ func (f *Finalizer) recurse(att *design.AttributeDefinition, target string, dept
 					"catt":       catt,
 					"depth":      depth,
 					"isDatetime": catt.Type == design.DateTime,
+					"isInteger":  catt.Type.Kind() == design.IntegerKind,
 					"defaultVal": printVal(catt.Type, catt.DefaultValue),
 				}
 				if !first {
@@ -163,7 +164,7 @@ func printVal(t design.DataType, val interface{}) string {
 
 const (
 	assignmentTmpl = `{{ if .catt.Type.IsPrimitive }}{{ $defaultName := (print "default" (goify .field true)) }}{{/*
-*/}}{{ tabs .depth }}var {{ $defaultName }}{{if .isDatetime}}, _{{end}} = {{ .defaultVal }}
+*/}}{{ tabs .depth }}var {{ $defaultName }}{{ if .isInteger }} int64{{ end }}{{if .isDatetime}}, _{{end}} = {{ .defaultVal }}
 {{ tabs .depth }}if {{ .target }}.{{ goify .field true }} == nil {
 {{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = &{{ $defaultName }}
 }{{ else }}{{ tabs .depth }}if {{ .target }}.{{ goify .field true }} == nil {
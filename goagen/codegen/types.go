@@ -131,7 +131,8 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 	return buffer.String()
 }
 
-// attributeTags computes the struct field tags.
+// attributeTags computes the struct field tags. The "scopes" metadata of the attribute produces
+// the "scopes" tag used by goa.RenderScoped.
 func attributeTags(parent, att *design.AttributeDefinition, name string, private bool) string {
 	var elems []string
 	keys := make([]string, len(att.Metadata))
@@ -157,7 +158,11 @@ func attributeTags(parent, att *design.AttributeDefinition, name string, private
 	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
 		omit = ",omitempty"
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"`", name, omit, name, omit, name, omit)
+	var scopes string
+	if s := att.Metadata["scopes"]; len(s) > 0 {
+		scopes = fmt.Sprintf(" scopes:\"%s\"", strings.Join(s, ","))
+	}
+	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"%s`", name, omit, name, omit, name, omit, scopes)
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -225,7 +230,7 @@ func GoNativeType(t design.DataType) string {
 		case design.BooleanKind:
 			return "bool"
 		case design.IntegerKind:
-			return "int"
+			return "int64"
 		case design.NumberKind:
 			return "float64"
 		case design.StringKind:
@@ -236,6 +241,10 @@ func GoNativeType(t design.DataType) string {
 			return "uuid.UUID"
 		case design.AnyKind:
 			return "interface{}"
+		case design.FileKind:
+			return "multipart.FileHeader"
+		case design.DecimalKind:
+			return "goa.Decimal"
 		default:
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
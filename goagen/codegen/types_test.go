@@ -206,7 +206,7 @@ var _ = Describe("code generation", func() {
 					expected := "struct {\n" +
 						"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
 						"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
-						"	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
+						"	Foo *int64 `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
 						"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
 						"}"
 					Ω(st).Should(Equal(expected))
@@ -230,7 +230,7 @@ var _ = Describe("code generation", func() {
 						expected := fmt.Sprintf("struct {\n"+
 							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n"+
 							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\"`\n"+
-							"	Foo *int `%s:\"%s,%s\" %s:\"%s\"`\n"+
+							"	Foo *int64 `%s:\"%s,%s\" %s:\"%s\"`\n"+
 							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n"+
 							"}", tn1[11:], tv11, tv12, tn2[11:], tv21)
 						Ω(st).Should(Equal(expected))
@@ -248,7 +248,7 @@ var _ = Describe("code generation", func() {
 						expected := "struct {\n" +
 							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
 							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
-							"	ServiceName *int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
+							"	ServiceName *int64 `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
 							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
 							"}"
 						Ω(st).Should(Equal(expected))
@@ -256,6 +256,24 @@ var _ = Describe("code generation", func() {
 				})
 			})
 
+			Context("of file types", func() {
+				BeforeEach(func() {
+					object = Object{
+						"avatar": &AttributeDefinition{Type: File},
+						"extras": &AttributeDefinition{Type: &Array{ElemType: &AttributeDefinition{Type: File}}},
+					}
+					required = &dslengine.ValidationDefinition{Required: []string{"extras"}}
+				})
+
+				It("produces the struct go code", func() {
+					expected := "struct {\n" +
+						"	Avatar *multipart.FileHeader `form:\"avatar,omitempty\" json:\"avatar,omitempty\" xml:\"avatar,omitempty\"`\n" +
+						"	Extras []multipart.FileHeader `form:\"extras\" json:\"extras\" xml:\"extras\"`\n" +
+						"}"
+					Ω(st).Should(Equal(expected))
+				})
+			})
+
 			Context("of hash of primitive types", func() {
 				BeforeEach(func() {
 					elemType := &AttributeDefinition{Type: Integer}
@@ -268,7 +286,7 @@ var _ = Describe("code generation", func() {
 				})
 
 				It("produces the struct go code", func() {
-					Ω(st).Should(Equal("struct {\n\tFoo map[int]int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n}"))
+					Ω(st).Should(Equal("struct {\n\tFoo map[int64]int64 `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n}"))
 				})
 			})
 
@@ -283,7 +301,7 @@ var _ = Describe("code generation", func() {
 				})
 
 				It("produces the struct go code", func() {
-					Ω(st).Should(Equal("struct {\n\tFoo []int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n}"))
+					Ω(st).Should(Equal("struct {\n\tFoo []int64 `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n}"))
 				})
 			})
 
@@ -309,7 +327,7 @@ var _ = Describe("code generation", func() {
 						"	Foo map[*struct {\n" +
 						"		KeyAtt *string `form:\"keyAtt,omitempty\" json:\"keyAtt,omitempty\" xml:\"keyAtt,omitempty\"`\n" +
 						"	}]*struct {\n" +
-						"		ElemAtt *int `form:\"elemAtt,omitempty\" json:\"elemAtt,omitempty\" xml:\"elemAtt,omitempty\"`\n" +
+						"		ElemAtt *int64 `form:\"elemAtt,omitempty\" json:\"elemAtt,omitempty\" xml:\"elemAtt,omitempty\"`\n" +
 						"	} `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
 						"}"
 					Ω(st).Should(Equal(expected))
@@ -332,7 +350,7 @@ var _ = Describe("code generation", func() {
 				It("produces the struct go code", func() {
 					expected := "struct {\n" +
 						"	Foo []*struct {\n" +
-						"		Bar *int `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+						"		Bar *int64 `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
 						"	} `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n" +
 						"}"
 					Ω(st).Should(Equal(expected))
@@ -348,7 +366,7 @@ var _ = Describe("code generation", func() {
 					It("produces the struct go code", func() {
 						expected := "struct {\n" +
 							"	Foo []*struct {\n" +
-							"		Bar *int `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"		Bar *int64 `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
 							"	} `form:\"foo\" json:\"foo\" xml:\"foo\"`\n" +
 							"}"
 						Ω(st).Should(Equal(expected))
@@ -368,7 +386,7 @@ var _ = Describe("code generation", func() {
 
 				It("produces the struct go code", func() {
 					expected := "struct {\n" +
-						"	Foo int `form:\"foo\" json:\"foo\" xml:\"foo\"`\n" +
+						"	Foo int64 `form:\"foo\" json:\"foo\" xml:\"foo\"`\n" +
 						"}"
 					Ω(st).Should(Equal(expected))
 				})
@@ -392,7 +410,7 @@ var _ = Describe("code generation", func() {
 				})
 
 				It("produces the array go code", func() {
-					Ω(source).Should(Equal("[]int"))
+					Ω(source).Should(Equal("[]int64"))
 				})
 
 			})
@@ -407,7 +425,7 @@ var _ = Describe("code generation", func() {
 				})
 
 				It("produces the array go code", func() {
-					Ω(source).Should(Equal("[]*struct {\n\tBar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n\tFoo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n}"))
+					Ω(source).Should(Equal("[]*struct {\n\tBar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n\tFoo *int64 `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\"`\n}"))
 				})
 			})
 		})
@@ -493,7 +511,7 @@ var _ = Describe("GoTypeTransform", func() {
 		It("generates a simple assignment", func() {
 			Ω(transform).Should(Equal(`func Transform(source *Source) (target *Target) {
 	target = new(Target)
-	target.Att = make([]int, len(source.Att))
+	target.Att = make([]int64, len(source.Att))
 	for i, v := range source.Att {
 		target.Att[i] = source.Att[i]
 	}
@@ -577,9 +595,9 @@ var _ = Describe("GoTypeTransform", func() {
 		target.Array.Elem[i].In.Foo = source.Array.Elem[i].In.Foo
 	}
 	target.Hash = new(Hash)
-	target.Hash.Elem = make(map[int]*Outer, len(source.Hash.Elem))
+	target.Hash.Elem = make(map[int64]*Outer, len(source.Hash.Elem))
 	for k, v := range source.Hash.Elem {
-		var tk int
+		var tk int64
 		tk = k
 		var tv *Outer
 		tv = new(Outer)
@@ -4,23 +4,28 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
 
 var (
-	enumValT     *template.Template
-	formatValT   *template.Template
-	patternValT  *template.Template
-	minMaxValT   *template.Template
-	lengthValT   *template.Template
-	requiredValT *template.Template
+	enumValT       *template.Template
+	formatValT     *template.Template
+	patternValT    *template.Template
+	minMaxValT     *template.Template
+	timeRangeValT  *template.Template
+	multipleOfValT *template.Template
+	lengthValT     *template.Template
+	uniqueValT     *template.Template
+	requiredValT   *template.Template
 )
 
-//  init instantiates the templates.
+// init instantiates the templates.
 func init() {
 	var err error
 	fm := template.FuncMap{
@@ -43,9 +48,18 @@ func init() {
 	if minMaxValT, err = template.New("minMax").Funcs(fm).Parse(minMaxValTmpl); err != nil {
 		panic(err)
 	}
+	if timeRangeValT, err = template.New("timeRange").Funcs(fm).Parse(timeRangeValTmpl); err != nil {
+		panic(err)
+	}
+	if multipleOfValT, err = template.New("multipleOf").Funcs(fm).Parse(multipleOfValTmpl); err != nil {
+		panic(err)
+	}
 	if lengthValT, err = template.New("length").Funcs(fm).Parse(lengthValTmpl); err != nil {
 		panic(err)
 	}
+	if uniqueValT, err = template.New("unique").Funcs(fm).Parse(uniqueValTmpl); err != nil {
+		panic(err)
+	}
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
@@ -53,6 +67,9 @@ func init() {
 
 // Validator is the code generator for the 'Validate' type methods.
 type Validator struct {
+	// FailFast causes the generated code to return the first validation error, see
+	// FailFastCode.
+	FailFast  bool
 	arrayValT *template.Template
 	userValT  *template.Template
 	seen      map[string]*bytes.Buffer
@@ -87,9 +104,27 @@ func NewValidator() *Validator {
 // Code produces Go code that runs the validation checks recursively over the given attribute.
 func (v *Validator) Code(att *design.AttributeDefinition, nonzero, required, hasDefault bool, target, context string, depth int, private bool) string {
 	buf := v.recurse(att, nonzero, required, hasDefault, target, context, depth, private)
+	if v.FailFast {
+		return FailFastCode(buf.String(), "err")
+	}
 	return buf.String()
 }
 
+// FailFastCode returns a copy of the given validation code where each statement that records a
+// validation error is followed by a statement that returns ret, e.g. "err" or "nil, err". The
+// resulting code skips the remaining validations once a validation fails.
+func FailFastCode(code, ret string) string {
+	lines := strings.Split(code, "\n")
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		res = append(res, line)
+		if trimmed := strings.TrimLeft(line, "\t"); strings.HasPrefix(trimmed, "err = goa.AppendError(") {
+			res = append(res, line[:len(line)-len(trimmed)]+"return "+ret)
+		}
+	}
+	return strings.Join(res, "\n")
+}
+
 func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required, hasDefault bool, target, context string, depth int, private bool) *bytes.Buffer {
 	var (
 		buf   = new(bytes.Buffer)
@@ -146,7 +181,7 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 				"target": "e",
 			})
 		} else {
-			val = v.Code(a.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
+			val = v.recurse(a.ElemType, true, false, false, "e", context+"[*]", depth+1, false).String()
 		}
 		if val != "" {
 			data := map[string]interface{}{
@@ -294,6 +329,7 @@ func validationsCode(validation *dslengine.ValidationDefinition, data map[string
 	if min := validation.Minimum; min != nil {
 		data["min"] = *min
 		data["isMin"] = true
+		data["exclusive"] = validation.ExclusiveMinimum
 		delete(data, "max")
 		if val := RunTemplate(minMaxValT, data); val != "" {
 			res = append(res, val)
@@ -302,11 +338,43 @@ func validationsCode(validation *dslengine.ValidationDefinition, data map[string
 	if max := validation.Maximum; max != nil {
 		data["max"] = *max
 		data["isMin"] = false
+		data["exclusive"] = validation.ExclusiveMaximum
 		delete(data, "min")
 		if val := RunTemplate(minMaxValT, data); val != "" {
 			res = append(res, val)
 		}
 	}
+	if min := validation.MinimumTime; min != nil {
+		data["bound"] = timeCode(*min)
+		data["isMin"] = true
+		data["exclusive"] = validation.ExclusiveMinimum
+		if val := RunTemplate(timeRangeValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if max := validation.MaximumTime; max != nil {
+		data["bound"] = timeCode(*max)
+		data["isMin"] = false
+		data["exclusive"] = validation.ExclusiveMaximum
+		if val := RunTemplate(timeRangeValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
+	if multipleOf := validation.MultipleOf; multipleOf != nil {
+		data["multipleOf"] = *multipleOf
+		data["integer"] = false
+		// Integer values are checked exactly when the divisor is integral, the floating point
+		// tolerance of ValidateMultipleOf only applies to numbers.
+		if att, ok := data["attribute"].(*design.AttributeDefinition); ok && att.Type.Kind() == design.IntegerKind {
+			if d := *multipleOf; d == math.Trunc(d) && d < math.MaxInt64 {
+				data["multipleOf"] = int64(d)
+				data["integer"] = true
+			}
+		}
+		if val := RunTemplate(multipleOfValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	if minLength := validation.MinLength; minLength != nil {
 		data["minLength"] = minLength
 		data["isMinLength"] = true
@@ -323,6 +391,11 @@ func validationsCode(validation *dslengine.ValidationDefinition, data map[string
 			res = append(res, val)
 		}
 	}
+	if validation.UniqueItems {
+		if val := RunTemplate(uniqueValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	if required := validation.Required; len(required) > 0 {
 		data["required"] = required
 		if val := RunTemplate(requiredValT, data); val != "" {
@@ -342,6 +415,14 @@ func oneof(target string, vals []interface{}) string {
 	return strings.Join(elems, " || ")
 }
 
+// timeCode produces the code that builds the given time in UTC, e.g.
+// "time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)".
+func timeCode(t time.Time) string {
+	t = t.UTC()
+	return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, %d, time.UTC)",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
+
 // constant returns the Go constant name of the format with the given value.
 func constant(formatName string) string {
 	switch formatName {
@@ -375,34 +456,48 @@ const (
 {{tabs .depth}}}`
 
 	userValTmpl = `{{tabs .depth}}if err2 := {{.target}}.Validate(); err2 != nil {
-{{tabs .depth}}	err = goa.MergeErrors(err, err2)
+{{tabs .depth}}	err = goa.AppendError(err, err2)
 {{tabs .depth}}}`
 
 	enumValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
 */}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
 {{end}}{{tabs $depth}}if !({{oneof .targetVal .values}}) {
-{{tabs $depth}}	err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{slice .values}}))
+{{tabs $depth}}	err = goa.AppendError(err, goa.InvalidEnumValueError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{slice .values}}))
 {{if .isPointer}}{{tabs $depth}}}
 {{end}}{{tabs .depth}}}`
 
 	patternValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
 */}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
 {{end}}{{tabs $depth}}if ok := goa.ValidatePattern(` + "`{{.pattern}}`" + `, {{.targetVal}}); !ok {
-{{tabs $depth}}	err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, ` + "`{{.pattern}}`" + `))
+{{tabs $depth}}	err = goa.AppendError(err, goa.InvalidPatternError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, ` + "`{{.pattern}}`" + `))
 {{tabs $depth}}}{{if .isPointer}}
 {{tabs .depth}}}{{end}}`
 
 	formatValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
 */}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
 {{end}}{{tabs $depth}}if err2 := goa.ValidateFormat({{constant .format}}, {{.targetVal}}); err2 != nil {
-{{tabs $depth}}		err = goa.MergeErrors(err, goa.InvalidFormatError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{constant .format}}, err2))
+{{tabs $depth}}		err = goa.AppendError(err, goa.InvalidFormatError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{constant .format}}, err2))
 {{if .isPointer}}{{tabs $depth}}}
 {{end}}{{tabs .depth}}}`
 
 	minMaxValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
 */}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
-{{end}}{{tabs .depth}}	if {{.targetVal}} {{if .isMin}}<{{else}}>{{end}} {{if .isMin}}{{.min}}{{else}}{{.max}}{{end}} {
-{{tabs $depth}}	err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{if .isMin}}{{.min}}, true{{else}}{{.max}}, false{{end}}))
+{{end}}{{tabs .depth}}	if {{.targetVal}} {{if .isMin}}<{{else}}>{{end}}{{if .exclusive}}={{end}} {{if .isMin}}{{.min}}{{else}}{{.max}}{{end}} {
+{{tabs $depth}}	err = goa.AppendError(err, goa.Invalid{{if .exclusive}}Exclusive{{end}}RangeError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{if .isMin}}{{.min}}, true{{else}}{{.max}}, false{{end}}))
+{{if .isPointer}}{{tabs $depth}}}
+{{end}}{{tabs .depth}}}`
+
+	timeRangeValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
+*/}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
+{{end}}{{tabs $depth}}if {{if .exclusive}}!{{end}}{{.target}}.{{if eq .isMin .exclusive}}After{{else}}Before{{end}}({{.bound}}) {
+{{tabs $depth}}	err = goa.AppendError(err, goa.Invalid{{if .exclusive}}Exclusive{{end}}RangeError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{.bound}}, {{.isMin}}))
+{{if .isPointer}}{{tabs $depth}}}
+{{end}}{{tabs .depth}}}`
+
+	multipleOfValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
+*/}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
+{{end}}{{tabs $depth}}if {{if .integer}}{{.targetVal}}%{{.multipleOf}} != 0{{else}}!goa.ValidateMultipleOf(float64({{.targetVal}}), {{.multipleOf}}){{end}} {
+{{tabs $depth}}	err = goa.AppendError(err, goa.InvalidMultipleOfError(` + "`" + `{{.context}}` + "`" + `, {{.targetVal}}, {{.multipleOf}}))
 {{if .isPointer}}{{tabs $depth}}}
 {{end}}{{tabs .depth}}}`
 
@@ -410,16 +505,24 @@ const (
 */}}{{$target := or (and (or (or .array .hash) .nonzero) .target) .targetVal}}{{/*
 */}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
 {{end}}{{tabs .depth}}	if {{if .string}}utf8.RuneCountInString({{$target}}){{else}}len({{$target}}){{end}} {{if .isMinLength}}<{{else}}>{{end}} {{if .isMinLength}}{{.minLength}}{{else}}{{.maxLength}}{{end}} {
-{{tabs $depth}}	err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `{{.context}}` + "`" + `, {{$target}}, {{if .string}}utf8.RuneCountInString({{$target}}){{else}}len({{$target}}){{end}}, {{if .isMinLength}}{{.minLength}}, true{{else}}{{.maxLength}}, false{{end}}))
+{{tabs $depth}}	err = goa.AppendError(err, goa.InvalidLengthError(` + "`" + `{{.context}}` + "`" + `, {{$target}}, {{if .string}}utf8.RuneCountInString({{$target}}){{else}}len({{$target}}){{end}}, {{if .isMinLength}}{{.minLength}}, true{{else}}{{.maxLength}}, false{{end}}))
+{{if .isPointer}}{{tabs $depth}}}
+{{end}}{{tabs .depth}}}`
+
+	uniqueValTmpl = `{{$depth := or (and .isPointer (add .depth 1)) .depth}}{{/*
+*/}}{{$target := or (and (or (or .array .hash) .nonzero) .target) .targetVal}}{{/*
+*/}}{{if .isPointer}}{{tabs .depth}}if {{.target}} != nil {
+{{end}}{{tabs $depth}}if dup, ok := goa.ValidateUniqueItems({{$target}}); !ok {
+{{tabs $depth}}	err = goa.AppendError(err, goa.InvalidUniqueItemsError(` + "`" + `{{.context}}` + "`" + `, dup))
 {{if .isPointer}}{{tabs $depth}}}
 {{end}}{{tabs .depth}}}`
 
 	requiredValTmpl = `{{range $r := .required}}{{$catt := index $.attribute.Type.ToObject $r}}{{/*
 */}}{{if and (not $.private) (eq $catt.Type.Kind 4)}}{{tabs $.depth}}if {{$.target}}.{{goifyAtt $catt $r true}} == "" {
-{{tabs $.depth}}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{$.context}}` + "`" + `, "{{$r}}"))
+{{tabs $.depth}}	err = goa.AppendError(err, goa.MissingAttributeError(` + "`" + `{{$.context}}` + "`" + `, "{{$r}}"))
 {{tabs $.depth}}}
 {{else if or $.private (not $catt.Type.IsPrimitive)}}{{tabs $.depth}}if {{$.target}}.{{goifyAtt $catt $r true}} == nil {
-{{tabs $.depth}}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{$.context}}` + "`" + `, "{{$r}}"))
+{{tabs $.depth}}	err = goa.AppendError(err, goa.MissingAttributeError(` + "`" + `{{$.context}}` + "`" + `, "{{$r}}"))
 {{tabs $.depth}}}
 {{end}}{{end}}`
 )
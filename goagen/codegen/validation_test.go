@@ -2,6 +2,7 @@ package codegen_test
 
 import (
 	"strings"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -71,6 +72,68 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of exclusive min value 0 and exclusive max value 10", func() {
+				BeforeEach(func() {
+					attType = design.Number
+					min, max := 0.0, 10.0
+					validation = &dslengine.ValidationDefinition{
+						Minimum:          &min,
+						Maximum:          &max,
+						ExclusiveMinimum: true,
+						ExclusiveMaximum: true,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(exclusiveMinMaxValCode))
+				})
+			})
+
+			Context("of date time min and exclusive max", func() {
+				BeforeEach(func() {
+					attType = design.DateTime
+					min := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+					max := time.Date(2017, time.January, 1, 12, 30, 0, 0, time.UTC)
+					validation = &dslengine.ValidationDefinition{
+						MinimumTime:      &min,
+						MaximumTime:      &max,
+						ExclusiveMaximum: true,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(timeMinExclusiveMaxValCode))
+				})
+			})
+
+			Context("of multiple of 0.01", func() {
+				BeforeEach(func() {
+					attType = design.Number
+					multipleOf := 0.01
+					validation = &dslengine.ValidationDefinition{
+						MultipleOf: &multipleOf,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(multipleOfValCode))
+				})
+			})
+
+			Context("of integer multiple of 10", func() {
+				BeforeEach(func() {
+					attType = design.Integer
+					multipleOf := 10.0
+					validation = &dslengine.ValidationDefinition{
+						MultipleOf: &multipleOf,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(intMultipleOfValCode))
+				})
+			})
+
 			Context("of array min length 1", func() {
 				BeforeEach(func() {
 					attType = &design.Array{
@@ -89,6 +152,23 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of array unique items", func() {
+				BeforeEach(func() {
+					attType = &design.Array{
+						ElemType: &design.AttributeDefinition{
+							Type: design.String,
+						},
+					}
+					validation = &dslengine.ValidationDefinition{
+						UniqueItems: true,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(arrayUniqueItemsValCode))
+				})
+			})
+
 			Context("of string min length 2", func() {
 				BeforeEach(func() {
 					attType = design.String
@@ -169,55 +249,143 @@ var _ = Describe("validation code generation", func() {
 
 		})
 	})
+
+	Describe("Validator", func() {
+		var att *design.AttributeDefinition
+		var failFast bool
+		var code string // generated code
+
+		BeforeEach(func() {
+			min := 2
+			att = &design.AttributeDefinition{
+				Type: design.Object{
+					"foo": &design.AttributeDefinition{
+						Type:       design.String,
+						Validation: &dslengine.ValidationDefinition{MinLength: &min},
+					},
+				},
+				Validation: &dslengine.ValidationDefinition{Required: []string{"foo"}},
+			}
+			failFast = false
+		})
+
+		JustBeforeEach(func() {
+			v := codegen.NewValidator()
+			v.FailFast = failFast
+			code = v.Code(att, false, false, false, "val", "context", 1, true)
+		})
+
+		It("collects all the validation errors", func() {
+			Ω(code).Should(ContainSubstring("goa.AppendError("))
+			Ω(code).ShouldNot(ContainSubstring("return"))
+		})
+
+		Context("with FailFast set", func() {
+			BeforeEach(func() {
+				failFast = true
+			})
+
+			It("returns the first validation error", func() {
+				Ω(code).Should(Equal(failFastValCode))
+			})
+		})
+	})
+
+	Describe("FailFastCode", func() {
+		It("returns after each validation error", func() {
+			code := "\tif x {\n\t\terr = goa.AppendError(err, e)\n\t}"
+			Ω(codegen.FailFastCode(code, "nil, err")).Should(Equal("\tif x {\n\t\terr = goa.AppendError(err, e)\n\t\treturn nil, err\n\t}"))
+		})
+	})
 })
 
 const (
 	enumValCode = `	if val != nil {
 		if !(*val == 1 || *val == 2 || *val == 3) {
-			err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`context`" + `, *val, []interface{}{1, 2, 3}))
+			err = goa.AppendError(err, goa.InvalidEnumValueError(` + "`context`" + `, *val, []interface{}{1, 2, 3}))
 		}
 	}`
 
 	patternValCode = `	if val != nil {
 		if ok := goa.ValidatePattern(` + "`.*`" + `, *val); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`context`" + `, *val, ` + "`.*`" + `))
+			err = goa.AppendError(err, goa.InvalidPatternError(` + "`context`" + `, *val, ` + "`.*`" + `))
 		}
 	}`
 
 	minValCode = `	if val != nil {
 		if *val < 0 {
-			err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
+			err = goa.AppendError(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
+		}
+	}`
+
+	exclusiveMinMaxValCode = `	if val != nil {
+		if *val <= 0 {
+			err = goa.AppendError(err, goa.InvalidExclusiveRangeError(` + "`" + `context` + "`" + `, *val, 0, true))
+		}
+	}
+	if val != nil {
+		if *val >= 10 {
+			err = goa.AppendError(err, goa.InvalidExclusiveRangeError(` + "`" + `context` + "`" + `, *val, 10, false))
+		}
+	}`
+
+	timeMinExclusiveMaxValCode = `	if val != nil {
+		if val.Before(time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+			err = goa.AppendError(err, goa.InvalidRangeError(` + "`" + `context` + "`" + `, *val, time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC), true))
+		}
+	}
+	if val != nil {
+		if !val.Before(time.Date(2017, time.January, 1, 12, 30, 0, 0, time.UTC)) {
+			err = goa.AppendError(err, goa.InvalidExclusiveRangeError(` + "`" + `context` + "`" + `, *val, time.Date(2017, time.January, 1, 12, 30, 0, 0, time.UTC), false))
+		}
+	}`
+
+	multipleOfValCode = `	if val != nil {
+		if !goa.ValidateMultipleOf(float64(*val), 0.01) {
+			err = goa.AppendError(err, goa.InvalidMultipleOfError(` + "`" + `context` + "`" + `, *val, 0.01))
+		}
+	}`
+
+	intMultipleOfValCode = `	if val != nil {
+		if *val%10 != 0 {
+			err = goa.AppendError(err, goa.InvalidMultipleOfError(` + "`" + `context` + "`" + `, *val, 10))
 		}
 	}`
 
 	arrayMinLengthValCode = `	if val != nil {
 		if len(val) < 1 {
-			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, val, len(val), 1, true))
+			err = goa.AppendError(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, val, len(val), 1, true))
+		}
+	}`
+
+	arrayUniqueItemsValCode = `	if val != nil {
+		if dup, ok := goa.ValidateUniqueItems(val); !ok {
+			err = goa.AppendError(err, goa.InvalidUniqueItemsError(` + "`" + `context` + "`" + `, dup))
 		}
 	}`
 
 	stringMinLengthValCode = `	if val != nil {
 		if utf8.RuneCountInString(*val) < 2 {
-			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, *val, utf8.RuneCountInString(*val), 2, true))
+			err = goa.AppendError(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, *val, utf8.RuneCountInString(*val), 2, true))
 		}
 	}`
 
 	embeddedValCode = `	if val.Foo != nil {
 		if val.Foo.Bar != nil {
 			if !(*val.Foo.Bar == 1 || *val.Foo.Bar == 2 || *val.Foo.Bar == 3) {
-				err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.Foo.Bar, []interface{}{1, 2, 3}))
+				err = goa.AppendError(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.Foo.Bar, []interface{}{1, 2, 3}))
 			}
 		}
 	}`
 
 	embeddedRequiredValCode = `	if val.Foo == nil {
-		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
+		err = goa.AppendError(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
 	}
 
 	if val.Foo != nil {
 		if val.Foo.Bar != nil {
 			if !(*val.Foo.Bar == 1 || *val.Foo.Bar == 2 || *val.Foo.Bar == 3) {
-				err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.Foo.Bar, []interface{}{1, 2, 3}))
+				err = goa.AppendError(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.Foo.Bar, []interface{}{1, 2, 3}))
 			}
 		}
 	}`
@@ -225,7 +393,7 @@ const (
 	tagCode = `	if val.__tag__ != nil {
 		if val.__tag__.Bar != nil {
 			if !(*val.__tag__.Bar == 1 || *val.__tag__.Bar == 2 || *val.__tag__.Bar == 3) {
-				err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.__tag__.Bar, []interface{}{1, 2, 3}))
+				err = goa.AppendError(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.__tag__.Bar, []interface{}{1, 2, 3}))
 			}
 		}
 	}`
@@ -233,8 +401,20 @@ const (
 	tagChildCode = `	if val.Foo != nil {
 		if val.Foo.__tag__ != nil {
 			if !(*val.Foo.__tag__ == 1 || *val.Foo.__tag__ == 2 || *val.Foo.__tag__ == 3) {
-				err = goa.MergeErrors(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.Foo.__tag__, []interface{}{1, 2, 3}))
+				err = goa.AppendError(err, goa.InvalidEnumValueError(` + "`" + `context.foo.bar` + "`" + `, *val.Foo.__tag__, []interface{}{1, 2, 3}))
 			}
 		}
 	}`
+
+	failFastValCode = `	if val.Foo == nil {
+		err = goa.AppendError(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
+		return err
+	}
+
+	if val.Foo != nil {
+		if utf8.RuneCountInString(*val.Foo) < 2 {
+			err = goa.AppendError(err, goa.InvalidLengthError(` + "`context.foo`" + `, *val.Foo, utf8.RuneCountInString(*val.Foo), 2, true))
+			return err
+		}
+	}`
 )
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
@@ -115,10 +116,12 @@ func (g *Generator) generateContexts() error {
 	if err != nil {
 		panic(err) // bug
 	}
+	ctxWr.Validator.FailFast = g.API.FailFast
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("golang.org/x/net/context"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
@@ -176,6 +179,7 @@ func (g *Generator) generateControllers() error {
 	if err != nil {
 		panic(err) // bug
 	}
+	ctlWr.Validator.FailFast = g.API.FailFast
 	title := fmt.Sprintf("%s: Application Controllers", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("net/http"),
@@ -184,6 +188,7 @@ func (g *Generator) generateControllers() error {
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
 		codegen.SimpleImport("regexp"),
+		codegen.SimpleImport("time"),
 	}
 	encoders, err := BuildEncoders(g.API.Produces, true)
 	if err != nil {
@@ -248,6 +253,12 @@ func (g *Generator) generateControllers() error {
 				"Unmarshal":       unmarshal,
 				"Payload":         a.Payload,
 				"PayloadOptional": a.PayloadOptional,
+				"MaxPayloadBytes": a.MaxPayloadBytes,
+				"Compress":        a.Compress,
+				"Timeout":         durationCode(a.Timeout),
+				"LinkRelations":   a.LinkRelations,
+				"Idempotent":      a.Idempotent,
+				"Coalesce":        a.Coalesce,
 				"Security":        a.Security,
 			}
 			data.Actions = append(data.Actions, action)
@@ -351,6 +362,7 @@ func (g *Generator) generateMediaTypes() error {
 	if err != nil {
 		panic(err) // bug
 	}
+	mtWr.Validator.FailFast = g.API.FailFast
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
@@ -384,9 +396,11 @@ func (g *Generator) generateUserTypes() error {
 	if err != nil {
 		panic(err) // bug
 	}
+	utWr.Validator.FailFast = g.API.FailFast
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
@@ -402,3 +416,26 @@ func (g *Generator) generateUserTypes() error {
 	}
 	return utWr.FormatCode()
 }
+
+// durationCode returns the Go code for the given duration, it returns an empty string if d is
+// not greater than 0.
+func durationCode(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
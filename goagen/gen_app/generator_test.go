@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -221,6 +222,22 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with a timeout", func() {
+			BeforeEach(func() {
+				design.Design.Resources["Widget"].Actions["get"].Timeout = 1500 * time.Millisecond
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("runs the action under the timeout", func() {
+				Ω(genErr).Should(BeNil())
+
+				controllersContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(controllersContent)).Should(ContainSubstring(`"time"`))
+				Ω(string(controllersContent)).Should(ContainSubstring("h = goa.ActionTimeout(1500*time.Millisecond, h)"))
+			})
+		})
+
 		Context("with a optional payload", func() {
 			BeforeEach(func() {
 				elemType := &design.AttributeDefinition{Type: design.Integer}
@@ -282,9 +299,17 @@ func NewGetWidgetContext(ctx context.Context, service *goa.Service) (*GetWidgetC
 	resp.Service = service
 	req := goa.ContextRequest(ctx)
 	rctx := GetWidgetContext{Context: ctx, ResponseData: resp, RequestData: req}
+	if service.StrictNegotiation {
+		if _, err := req.NegotiateMediaType("application/vnd.rightscale.codegen.test.widgets"); err != nil {
+			return nil, err
+		}
+	}
 	paramID := req.Params["id"]
 	if len(paramID) > 0 {
-		rawID := paramID[0]
+		rawID, err2 := service.ScalarParam("id", paramID)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
 		rctx.ID = rawID
 	}
 	return &rctx, err
@@ -350,6 +375,28 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("Get", h, nil))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
+
+// WidgetControllerFactory builds the Widget controller that handles a request.
+type WidgetControllerFactory func(context.Context) WidgetController
+
+// MountWidgetControllerFactory "mounts" a Widget resource controller on the given
+// service. factory is invoked for each request so that a distinct controller carrying request
+// specific dependencies handles it. ctrl provides the request mux handlers.
+func MountWidgetControllerFactory(service *goa.Service, ctrl *goa.Controller, factory WidgetControllerFactory) {
+	MountWidgetController(service, &widgetFactoryController{Controller: ctrl, factory: factory})
+}
+
+// widgetFactoryController implements WidgetController by delegating each
+// request to a controller built by a factory.
+type widgetFactoryController struct {
+	*goa.Controller
+	factory WidgetControllerFactory
+}
+
+// Get builds a controller for the request and runs its Get action.
+func (c *widgetFactoryController) Get(ctx *GetWidgetContext) error {
+	return c.factory(ctx).Get(ctx)
+}
 `
 
 const hrefsCodeTmpl = `//************************************************************************//
@@ -421,6 +468,28 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
+// WidgetControllerFactory builds the Widget controller that handles a request.
+type WidgetControllerFactory func(context.Context) WidgetController
+
+// MountWidgetControllerFactory "mounts" a Widget resource controller on the given
+// service. factory is invoked for each request so that a distinct controller carrying request
+// specific dependencies handles it. ctrl provides the request mux handlers.
+func MountWidgetControllerFactory(service *goa.Service, ctrl *goa.Controller, factory WidgetControllerFactory) {
+	MountWidgetController(service, &widgetFactoryController{Controller: ctrl, factory: factory})
+}
+
+// widgetFactoryController implements WidgetController by delegating each
+// request to a controller built by a factory.
+type widgetFactoryController struct {
+	*goa.Controller
+	factory WidgetControllerFactory
+}
+
+// Get builds a controller for the request and runs its Get action.
+func (c *widgetFactoryController) Get(ctx *GetWidgetContext) error {
+	return c.factory(ctx).Get(ctx)
+}
+
 // unmarshalGetWidgetPayload unmarshals the request body into the context request data Payload field.
 func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *http.Request) error {
 	var payload Collection
@@ -458,6 +527,28 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
 
+// WidgetControllerFactory builds the Widget controller that handles a request.
+type WidgetControllerFactory func(context.Context) WidgetController
+
+// MountWidgetControllerFactory "mounts" a Widget resource controller on the given
+// service. factory is invoked for each request so that a distinct controller carrying request
+// specific dependencies handles it. ctrl provides the request mux handlers.
+func MountWidgetControllerFactory(service *goa.Service, ctrl *goa.Controller, factory WidgetControllerFactory) {
+	MountWidgetController(service, &widgetFactoryController{Controller: ctrl, factory: factory})
+}
+
+// widgetFactoryController implements WidgetController by delegating each
+// request to a controller built by a factory.
+type widgetFactoryController struct {
+	*goa.Controller
+	factory WidgetControllerFactory
+}
+
+// Get builds a controller for the request and runs its Get action.
+func (c *widgetFactoryController) Get(ctx *GetWidgetContext) error {
+	return c.factory(ctx).Get(ctx)
+}
+
 // unmarshalGetWidgetPayload unmarshals the request body into the context request data Payload field.
 func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *http.Request) error {
 	var payload Collection
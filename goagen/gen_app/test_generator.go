@@ -37,6 +37,7 @@ type TestMethod struct {
 	RouteVerb      string
 	FullPath       string
 	Status         int
+	StatusRange    *design.StatusRange
 	ReturnType     *ObjectType
 	Params         []*ObjectType
 	QueryParams    []*ObjectType
@@ -210,6 +211,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		ContextType:    fmt.Sprintf("%s.New%s%sContext", g.Target, actionName, ctrlName),
 		RouteVerb:      route.Verb,
 		Status:         response.Status,
+		StatusRange:    response.StatusRange,
 		FullPath:       goPathFormat(route.FullPath()),
 	}
 }
@@ -265,7 +267,7 @@ func isSlice(typeName string) bool {
 }
 
 var convertParamTmpl = `{{ if eq .Type "string" }}		sliceVal := []string{ {{ if .Pointer }}*{{ end }}{{ .Name }}}{{/*
-*/}}{{ else if eq .Type "int" }}		sliceVal := []string{strconv.Itoa({{ if .Pointer }}*{{ end }}{{ .Name }})}{{/*
+*/}}{{ else if eq .Type "int64" }}		sliceVal := []string{strconv.FormatInt({{ if .Pointer }}*{{ end }}{{ .Name }}, 10)}{{/*
 */}}{{ else if eq .Type "[]string" }}		sliceVal := {{ .Name }}{{/*
 */}}{{ else if (isSlice .Type) }}		sliceVal := make([]string, len({{ .Name }}))
 		for i, v := range {{ .Name }} {
@@ -308,7 +310,7 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 		if !ok {
 			panic(err) // bug
 		}
-		if e.ResponseStatus() != {{ $test.Status }} {
+		if {{ if $test.StatusRange }}e.ResponseStatus() < {{ $test.StatusRange.Min }} || e.ResponseStatus() > {{ $test.StatusRange.Max }}{{ else }}e.ResponseStatus() != {{ $test.Status }}{{ end }} {
 			t.Errorf("unexpected payload validation error: %+v", e)
 		}
 		{{ if $test.ReturnType }}return nil, {{ if eq $test.Status 400 }}e{{ else }}nil{{ end }}{{ else }}return nil{{ end }}
@@ -352,8 +354,8 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	if err != nil {
 		t.Fatalf("controller returned %s, logs:\n%s", err, logBuf.String())
 	}
-	if rw.Code != {{ $test.Status }} {
-		t.Errorf("invalid response status code: got %+v, expected {{ $test.Status }}", rw.Code)
+	if {{ if $test.StatusRange }}rw.Code < {{ $test.StatusRange.Min }} || rw.Code > {{ $test.StatusRange.Max }}{{ else }}rw.Code != {{ $test.Status }}{{ end }} {
+		t.Errorf("invalid response status code: got %+v, expected {{ if $test.StatusRange }}{{ $test.StatusRange }}{{ else }}{{ $test.Status }}{{ end }}", rw.Code)
 	}
 {{ if $test.ReturnType }}	var mt {{ $test.ReturnType.Pointer }}{{ $test.ReturnType.Type }}
 	if resp != nil {
@@ -155,6 +155,22 @@ var _ = Describe("Generate", func() {
 									},
 								},
 							},
+							"list": {
+								Name: "list",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"success": {
+										Name:        "success",
+										Status:      200,
+										StatusRange: &design.StatusRange{Min: 200, Max: 299},
+									},
+								},
+							},
 						},
 					},
 				},
@@ -188,6 +204,15 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("GetFooOK(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.FooController, payload app.CustomName) (http.ResponseWriter, error)"))
 		})
 
+		It("checks the status code against the response status range", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("if rw.Code < 200 || rw.Code > 299 {"))
+			Ω(content).Should(ContainSubstring(`expected 200-299", rw.Code)`))
+			Ω(content).Should(ContainSubstring("if rw.Code != 200 {"))
+		})
+
 		It("generates the route path parameters", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
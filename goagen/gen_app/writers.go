@@ -1,6 +1,7 @@
 package genapp
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -190,6 +191,188 @@ func (c *ContextTemplateData) IterateResponses(it func(*design.ResponseDefinitio
 	return nil
 }
 
+// ResponseMediaTypes returns the content types of the responses that have a media type sorted by
+// response status code. The generated context constructor matches them against the request
+// Accept header.
+func (c *ContextTemplateData) ResponseMediaTypes() []string {
+	var mts []string
+	seen := make(map[string]bool)
+	c.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if resp.Type == nil && resp.MediaType == "" {
+			return nil
+		}
+		if ct := responseContentType(resp); ct != "" && !seen[ct] {
+			seen[ct] = true
+			mts = append(mts, ct)
+		}
+		return nil
+	})
+	return mts
+}
+
+// responseHeadersCode produces the code that checks the headers of a response prior to sending it.
+// Headers with a default value are set to the default if missing, required headers that are
+// missing cause the response helper to return an error naming the header and the action.
+func responseHeadersCode(resp *design.ResponseDefinition, action string) string {
+	if resp.Headers == nil {
+		return ""
+	}
+	headers := resp.Headers.Type.ToObject()
+	names := make([]string, 0, len(headers))
+	for n := range headers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var code string
+	for _, n := range names {
+		name := http.CanonicalHeaderKey(n)
+		att := headers[n]
+		if val := fixedHeaderValue(att); val != "" {
+			code += fmt.Sprintf("\tctx.ResponseData.Header().Set(%q, %q)\n", name, val)
+			continue
+		}
+		if def := att.DefaultValue; def != nil {
+			code += fmt.Sprintf("\tif ctx.ResponseData.Header().Get(%q) == \"\" {\n", name)
+			code += fmt.Sprintf("\t\tctx.ResponseData.Header().Set(%q, %q)\n\t}\n", name, fmt.Sprintf("%v", def))
+		} else if resp.Headers.IsRequired(n) {
+			code += fmt.Sprintf("\tif ctx.ResponseData.Header().Get(%q) == \"\" {\n", name)
+			code += fmt.Sprintf("\t\treturn goa.MissingResponseHeaderError(%q, %q)\n\t}\n", action, name)
+		}
+		if att.Validation != nil && att.Validation.Pattern != "" {
+			code += fmt.Sprintf("\tif h := ctx.ResponseData.Header().Get(%q); h != \"\" && !goa.ValidatePattern(%q, h) {\n", name, att.Validation.Pattern)
+			code += fmt.Sprintf("\t\treturn goa.InvalidResponseHeaderError(%q, %q, h, %q)\n\t}\n", action, name, att.Validation.Pattern)
+		}
+	}
+	return code
+}
+
+// requiredForCode produces the code that checks that the payload includes the attributes that are
+// required for the method of the request being handled, see apidsl.RequiredFor. The generated code
+// uses the private payload data structure whose fields are all pointers.
+func requiredForCode(payload *design.UserTypeDefinition) string {
+	obj := payload.ToObject()
+	if obj == nil {
+		return ""
+	}
+	names := make([]string, 0, len(obj))
+	for n, att := range obj {
+		if len(att.RequiredFor) > 0 && !payload.IsRequired(n) {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	var code string
+	for _, n := range names {
+		att := obj[n]
+		conds := make([]string, len(att.RequiredFor))
+		for i, m := range att.RequiredFor {
+			conds[i] = fmt.Sprintf("req.Method == %q", m)
+		}
+		cond := strings.Join(conds, " || ")
+		if len(conds) > 1 {
+			cond = "(" + cond + ")"
+		}
+		code += fmt.Sprintf("\tif %s && payload.%s == nil {\n", cond, codegen.GoifyAtt(att, n, true))
+		code += fmt.Sprintf("\t\terr = goa.AppendError(err, goa.MissingAttributeError(`raw`, %q))\n\t}\n", n)
+	}
+	return code
+}
+
+// propertiesRuleCode produces the goa.PropertiesRule literal that describes how the additional
+// properties of att and of its child objects are validated, see apidsl.AdditionalProperties. It
+// returns the empty string if the design does not restrict additional properties. Child
+// attributes whose type is a user type are skipped as the user type validates its own additional
+// properties.
+func propertiesRuleCode(att *design.AttributeDefinition, depth int) string {
+	obj := att.Type.ToObject()
+	if obj == nil {
+		return ""
+	}
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var children []string
+	for _, n := range names {
+		child := obj[n]
+		if arr := child.Type.ToArray(); arr != nil && !isExplicitRule(child) {
+			child = arr.ElemType
+		}
+		switch child.Type.(type) {
+		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+			if !isExplicitRule(child) {
+				continue
+			}
+		}
+		if code := propertiesRuleCode(child, depth+2); code != "" {
+			children = append(children, fmt.Sprintf("%s%q: %s,", codegen.Tabs(depth+2), n, code))
+		}
+	}
+	typ := additionalPropertiesJSONType(att.AdditionalPropertiesType)
+	reject := att.AdditionalProperties == design.AdditionalPropertiesReject
+	if !reject && typ == "" && len(children) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	tabs := codegen.Tabs(depth + 1)
+	code := "&goa.PropertiesRule{\n"
+	code += fmt.Sprintf("%sKnown: []string{%s},\n", tabs, strings.Join(quoted, ", "))
+	if reject {
+		code += fmt.Sprintf("%sReject: true,\n", tabs)
+	} else if typ != "" {
+		code += fmt.Sprintf("%sType: %q,\n", tabs, typ)
+	}
+	if len(children) > 0 {
+		code += fmt.Sprintf("%sAttributes: map[string]*goa.PropertiesRule{\n", tabs)
+		code += strings.Join(children, "\n") + "\n"
+		code += fmt.Sprintf("%s},\n", tabs)
+	}
+	return code + codegen.Tabs(depth) + "}"
+}
+
+// isExplicitRule returns true if the design of att defines how its additional properties are
+// handled.
+func isExplicitRule(att *design.AttributeDefinition) bool {
+	return att.AdditionalProperties != design.AdditionalPropertiesAllow || att.AdditionalPropertiesType != nil
+}
+
+// additionalPropertiesJSONType returns the JSON type of the values of additional properties of
+// type t, the empty string if t is nil or is Any.
+func additionalPropertiesJSONType(t design.DataType) string {
+	switch {
+	case t == nil:
+		return ""
+	case t.IsObject() || t.IsHash():
+		return "object"
+	case t.IsArray():
+		return "array"
+	}
+	switch t.Kind() {
+	case design.BooleanKind:
+		return "boolean"
+	case design.IntegerKind:
+		return "integer"
+	case design.NumberKind:
+		return "number"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind, design.DecimalKind:
+		return "string"
+	}
+	return ""
+}
+
+// fixedHeaderValue returns the value of a response header whose design only allows a single
+// value, the empty string if the header value is not fixed.
+func fixedHeaderValue(att *design.AttributeDefinition) string {
+	if att.Validation == nil || len(att.Validation.Values) != 1 {
+		return ""
+	}
+	return fmt.Sprintf("%v", att.Validation.Values[0])
+}
+
 // NewContextsWriter returns a contexts code writer.
 // Contexts provide the glue between the underlying request data and the user controller.
 func NewContextsWriter(filename string) (*ContextsWriter, error) {
@@ -213,8 +396,10 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		"newCoerceData":      newCoerceData,
 		"arrayAttribute":     arrayAttribute,
 		"canonicalHeaderKey": http.CanonicalHeaderKey,
+		"responseHeaders":    responseHeadersCode,
+		"defaultParam":       defaultParamCode,
 	}
-	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
+	if err := w.executeNew(fn, data); err != nil {
 		return err
 	}
 	if data.Payload != nil {
@@ -229,6 +414,7 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
+				"propertiesRule": propertiesRuleCode,
 			}
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
@@ -240,13 +426,19 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			"Context":  data,
 			"Response": resp,
 		}
+		if resp.Status == http.StatusNotFound {
+			respData["ContentType"] = responseContentType(resp)
+			if err := w.ExecuteTemplate("notFound", ctxRespondNotFoundT, fn, respData); err != nil {
+				return err
+			}
+		}
 		var mt *design.MediaTypeDefinition
 		if resp.Type != nil {
 			var ok bool
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
-				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
+				return w.ExecuteTemplate("response", ctxTRespT, fn, respData)
 			}
 		} else {
 			mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
@@ -285,10 +477,44 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			}
 			return nil
 		}
-		return w.ExecuteTemplate("response", ctxNoMTRespT, nil, respData)
+		return w.ExecuteTemplate("response", ctxNoMTRespT, fn, respData)
 	})
 }
 
+// responseContentType returns the content type of the given response: the content type of its
+// media type if it has one or the response media type identifier otherwise.
+func responseContentType(resp *design.ResponseDefinition) string {
+	mt, ok := resp.Type.(*design.MediaTypeDefinition)
+	if !ok && resp.Type == nil {
+		mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
+	}
+	if mt != nil && mt.ContentType != "" {
+		return mt.ContentType
+	}
+	if mt != nil {
+		return mt.Identifier
+	}
+	return resp.MediaType
+}
+
+// executeNew writes the context constructor. The constructor returns the first error found in the
+// request parameters and headers if the validator is configured to fail fast.
+func (w *ContextsWriter) executeNew(fn template.FuncMap, data *ContextTemplateData) error {
+	if !w.Validator.FailFast {
+		return w.ExecuteTemplate("new", ctxNewT, fn, data)
+	}
+	tmpl, err := template.New("new").Funcs(codegen.DefaultFuncMap).Funcs(fn).Parse(ctxNewT)
+	if err != nil {
+		panic(err) // bug
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte(codegen.FailFastCode(buf.String(), "nil, err")))
+	return err
+}
+
 // NewControllersWriter returns a handlers code writer.
 // Handlers provide the glue between the underlying request data and the user controller.
 func NewControllersWriter(filename string) (*ControllersWriter, error) {
@@ -309,6 +535,7 @@ func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTempla
 		"API":      design.Design,
 		"Encoders": encoders,
 		"Decoders": decoders,
+		"Patterns": validationPatterns(design.Design),
 	}
 	if err := w.ExecuteTemplate("service", serviceT, nil, ctx); err != nil {
 		return err
@@ -316,6 +543,44 @@ func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTempla
 	return nil
 }
 
+// validationPatterns returns the sorted list of distinct regular expressions used by the pattern
+// validations of the API types, media types and action parameters, headers and payloads.
+func validationPatterns(api *design.APIDefinition) []string {
+	if api == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var patterns []string
+	collect := func(att *design.AttributeDefinition) error {
+		if att.Validation != nil && att.Validation.Pattern != "" && !seen[att.Validation.Pattern] {
+			seen[att.Validation.Pattern] = true
+			patterns = append(patterns, att.Validation.Pattern)
+		}
+		return nil
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		return ut.Walk(collect)
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		return mt.Walk(collect)
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			for _, att := range []*design.AttributeDefinition{a.Params, a.Headers} {
+				if att != nil {
+					att.Walk(collect)
+				}
+			}
+			if a.Payload != nil {
+				a.Payload.Walk(collect)
+			}
+			return nil
+		})
+	})
+	sort.Strings(patterns)
+	return patterns
+}
+
 // Execute writes the handlers GoGenerator
 func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 	if len(data) == 0 {
@@ -328,14 +593,18 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
 			return err
 		}
+		if err := w.ExecuteTemplate("mountFactory", mountFactoryT, nil, d); err != nil {
+			return err
+		}
 		if len(d.Origins) > 0 {
 			if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, d); err != nil {
 				return err
 			}
 		}
 		fn := template.FuncMap{
-			"finalizeCode":   w.Finalizer.Code,
-			"validationCode": w.Validator.Code,
+			"finalizeCode":    w.Finalizer.Code,
+			"validationCode":  w.Validator.Code,
+			"requiredForCode": requiredForCode,
 		}
 		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
 			return err
@@ -413,9 +682,27 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 			return err
 		}
 	}
+	if mt.IsObject() {
+		fn = template.FuncMap{"viewMembers": viewMembers}
+		if err := w.ExecuteTemplate("mediatypeviews", mediaTypeViewsT, fn, mt); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// viewMembers returns the quoted names of the members rendered by the given view sorted
+// alphabetically.
+func viewMembers(view *design.ViewDefinition) string {
+	obj := view.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, fmt.Sprintf("%q", n))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
 // NewUserTypesWriter returns a contexts code writer.
 // User types contain custom data structured defined in the DSL with "Type".
 func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
@@ -435,6 +722,7 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
+		"propertiesRule": propertiesRuleCode,
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
@@ -451,6 +739,21 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 	}
 }
 
+// defaultParamCode returns the code of the raw values of the parameter att when it is absent from
+// the request, that is its default value formatted as it would appear in the request. Going
+// through the raw values makes the default go through the same coercion as the request values.
+func defaultParamCode(att *design.AttributeDefinition) string {
+	var raw []string
+	if vals, ok := att.DefaultValue.([]interface{}); ok {
+		for _, v := range vals {
+			raw = append(raw, fmt.Sprintf("%v", v))
+		}
+	} else {
+		raw = []string{fmt.Sprintf("%v", att.DefaultValue)}
+	}
+	return fmt.Sprintf("%#v", raw)
+}
+
 // arrayAttribute returns the array element attribute definition.
 func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
 	return a.Type.(*design.Array).ElemType
@@ -478,23 +781,23 @@ type {{ .Name }} struct {
 
 */}}{{/* BooleanType */}}{{/*
 */}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseBool(raw{{ goify .Name true }}); err2 == nil {
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := service.ParseBool(raw{{ goify .Name true }}); err2 == nil {
 {{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "boolean"))
+{{ tabs .Depth }}	err = goa.AppendError(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "boolean"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 2 }}{{/*
 
 */}}{{/* IntegerType */}}{{/*
 */}}{{ $tmp := tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseInt(raw{{ goify .Name true }}, 10, 64); err2 == nil {
 {{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
 {{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
 {{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
 {{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
 {{ end }}{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
+{{ tabs .Depth }}	err = goa.AppendError(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 3 }}{{/*
 
@@ -504,7 +807,7 @@ type {{ .Name }} struct {
 {{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
+{{ tabs .Depth }}	err = goa.AppendError(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 4 }}{{/*
 
@@ -514,11 +817,11 @@ type {{ .Name }} struct {
 
 */}}{{/* DateTimeType */}}{{/*
 */}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := time.Parse(time.RFC3339, raw{{ goify .Name true }}); err2 == nil {
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := {{ if .Attribute.TimeUnit }}goa.ParseTime(raw{{ goify .Name true }}, {{ printf "%q" .Attribute.TimeFormat }}, {{ printf "%q" .Attribute.TimeUnit }}){{ else }}time.Parse({{ if .Attribute.TimeFormat }}{{ printf "%q" .Attribute.TimeFormat }}{{ else }}time.RFC3339{{ end }}, raw{{ goify .Name true }}){{ end }}; err2 == nil {
 {{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "datetime"))
+{{ tabs .Depth }}	err = goa.AppendError(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "datetime"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 6 }}{{/*
 
@@ -528,7 +831,17 @@ type {{ .Name }} struct {
 {{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "uuid"))
+{{ tabs .Depth }}	err = goa.AppendError(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "uuid"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 14 }}{{/*
+
+*/}}{{/* DecimalType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := goa.ParseDecimal(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.AppendError(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "decimal"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 7 }}{{/*
 
@@ -550,9 +863,14 @@ func New{{ .Name }}(ctx context.Context, service *goa.Service) (*{{ .Name }}, er
 	req := goa.ContextRequest(ctx)
 	rctx := {{ .Name }}{Context: ctx, ResponseData: resp, RequestData: req}{{/*
 */}}
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
+{{ $mediaTypes := .ResponseMediaTypes }}{{ if $mediaTypes }}	if service.StrictNegotiation {
+		if _, err := req.NegotiateMediaType({{ range $i, $mt := $mediaTypes }}{{ if $i }}, {{ end }}{{ printf "%q" $mt }}{{ end }}); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
 {{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $name true }}) == 0 {
-		err = goa.MergeErrors(err, goa.MissingHeaderError("{{ $name }}"))
+		err = goa.AppendError(err, goa.MissingHeaderError("{{ $name }}"))
 	} else {
 {{ else }}	if len(header{{ goify $name true }}) > 0 {
 {{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}		req.Params["{{ $name }}"] = header{{ goify $name true }}
@@ -571,8 +889,12 @@ func New{{ .Name }}(ctx context.Context, service *goa.Service) (*{{ .Name }}, er
 {{ end }}{{ end }}{{/* if .Headers }}{{/*
 
 */}}{{ if.Params }}{{ range $name, $att := .Params.Type.ToObject }}	param{{ goify $name true }} := req.Params["{{ $name }}"]
-{{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
-		err = goa.MergeErrors(err, goa.MissingParamError("{{ $name }}"))
+{{ if and $att.Type.IsArray $att.CollectionFormat (ne $att.CollectionFormat "multi") }}	param{{ goify $name true }} = goa.SplitCollectionParam(param{{ goify $name true }}, "{{ $att.CollectionFormat }}")
+{{ end }}{{ if ne $att.DefaultValue nil }}	if len(param{{ goify $name true }}) == 0 {
+		param{{ goify $name true }} = {{ defaultParam $att }}
+	}
+{{ end }}{{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
+		err = goa.AppendError(err, goa.MissingParamError("{{ $name }}"))
 	} else {
 {{ else }}	if len(param{{ goify $name true }}) > 0 {
 {{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if eq (arrayAttribute $att).Type.Kind 4 }}		params := param{{ goify $name true }}
@@ -581,7 +903,10 @@ func New{{ .Name }}(ctx context.Context, service *goa.Service) (*{{ .Name }}, er
 {{ template "Coerce" (newCoerceData $name (arrayAttribute $att) ($.Params.IsPrimitivePointer $name) "params[i]" 3) }}{{/*
 */}}		}
 {{ end }}		{{ printf "rctx.%s" (goifyatt $att $name true) }} = params
-{{ else }}		raw{{ goify $name true}} := param{{ goify $name true}}[0]
+{{ else }}		raw{{ goify $name true}}, err2 := service.ScalarParam("{{ $name }}", param{{ goify $name true}})
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
 {{ template "Coerce" (newCoerceData $name $att ($.Params.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
 */}}{{ $validation := validationChecker $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
 */}}{{ if $validation }}{{ $validation }}
@@ -595,7 +920,7 @@ func New{{ .Name }}(ctx context.Context, service *goa.Service) (*{{ .Name }}, er
 	ctxMTRespT = `// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
 	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
-{{ if .Projected.Type.IsArray }}	if r == nil {
+{{ responseHeaders .Response .Context.ActionName }}{{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
 {{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
@@ -607,7 +932,7 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 	ctxTRespT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .Type nil 0 false }}) error {
 	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
-	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ responseHeaders .Response .Context.ActionName }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
 }
 `
 
@@ -617,11 +942,24 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 // {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.MediaType }}resp []byte{{ end }}) error {
 {{ if .Response.MediaType }}	ctx.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
+{{ end }}{{ responseHeaders .Response .Context.ActionName }}{{ if and (ge .Response.Status 400) (not .Response.MediaType) }}	if ctx.ResponseData.Service.EnvelopeErrors {
+		return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, nil)
+	}
 {{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
 	_, err := ctx.ResponseData.Write(resp)
 	return err{{ else }}
 	return nil{{ end }}
 }
+`
+
+	// ctxRespondNotFoundT generates the generic not found response helper.
+	// template input: map[string]interface{}
+	ctxRespondNotFoundT = `// RespondNotFound sends a HTTP response with status code 404 using the media type of the
+// {{ .Response.Name }} response.
+func (ctx *{{ .Context.Name }}) RespondNotFound(body interface{}) error {
+{{ if .ContentType }}	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, 404, body)
+}
 `
 
 	// payloadT generates the payload type definition GoGenerator
@@ -640,7 +978,13 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Validate() (
 {{ $validation }}
 	return
 }{{ end }}
-{{ $typeName := gotypename .Payload .Payload.AllRequired 1 false }}
+{{ $properties := propertiesRule .Payload.AttributeDefinition 1 }}{{ if $properties }}
+// UnmarshalJSON validates the additional properties of the payload before decoding it.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) UnmarshalJSON(data []byte) error {
+	type alias {{ $privateTypeName }}
+	return goa.DecodeProperties("raw", data, (*alias)(payload), {{ $properties }})
+}
+{{ end }}{{ $typeName := gotypename .Payload .Payload.AllRequired 1 false }}
 // Publicize creates {{ $typeName }} from {{ $privateTypeName }}
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize() {{ gotyperef .Payload .Payload.AllRequired 0 false }} {
 	var pub {{ $typeName }}
@@ -684,7 +1028,12 @@ func initService(service *goa.Service) {
 */}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}}
+{{ end }}{{ end }}{{ if .Patterns }}
+	// Compile the validation patterns
+	if err := goa.CompilePatterns({{ range $i, $p := .Patterns }}{{ if $i }}, {{ end }}{{ printf "%q" $p }}{{ end }}); err != nil {
+		panic(err) // bug: the design validation checks the patterns
+	}
+{{ end }}}
 `
 
 	// mountT generates the code for a resource "Mount" function.
@@ -715,9 +1064,14 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
+{{ if .LinkRelations }}	h = goa.LinkRelations({{ printf "%#v" .LinkRelations }}, h)
+{{ end }}{{ if .Timeout }}	h = goa.ActionTimeout({{ .Timeout }}, h)
+{{ end }}{{ if .Idempotent }}	h = goa.IdempotentAction(h)
+{{ end }}{{ if .Coalesce }}	h = goa.CoalesceAction(h)
+{{ end }}{{ if .Compress }}	h = goa.CompressResponse({{ printf "%q" .Compress }}, h)
+{{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
 {{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.Name }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.Name }}, h, {{ if $action.Payload }}{{ if $action.MaxPayloadBytes }}goa.LimitPayload({{ $action.MaxPayloadBytes }}, {{ $action.Unmarshal }}){{ else }}{{ $action.Unmarshal }}{{ end }}{{ else }}nil{{ end }}))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
@@ -728,6 +1082,33 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}}
 `
 
+	// mountFactoryT generates the code for a resource "MountFactory" function and the adapter
+	// controller that builds a new controller for each request.
+	// template input: *ControllerTemplateData
+	mountFactoryT = `
+// {{ .Resource }}ControllerFactory builds the {{ .Resource }} controller that handles a request.
+type {{ .Resource }}ControllerFactory func(context.Context) {{ .Resource }}Controller
+
+// Mount{{ .Resource }}ControllerFactory "mounts" a {{ .Resource }} resource controller on the given
+// service. factory is invoked for each request so that a distinct controller carrying request
+// specific dependencies handles it. ctrl provides the request mux handlers.
+func Mount{{ .Resource }}ControllerFactory(service *goa.Service, ctrl *goa.Controller, factory {{ .Resource }}ControllerFactory) {
+	Mount{{ .Resource }}Controller(service, &{{ goify .Resource false }}FactoryController{Controller: ctrl, factory: factory})
+}
+
+// {{ goify .Resource false }}FactoryController implements {{ .Resource }}Controller by delegating each
+// request to a controller built by a factory.
+type {{ goify .Resource false }}FactoryController struct {
+	*goa.Controller
+	factory {{ .Resource }}ControllerFactory
+}
+{{ $res := .Resource }}{{ range .Actions }}
+// {{ .Name }} builds a controller for the request and runs its {{ .Name }} action.
+func (c *{{ goify $res false }}FactoryController) {{ .Name }}(ctx *{{ .Context }}) error {
+	return c.factory(ctx).{{ .Name }}(ctx)
+}
+{{ end }}`
+
 	// handleCORST generates the code that checks whether a CORS request is authorized
 	// template input: *ControllerTemplateData
 	handleCORST = `// handle{{ .Resource }}Origin applies the CORS response headers corresponding to the origin.
@@ -777,6 +1158,12 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
 		return err
+	}{{ end }}{{ $requiredFor := requiredForCode .Payload }}{{ if $requiredFor }}
+	var err error
+{{ $requiredFor }}	if err != nil {
+		// Initialize payload with private data structure so it can be logged
+		goa.ContextRequest(ctx).Payload = payload
+		return err
 	}{{ end }}
 	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
 	return nil
@@ -807,6 +1194,16 @@ func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 	return
 }
 {{ end }}
+`
+
+	// mediaTypeViewsT generates the members rendered by each view of a media type.
+	// template input: *design.MediaTypeDefinition
+	mediaTypeViewsT = `{{ $typeName := gotypename . nil 0 false }}// {{ $typeName }}Views lists the members rendered by each view of the {{ .Identifier }} media type,
+// see goa.RenderView.
+var {{ $typeName }}Views = map[string]goa.View{
+{{ range $name, $view := .Views }}	"{{ $name }}": {{ "{" }}{{ viewMembers $view }}{{ "}" }},
+{{ end }}}
+
 `
 
 	// mediaTypeLinkT generates the code for a media type link.
@@ -833,7 +1230,14 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
-{{ $typeName := gotypename . .AllRequired 0 false }}
+{{ $properties := propertiesRule .AttributeDefinition 1 }}{{ if $properties }}
+// UnmarshalJSON validates the additional properties of the {{$privateTypeName}} type instance before
+// decoding it.
+func (ut {{ gotyperef . .AllRequired 0 true }}) UnmarshalJSON(data []byte) error {
+	type alias {{ $privateTypeName }}
+	return goa.DecodeProperties({{ printf "%q" .TypeName }}, data, (*alias)(ut), {{ $properties }})
+}
+{{ end }}{{ $typeName := gotypename . .AllRequired 0 false }}
 // Publicize creates {{ $typeName }} from {{ $privateTypeName }}
 func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllRequired 0 false }} {
 	var pub {{ gotypename . .AllRequired 0 false }}
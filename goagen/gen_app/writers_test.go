@@ -131,6 +131,100 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a not found response", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"msg": {Type: design.String}},
+							},
+						},
+						Identifier: "application/vnd.goa.test.error",
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": {
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK":       {Name: "OK", Status: 200, MediaType: "text/plain"},
+						"NotFound": {Name: "NotFound", Status: 404, MediaType: mediaType.Identifier},
+					}
+				})
+
+				It("generates RespondNotFound using the not found response media type", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(respondNotFoundCode))
+				})
+
+				It("generates the Accept header negotiation", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(negotiateCode))
+				})
+			})
+
+			Context("with a response defining headers", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: "text/plain",
+						Headers: &design.AttributeDefinition{
+							Type: design.Object{
+								"X-Request-Id": {Type: design.String},
+								"X-Version":    {Type: design.String, DefaultValue: "v1"},
+								"X-Api":        {Type: design.String, Validation: &dslengine.ValidationDefinition{Values: []interface{}{"bottles"}}},
+								"X-Trace":      {Type: design.String, Validation: &dslengine.ValidationDefinition{Pattern: "^[a-f0-9`]+\\d*$"}},
+							},
+							Validation: &dslengine.ValidationDefinition{Required: []string{"X-Request-Id"}},
+						},
+					}}
+				})
+
+				It("the generated code checks the required headers and sets the default and fixed ones", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(responseHeadersCode))
+				})
+			})
+
+			Context("with a bodyless error response", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK":       {Name: "OK", Status: 200},
+						"NotFound": {Name: "NotFound", Status: 404},
+					}
+				})
+
+				It("the generated code lets the service envelope the error", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(bodylessErrorCode))
+					Ω(written).Should(ContainSubstring(bodylessOKCode))
+				})
+			})
+
 			Context("with a collection media type", func() {
 				BeforeEach(func() {
 					elemType := &design.MediaTypeDefinition{
@@ -198,6 +292,65 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a page limit param", func() {
+				BeforeEach(func() {
+					min, max := 1.0, 100.0
+					limitParam := &design.AttributeDefinition{
+						Type:         design.Integer,
+						DefaultValue: 20,
+						Validation:   &dslengine.ValidationDefinition{Minimum: &min, Maximum: &max},
+					}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"maxResults": limitParam},
+					}
+				})
+
+				It("applies the default and checks the maximum", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(pageLimitContextFactory))
+				})
+			})
+
+			Context("with a date time param with a time format", func() {
+				BeforeEach(func() {
+					dateParam := &design.AttributeDefinition{Type: design.DateTime, TimeFormat: "2006-01-02"}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"param": dateParam},
+					}
+				})
+
+				It("parses the param using the time format", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`if param, err2 := time.Parse("2006-01-02", rawParam); err2 == nil {`))
+				})
+			})
+
+			Context("with a date time param with a time unit", func() {
+				BeforeEach(func() {
+					dateParam := &design.AttributeDefinition{Type: design.DateTime, TimeUnit: "milliseconds"}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"param": dateParam},
+					}
+				})
+
+				It("parses the param using the time unit", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`if param, err2 := goa.ParseTime(rawParam, "", "milliseconds"); err2 == nil {`))
+				})
+			})
+
 			Context("with a string param", func() {
 				BeforeEach(func() {
 					strParam := &design.AttributeDefinition{Type: design.String}
@@ -244,6 +397,29 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a decimal param", func() {
+				BeforeEach(func() {
+					decParam := &design.AttributeDefinition{Type: design.Decimal}
+					dataType := design.Object{
+						"param": decParam,
+					}
+					params = &design.AttributeDefinition{
+						Type: dataType,
+					}
+				})
+
+				It("writes the contexts code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(decContext))
+					Ω(written).Should(ContainSubstring(decContextFactory))
+				})
+			})
+
 			Context("with a boolean param", func() {
 				BeforeEach(func() {
 					boolParam := &design.AttributeDefinition{Type: design.Boolean}
@@ -293,6 +469,34 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with an array param using the csv collection format", func() {
+				BeforeEach(func() {
+					str := &design.AttributeDefinition{Type: design.String}
+					arrayParam := &design.AttributeDefinition{
+						Type:             &design.Array{ElemType: str},
+						CollectionFormat: "csv",
+					}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"param": arrayParam},
+					}
+				})
+
+				It("splits the param values", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`	paramParam := req.Params["param"]
+	paramParam = goa.SplitCollectionParam(paramParam, "csv")
+	if len(paramParam) > 0 {
+		params := paramParam
+		rctx.Param = params
+	}
+`))
+				})
+			})
+
 			Context("with an integer array param", func() {
 				BeforeEach(func() {
 					i := &design.AttributeDefinition{Type: design.Integer}
@@ -342,6 +546,27 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with an optional param with a format validation", func() {
+				BeforeEach(func() {
+					sinceParam := &design.AttributeDefinition{
+						Type:       design.String,
+						Validation: &dslengine.ValidationDefinition{Format: "date-time"},
+					}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"since": sinceParam},
+					}
+				})
+
+				It("validates the param only when present", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(optionalFormatContextFactory))
+				})
+			})
+
 			Context("with a required param", func() {
 				BeforeEach(func() {
 					intParam := &design.AttributeDefinition{Type: design.Integer}
@@ -369,6 +594,31 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a required param and fail fast validations", func() {
+				BeforeEach(func() {
+					intParam := &design.AttributeDefinition{Type: design.Integer}
+					params = &design.AttributeDefinition{
+						Type:       design.Object{"int": intParam},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"int"}},
+					}
+				})
+
+				It("returns the first error", func() {
+					writer.Validator.FailFast = true
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`		err = goa.AppendError(err, goa.MissingParamError("int"))
+		return nil, err
+`))
+					Ω(written).Should(ContainSubstring(`			err = goa.AppendError(err, err2)
+			return nil, err
+`))
+				})
+			})
+
 			Context("with a custom name param", func() {
 				BeforeEach(func() {
 					intParam := &design.AttributeDefinition{
@@ -521,6 +771,35 @@ var _ = Describe("ContextsWriter", func() {
 					})
 				})
 			})
+
+			Context("with a payload restricting additional properties", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					settings := &design.AttributeDefinition{
+						Type:                     design.Object{"theme": {Type: design.String}},
+						AdditionalPropertiesType: design.Integer,
+					}
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"name":     {Type: design.String},
+								"settings": settings,
+							},
+							AdditionalProperties: design.AdditionalPropertiesReject,
+						},
+						TypeName: "ListBottlePayload",
+					}
+				})
+
+				It("decodes the payload with the additional properties rule", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadPropertiesCode))
+				})
+			})
 		})
 	})
 })
@@ -555,6 +834,37 @@ var _ = Describe("ControllersWriter", func() {
 			os.Create(filename)
 		})
 
+		Context("with types using pattern validations", func() {
+			BeforeEach(func() {
+				pattern := "^[a-z]+$"
+				design.Design = &design.APIDefinition{
+					Types: map[string]*design.UserTypeDefinition{
+						"Foo": {
+							TypeName: "Foo",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name": &design.AttributeDefinition{
+										Type:       design.String,
+										Validation: &dslengine.ValidationDefinition{Pattern: pattern},
+									},
+								},
+							},
+						},
+					},
+				}
+			})
+
+			It("compiles the patterns when initializing the service", func() {
+				err := writer.WriteInitService(nil, nil)
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				written := string(b)
+				Ω(written).Should(ContainSubstring(`if err := goa.CompilePatterns("^[a-z]+$"); err != nil {
+		panic(err)`))
+			})
+		})
+
 		Context("with file servers", func() {
 			requestPath := "/swagger.json"
 			filePath := "swagger/swagger.json"
@@ -623,6 +933,10 @@ var _ = Describe("ControllersWriter", func() {
 		Context("with data", func() {
 			var actions, verbs, paths, contexts, unmarshals []string
 			var payloads []*design.UserTypeDefinition
+			var maxPayloadBytes int64
+			var compress, timeout string
+			var linkRelations []string
+			var idempotent, coalesce bool
 			var encoders, decoders []*genapp.EncoderTemplateData
 			var origins []*design.CORSDefinition
 
@@ -635,6 +949,12 @@ var _ = Describe("ControllersWriter", func() {
 				contexts = nil
 				unmarshals = nil
 				payloads = nil
+				maxPayloadBytes = 0
+				compress = ""
+				timeout = ""
+				linkRelations = nil
+				idempotent = false
+				coalesce = false
 				encoders = nil
 				decoders = nil
 				origins = nil
@@ -664,9 +984,15 @@ var _ = Describe("ControllersWriter", func() {
 								Verb: verbs[i],
 								Path: paths[i],
 							}},
-						"Context":   contexts[i],
-						"Unmarshal": unmarshal,
-						"Payload":   payload,
+						"Context":         contexts[i],
+						"Unmarshal":       unmarshal,
+						"Payload":         payload,
+						"MaxPayloadBytes": maxPayloadBytes,
+						"Compress":        compress,
+						"Timeout":         timeout,
+						"LinkRelations":   linkRelations,
+						"Idempotent":      idempotent,
+						"Coalesce":        coalesce,
 					}
 				}
 				if len(as) > 0 {
@@ -709,6 +1035,90 @@ var _ = Describe("ControllersWriter", func() {
 					Ω(written).Should(ContainSubstring(simpleController))
 					Ω(written).Should(ContainSubstring(simpleMount))
 				})
+
+				It("writes the controller factory code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(simpleMountFactory))
+				})
+
+				Context("with a compression mode", func() {
+					BeforeEach(func() {
+						compress = "never"
+					})
+
+					It("overrides the service compression settings", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring("\th = goa.CompressResponse(\"never\", h)\n\tservice.Mux.Handle("))
+					})
+				})
+
+				Context("with a timeout", func() {
+					BeforeEach(func() {
+						timeout = "5 * time.Second"
+					})
+
+					It("runs the action under the timeout", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring("\th = goa.ActionTimeout(5 * time.Second, h)\n"))
+					})
+				})
+
+				Context("with an idempotent action", func() {
+					BeforeEach(func() {
+						idempotent = true
+					})
+
+					It("lets the idempotency middleware handle the action", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring("\th = goa.IdempotentAction(h)\n"))
+					})
+				})
+
+				Context("with a coalesced action", func() {
+					BeforeEach(func() {
+						coalesce = true
+					})
+
+					It("lets the coalescing middleware handle the action", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring("\th = goa.CoalesceAction(h)\n"))
+					})
+				})
+
+				Context("with link relations", func() {
+					BeforeEach(func() {
+						linkRelations = []string{"next", "prev"}
+					})
+
+					It("restricts the links added by the action", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring("\th = goa.LinkRelations([]string{\"next\", \"prev\"}, h)\n"))
+					})
+				})
 			})
 
 			Context("with actions that take a payload", func() {
@@ -740,6 +1150,21 @@ var _ = Describe("ControllersWriter", func() {
 					written := string(b)
 					Ω(written).Should(ContainSubstring(payloadNoValidationsObjUnmarshal))
 				})
+
+				Context("with a maximum payload length", func() {
+					BeforeEach(func() {
+						maxPayloadBytes = 1024
+					})
+
+					It("limits the payload length", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`ctrl.MuxHandler("List", h, goa.LimitPayload(1024, unmarshalListBottlePayload))`))
+					})
+				})
 			})
 			Context("with actions that take a payload with a required validation", func() {
 				BeforeEach(func() {
@@ -776,6 +1201,45 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with actions that take a payload with attributes required for some methods", func() {
+				BeforeEach(func() {
+					actions = []string{"List"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+									"name": &design.AttributeDefinition{
+										Type:        design.String,
+										RequiredFor: []string{"POST"},
+									},
+									"vintage": &design.AttributeDefinition{
+										Type:        design.Integer,
+										RequiredFor: []string{"POST", "PUT"},
+									},
+								},
+							},
+						},
+					}
+				})
+
+				It("writes the payload unmarshal function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadRequiredForObjUnmarshal))
+				})
+			})
+
 			Context("with multiple controllers", func() {
 				BeforeEach(func() {
 					actions = []string{"List", "Show"}
@@ -1036,6 +1500,78 @@ var _ = Describe("HrefWriter", func() {
 	})
 })
 
+var _ = Describe("MediaTypesWriter", func() {
+	var writer *genapp.MediaTypesWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("media_types")
+		Ω(err).ShouldNot(HaveOccurred())
+		src := pkg.CreateSourceFile("test.go")
+		filename = src.Abs()
+		writer, err = genapp.NewMediaTypesWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a media type defining views and scoped members", func() {
+		var mediaType *design.MediaTypeDefinition
+
+		BeforeEach(func() {
+			attDef := &design.AttributeDefinition{
+				Type: design.Object{
+					"id":   {Type: design.Integer},
+					"name": {Type: design.String},
+					"status": {
+						Type:     design.String,
+						Metadata: dslengine.MetadataDefinition{"scopes": {"admin"}},
+					},
+				},
+			}
+			mediaType = &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: attDef,
+					TypeName:            "Blogger",
+				},
+				Identifier: "application/vnd.goa.blogger",
+			}
+			mediaType.Views = map[string]*design.ViewDefinition{
+				"default": {
+					AttributeDefinition: attDef,
+					Name:                "default",
+					Parent:              mediaType,
+				},
+				"tiny": {
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"id": {Type: design.Integer}},
+					},
+					Name:   "tiny",
+					Parent: mediaType,
+				},
+			}
+			design.Design = new(design.APIDefinition)
+			design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+		})
+
+		It("writes the members of each view and the scopes tags", func() {
+			err := writer.Execute(mediaType)
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).Should(ContainSubstring(mediaTypeViewsCode))
+			Ω(written).Should(ContainSubstring("`form:\"status,omitempty\" json:\"status,omitempty\" xml:\"status,omitempty\" scopes:\"admin\"`"))
+		})
+	})
+})
+
 const (
 	emptyContext = `
 type ListBottleContext struct {
@@ -1061,7 +1597,7 @@ type ListBottleContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Param *int
+	Param *int64
 }
 `
 
@@ -1074,13 +1610,16 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
-		rawParam := paramParam[0]
-		if param, err2 := strconv.Atoi(rawParam); err2 == nil {
+		rawParam, err2 := service.ScalarParam("param", paramParam)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if param, err2 := strconv.ParseInt(rawParam, 10, 64); err2 == nil {
 			tmp2 := param
 			tmp1 := &tmp2
 			rctx.Param = tmp1
 		} else {
-			err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "integer"))
+			err = goa.AppendError(err, goa.InvalidParamTypeError("param", rawParam, "integer"))
 		}
 	}
 	return &rctx, err
@@ -1105,7 +1644,10 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
-		rawParam := paramParam[0]
+		rawParam, err2 := service.ScalarParam("param", paramParam)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
 		rctx.Param = &rawParam
 	}
 	return &rctx, err
@@ -1153,7 +1695,10 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
-		rawParam := paramParam[0]
+		rawParam, err2 := service.ScalarParam("param", paramParam)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
 		rctx.Param = &rawParam
 	}
 	return &rctx, err
@@ -1178,17 +1723,77 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
-		rawParam := paramParam[0]
+		rawParam, err2 := service.ScalarParam("param", paramParam)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
 		if param, err2 := strconv.ParseFloat(rawParam, 64); err2 == nil {
 			tmp1 := &param
 			rctx.Param = tmp1
 		} else {
-			err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "number"))
+			err = goa.AppendError(err, goa.InvalidParamTypeError("param", rawParam, "number"))
 		}
 	}
 	return &rctx, err
 }
 `
+	pageLimitContextFactory = `
+	paramMaxResults := req.Params["maxResults"]
+	if len(paramMaxResults) == 0 {
+		paramMaxResults = []string{"20"}
+	}
+	if len(paramMaxResults) > 0 {
+		rawMaxResults, err2 := service.ScalarParam("maxResults", paramMaxResults)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if maxResults, err2 := strconv.ParseInt(rawMaxResults, 10, 64); err2 == nil {
+			rctx.MaxResults = maxResults
+		} else {
+			err = goa.AppendError(err, goa.InvalidParamTypeError("maxResults", rawMaxResults, "integer"))
+		}
+			if rctx.MaxResults < 1 {
+			err = goa.AppendError(err, goa.InvalidRangeError(` + "`" + `maxResults` + "`" + `, rctx.MaxResults, 1, true))
+		}
+			if rctx.MaxResults > 100 {
+			err = goa.AppendError(err, goa.InvalidRangeError(` + "`" + `maxResults` + "`" + `, rctx.MaxResults, 100, false))
+		}
+	}
+`
+
+	decContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Param *goa.Decimal
+}
+`
+
+	decContextFactory = `
+func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottleContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
+	paramParam := req.Params["param"]
+	if len(paramParam) > 0 {
+		rawParam, err2 := service.ScalarParam("param", paramParam)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if param, err2 := goa.ParseDecimal(rawParam); err2 == nil {
+			tmp1 := &param
+			rctx.Param = tmp1
+		} else {
+			err = goa.AppendError(err, goa.InvalidParamTypeError("param", rawParam, "decimal"))
+		}
+	}
+	return &rctx, err
+}
+`
+
 	boolContext = `
 type ListBottleContext struct {
 	context.Context
@@ -1207,12 +1812,15 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
-		rawParam := paramParam[0]
-		if param, err2 := strconv.ParseBool(rawParam); err2 == nil {
+		rawParam, err2 := service.ScalarParam("param", paramParam)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if param, err2 := service.ParseBool(rawParam); err2 == nil {
 			tmp1 := &param
 			rctx.Param = tmp1
 		} else {
-			err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "boolean"))
+			err = goa.AppendError(err, goa.InvalidParamTypeError("param", rawParam, "boolean"))
 		}
 	}
 	return &rctx, err
@@ -1249,7 +1857,7 @@ type ListBottleContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Param []int
+	Param []int64
 }
 `
 
@@ -1262,12 +1870,12 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
-		params := make([]int, len(paramParam))
+		params := make([]int64, len(paramParam))
 		for i, rawParam := range paramParam {
-			if param, err2 := strconv.Atoi(rawParam); err2 == nil {
+			if param, err2 := strconv.ParseInt(rawParam, 10, 64); err2 == nil {
 				params[i] = param
 			} else {
-				err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "integer"))
+				err = goa.AppendError(err, goa.InvalidParamTypeError("param", rawParam, "integer"))
 			}
 		}
 		rctx.Param = params
@@ -1281,7 +1889,7 @@ type ListBottleContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Int *int
+	Int *int64
 }
 `
 
@@ -1294,13 +1902,16 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramInt := req.Params["int"]
 	if len(paramInt) > 0 {
-		rawInt := paramInt[0]
-		if int_, err2 := strconv.Atoi(rawInt); err2 == nil {
+		rawInt, err2 := service.ScalarParam("int", paramInt)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if int_, err2 := strconv.ParseInt(rawInt, 10, 64); err2 == nil {
 			tmp2 := int_
 			tmp1 := &tmp2
 			rctx.Int = tmp1
 		} else {
-			err = goa.MergeErrors(err, goa.InvalidParamTypeError("int", rawInt, "integer"))
+			err = goa.AppendError(err, goa.InvalidParamTypeError("int", rawInt, "integer"))
 		}
 	}
 	return &rctx, err
@@ -1312,7 +1923,7 @@ type ListBottleContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Int int
+	Int int64
 }
 `
 
@@ -1325,13 +1936,16 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramInt := req.Params["int"]
 	if len(paramInt) == 0 {
-		err = goa.MergeErrors(err, goa.MissingParamError("int"))
+		err = goa.AppendError(err, goa.MissingParamError("int"))
 	} else {
-		rawInt := paramInt[0]
-		if int_, err2 := strconv.Atoi(rawInt); err2 == nil {
+		rawInt, err2 := service.ScalarParam("int", paramInt)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if int_, err2 := strconv.ParseInt(rawInt, 10, 64); err2 == nil {
 			rctx.Int = int_
 		} else {
-			err = goa.MergeErrors(err, goa.InvalidParamTypeError("int", rawInt, "integer"))
+			err = goa.AppendError(err, goa.InvalidParamTypeError("int", rawInt, "integer"))
 		}
 	}
 	return &rctx, err
@@ -1343,7 +1957,7 @@ type ListBottleContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Custom *int
+	Custom *int64
 }
 `
 
@@ -1356,13 +1970,16 @@ func NewListBottleContext(ctx context.Context, service *goa.Service) (*ListBottl
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramInt := req.Params["int"]
 	if len(paramInt) > 0 {
-		rawInt := paramInt[0]
-		if int_, err2 := strconv.Atoi(rawInt); err2 == nil {
+		rawInt, err2 := service.ScalarParam("int", paramInt)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		if int_, err2 := strconv.ParseInt(rawInt, 10, 64); err2 == nil {
 			tmp2 := int_
 			tmp1 := &tmp2
 			rctx.Custom = tmp1
 		} else {
-			err = goa.MergeErrors(err, goa.InvalidParamTypeError("int", rawInt, "integer"))
+			err = goa.AppendError(err, goa.InvalidParamTypeError("int", rawInt, "integer"))
 		}
 	}
 	return &rctx, err
@@ -1412,6 +2029,29 @@ func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *
 	return nil
 }
 `
+	payloadRequiredForObjUnmarshal = `
+func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	payload := &listBottlePayload{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}
+	var err error
+	if req.Method == "POST" && payload.Name == nil {
+		err = goa.AppendError(err, goa.MissingAttributeError(` + "`" + `raw` + "`" + `, "name"))
+	}
+	if (req.Method == "POST" || req.Method == "PUT") && payload.Vintage == nil {
+		err = goa.AppendError(err, goa.MissingAttributeError(` + "`" + `raw` + "`" + `, "vintage"))
+	}
+	if err != nil {
+		// Initialize payload with private data structure so it can be logged
+		goa.ContextRequest(ctx).Payload = payload
+		return err
+	}
+	goa.ContextRequest(ctx).Payload = payload.Publicize()
+	return nil
+}
+`
+
 	payloadNoValidationsObjUnmarshal = `
 func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
 	payload := &listBottlePayload{}
@@ -1566,6 +2206,79 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("List", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
+`
+
+	optionalFormatContextFactory = `
+	paramSince := req.Params["since"]
+	if len(paramSince) > 0 {
+		rawSince, err2 := service.ScalarParam("since", paramSince)
+		if err2 != nil {
+			err = goa.AppendError(err, err2)
+		}
+		rctx.Since = &rawSince
+		if rctx.Since != nil {
+			if err2 := goa.ValidateFormat(goa.FormatDateTime, *rctx.Since); err2 != nil {
+					err = goa.AppendError(err, goa.InvalidFormatError(` + "`since`" + `, *rctx.Since, goa.FormatDateTime, err2))
+			}
+		}
+	}
+	return &rctx, err
+}
+`
+
+	bodylessErrorCode = `
+// NotFound sends a HTTP response with status code 404.
+func (ctx *ListBottleContext) NotFound() error {
+	if ctx.ResponseData.Service.EnvelopeErrors {
+		return ctx.ResponseData.Service.Send(ctx.Context, 404, nil)
+	}
+	ctx.ResponseData.WriteHeader(404)
+	return nil
+}
+`
+
+	bodylessOKCode = `
+// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK() error {
+	ctx.ResponseData.WriteHeader(200)
+	return nil
+}
+`
+
+	responseHeadersCode = `	ctx.ResponseData.Header().Set("X-Api", "bottles")
+	if ctx.ResponseData.Header().Get("X-Request-Id") == "" {
+		return goa.MissingResponseHeaderError("list", "X-Request-Id")
+	}
+	if h := ctx.ResponseData.Header().Get("X-Trace"); h != "" && !goa.ValidatePattern("^[a-f0-9` + "`" + `]+\\d*$", h) {
+		return goa.InvalidResponseHeaderError("list", "X-Trace", h, "^[a-f0-9` + "`" + `]+\\d*$")
+	}
+	if ctx.ResponseData.Header().Get("X-Version") == "" {
+		ctx.ResponseData.Header().Set("X-Version", "v1")
+	}
+	ctx.ResponseData.WriteHeader(200)`
+
+	simpleMountFactory = `
+// BottlesControllerFactory builds the Bottles controller that handles a request.
+type BottlesControllerFactory func(context.Context) BottlesController
+
+// MountBottlesControllerFactory "mounts" a Bottles resource controller on the given
+// service. factory is invoked for each request so that a distinct controller carrying request
+// specific dependencies handles it. ctrl provides the request mux handlers.
+func MountBottlesControllerFactory(service *goa.Service, ctrl *goa.Controller, factory BottlesControllerFactory) {
+	MountBottlesController(service, &bottlesFactoryController{Controller: ctrl, factory: factory})
+}
+
+// bottlesFactoryController implements BottlesController by delegating each
+// request to a controller built by a factory.
+type bottlesFactoryController struct {
+	*goa.Controller
+	factory BottlesControllerFactory
+}
+
+// List builds a controller for the request and runs its List action.
+func (c *bottlesFactoryController) List(ctx *ListBottleContext) error {
+	return c.factory(ctx).List(ctx)
+}
 `
 
 	multiController = `// BottlesController is the controller interface for the Bottles actions.
@@ -1620,5 +2333,47 @@ type BottlesController interface {
 	noParamHref = `func BottleHref() string {
 	return "/bottles"
 }
+`
+
+	negotiateCode = `	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
+	if service.StrictNegotiation {
+		if _, err := req.NegotiateMediaType("text/plain", "application/vnd.goa.test.error"); err != nil {
+			return nil, err
+		}
+	}
+	return &rctx, err
+`
+
+	respondNotFoundCode = `// RespondNotFound sends a HTTP response with status code 404 using the media type of the
+// NotFound response.
+func (ctx *ListBottleContext) RespondNotFound(body interface{}) error {
+	ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.test.error")
+	return ctx.ResponseData.Service.Send(ctx.Context, 404, body)
+}
 `
 )
+
+const mediaTypeViewsCode = `// BloggerViews lists the members rendered by each view of the application/vnd.goa.blogger media type,
+// see goa.RenderView.
+var BloggerViews = map[string]goa.View{
+	"default": {"id", "name", "status"},
+	"tiny": {"id"},
+}
+`
+
+const payloadPropertiesCode = `
+// UnmarshalJSON validates the additional properties of the payload before decoding it.
+func (payload *listBottlePayload) UnmarshalJSON(data []byte) error {
+	type alias listBottlePayload
+	return goa.DecodeProperties("raw", data, (*alias)(payload), &goa.PropertiesRule{
+		Known: []string{"name", "settings"},
+		Reject: true,
+		Attributes: map[string]*goa.PropertiesRule{
+			"settings": &goa.PropertiesRule{
+				Known: []string{"theme"},
+				Type: "integer",
+			},
+		},
+	})
+}
+`
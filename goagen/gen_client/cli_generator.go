@@ -99,6 +99,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	funcs["defaultRouteParams"] = defaultRouteParams
 	funcs["defaultRouteTemplate"] = defaultRouteTemplate
 	funcs["joinNames"] = joinNames
+	funcs["joinCallArgs"] = g.joinCallArgs
 	funcs["joinFieldNames"] = joinFieldhNames
 	funcs["routes"] = routes
 	funcs["flagType"] = flagType
@@ -303,44 +304,79 @@ func joinFieldhNames(atts ...*design.AttributeDefinition) string {
 func joinNames(useNil bool, atts ...*design.AttributeDefinition) string {
 	var elems []string
 	for _, att := range atts {
-		if att == nil {
-			continue
-		}
-		obj := att.Type.ToObject()
-		var names, optNames []string
+		_, vals := attributeArgs(useNil, att)
+		elems = append(elems, vals...)
+	}
+	return strings.Join(elems, ", ")
+}
 
-		keys := make([]string, len(obj))
-		i := 0
-		for n := range obj {
-			keys[i] = n
-			i++
+// joinCallArgs returns the arguments given to the client method of action by the generated
+// command after the request path and payload.
+func (g *Generator) joinCallArgs(action *design.ActionDefinition) string {
+	if g.QueryStruct && hasQueryParams(action) {
+		return joinQueryStructNames(true, g.Target+"."+queryStructName(action), action.QueryParams, action.Headers)
+	}
+	return joinNames(true, action.QueryParams, action.Headers)
+}
+
+// joinQueryStructNames is the counterpart of joinNames used when the client generator bundles the
+// action query parameters in a single struct. It returns the arguments of the client method call
+// starting with a literal value of the query struct typeName initialized from the command flags.
+func joinQueryStructNames(useNil bool, typeName string, query, headers *design.AttributeDefinition) string {
+	var elems []string
+	if fields, vals := attributeArgs(useNil, query); len(fields) > 0 {
+		inits := make([]string, len(fields))
+		for i, f := range fields {
+			inits[i] = f + ": " + vals[i]
 		}
-		sort.Strings(keys)
+		elems = append(elems, fmt.Sprintf("&%s{%s}", typeName, strings.Join(inits, ", ")))
+	}
+	_, vals := attributeArgs(useNil, headers)
+	elems = append(elems, vals...)
+	return strings.Join(elems, ", ")
+}
 
-		for _, n := range keys {
-			a := obj[n]
-			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
-			if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
-				if useNil {
-					field = flagTypeVal(a, n, field)
-				} else {
-					field = "&" + field
-				}
-			} else if a.Type.IsArray() {
-				field = flagTypeArrayVal(a, field)
-			} else {
-				field = flagRequiredTypeVal(a, field)
-			}
-			if att.IsRequired(n) {
-				names = append(names, field)
+// attributeArgs returns the Go field names and the corresponding command flag expressions for the
+// attributes of att, required attributes first.
+func attributeArgs(useNil bool, att *design.AttributeDefinition) (fields, vals []string) {
+	if att == nil {
+		return nil, nil
+	}
+	obj := att.Type.ToObject()
+	var names, optNames, optFields []string
+
+	keys := make([]string, len(obj))
+	i := 0
+	for n := range obj {
+		keys[i] = n
+		i++
+	}
+	sort.Strings(keys)
+
+	for _, n := range keys {
+		a := obj[n]
+		fieldName := codegen.Goify(n, true)
+		field := fmt.Sprintf("cmd.%s", fieldName)
+		if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
+			if useNil {
+				field = flagTypeVal(a, n, field)
 			} else {
-				optNames = append(optNames, field)
+				field = "&" + field
 			}
+		} else if a.Type.IsArray() {
+			field = flagTypeArrayVal(a, field)
+		} else {
+			field = flagRequiredTypeVal(a, field)
+		}
+		if att.IsRequired(n) {
+			fields = append(fields, fieldName)
+			names = append(names, field)
+		} else {
+			optFields = append(optFields, fieldName)
+			optNames = append(optNames, field)
 		}
-		elems = append(elems, names...)
-		elems = append(elems, optNames...)
 	}
-	return strings.Join(elems, ", ")
+	return append(fields, optFields...), append(names, optNames...)
 }
 
 // resolve non required, non array Param/QueryParam for access via CII flags.
@@ -351,7 +387,7 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 		return `intFlagVal("` + key + `", ` + field + ")"
 	case design.String:
 		return `stringFlagVal("` + key + `", ` + field + ")"
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Decimal, design.Any:
 		return "%s"
 	default:
 		return "&" + field
@@ -364,7 +400,7 @@ func flagTypeVal(a *design.AttributeDefinition, key string, field string) string
 // %s maps to specialTypeResult.Temps
 func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 	switch a.Type {
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Decimal, design.Any:
 		return "*%s"
 	default:
 		return field
@@ -376,7 +412,7 @@ func flagRequiredTypeVal(a *design.AttributeDefinition, field string) string {
 // %s maps to specialTypeResult.Temps
 func flagTypeArrayVal(a *design.AttributeDefinition, field string) string {
 	switch a.Type.ToArray().ElemType.Type {
-	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Any:
+	case design.Number, design.Boolean, design.UUID, design.DateTime, design.Decimal, design.Any:
 		return "%s"
 	}
 	return field
@@ -440,6 +476,8 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "uuidVal"
 				case design.DateTime:
 					typeHandler = "timeVal"
+				case design.Decimal:
+					typeHandler = "decimalVal"
 				case design.Any:
 					typeHandler = "jsonVal"
 				}
@@ -455,6 +493,8 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 					typeHandler = "uuidArray"
 				case design.DateTime:
 					typeHandler = "timeArray"
+				case design.Decimal:
+					typeHandler = "decimalArray"
 				case design.Any:
 					typeHandler = "jsonArray"
 				}
@@ -551,7 +591,7 @@ func signerArgs(sec *design.SecuritySchemeDefinition) string {
 func flagType(att *design.AttributeDefinition) string {
 	switch att.Type.Kind() {
 	case design.IntegerKind:
-		return "Int"
+		return "Int64"
 	case design.NumberKind:
 		return "String"
 	case design.BooleanKind:
@@ -562,6 +602,8 @@ func flagType(att *design.AttributeDefinition) string {
 		return "String"
 	case design.UUIDKind:
 		return "String"
+	case design.DecimalKind:
+		return "String"
 	case design.AnyKind:
 		return "String"
 	case design.ArrayKind:
@@ -685,8 +727,8 @@ func new{{ goify $security.SchemeName true }}Signer({{ signerSignature $security
 const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%s%s" .Name (title .Parent.Name) "Command") true }}	// {{ $cmdName }} is the command line data structure for the {{ .Name }} action of {{ .Parent.Name }}
 	{{ $cmdName }} struct {
 {{ if .Payload }}		Payload string
-		ContentType string
-{{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
+{{ if not .Payload.HasFiles }}		ContentType string
+{{ end }}{{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false }}
 {{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
@@ -718,7 +760,7 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	ws, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
+	*/}}{{ $params := joinCallArgs .Action }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -782,8 +824,8 @@ found:
 const registerTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Action.Name (title .Resource.Name)) true }}// RegisterFlags registers the command flags with the command line.
 func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Client) {
 {{ if .Action.Payload }}	cc.Flags().StringVar(&cmd.Payload, "payload", "", "Request body encoded in JSON")
-	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
-{{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
+{{ if not .Action.Payload.HasFiles }}	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
+{{ end }}{{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
 */}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
 */}}{{ if $pparam.DefaultValue }}{{ printf "%#v" $pparam.DefaultValue }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $pparam.Description }}` + "`" + `)
@@ -818,8 +860,8 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
 	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
-	*/}}{{ if .Action.Payload }}, cmd.ContentType{{ end }})
+	*/}}{{ $params := joinCallArgs .Action }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
+	*/}}{{ if .Action.Payload }}{{ if not .Action.Payload.HasFiles }}, cmd.ContentType{{ end }}{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -867,7 +909,7 @@ Payload example:
 	app.AddCommand(dlc)
 {{ end }}}
 
-func intFlagVal(name string, parsed int) *int {
+func intFlagVal(name string, parsed int64) *int64 {
 	if hasFlag(name) {
 		return &parsed
 	}
@@ -974,6 +1016,29 @@ func uuidArray(ins []string) ([]uuid.UUID, error) {
 	return vals, nil
 }
 
+func decimalVal(val string) (*goa.Decimal, error) {
+	t, err := goa.ParseDecimal(val)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func decimalArray(ins []string) ([]goa.Decimal, error) {
+	if ins == nil {
+		return nil, nil
+	}
+	var vals []goa.Decimal
+	for _, id := range ins {
+		val, err := decimalVal(id)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, *val)
+	}
+	return vals, nil
+}
+
 func float64Val(val string) (*float64, error) {
 	t, err := strconv.ParseFloat(val, 64)
 	if err != nil {
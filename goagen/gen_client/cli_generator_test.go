@@ -201,7 +201,7 @@ var _ = Describe("Generate", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 3))
-			Ω(content).Should(ContainSubstring("var param int"))
+			Ω(content).Should(ContainSubstring("var param int64"))
 			Ω(content).Should(ContainSubstring("var time_ string"))
 			Ω(content).Should(ContainSubstring(".Flags()"))
 			_, err = gexec.Build(filepath.Join(testgenPackagePath, "tool", "testapi-cli"))
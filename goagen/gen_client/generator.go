@@ -27,6 +27,7 @@ type Generator struct {
 	ToolDirName    string                // Name of tool directory where CLI main is generated once
 	Tool           string                // Name of CLI tool
 	NoTool         bool                  // Whether to skip tool generation
+	QueryStruct    bool                  // Whether to bundle action query params in a single struct
 	genfiles       []string
 	encoders       []*genapp.EncoderTemplateData
 	decoders       []*genapp.EncoderTemplateData
@@ -37,7 +38,7 @@ type Generator struct {
 func Generate() (files []string, err error) {
 	var (
 		outDir, target, toolDir, tool, ver string
-		notool                             bool
+		notool, querystruct                bool
 	)
 	dtool := defaultToolName(design.Design)
 
@@ -48,6 +49,7 @@ func Generate() (files []string, err error) {
 	set.StringVar(&tool, "tool", dtool, "")
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&notool, "notool", false, "")
+	set.BoolVar(&querystruct, "querystruct", false, "")
 	set.String("design", "", "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
@@ -60,7 +62,7 @@ func Generate() (files []string, err error) {
 
 	// Now proceed
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, API: design.Design}
+	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, QueryStruct: querystruct, API: design.Design}
 
 	return g.Generate()
 }
@@ -144,6 +146,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"typeName":           typeName,
 			"format":             format,
 			"handleSpecialTypes": handleSpecialTypes,
+			"queryField":         queryField,
+			"queryFieldType":     queryFieldType,
+			"queryFieldTag":      queryFieldTag,
 		}
 		clientPkg, err = codegen.PackagePath(pkgDir)
 		if err != nil {
@@ -296,6 +301,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
 		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("os"),
@@ -305,6 +311,8 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("golang.org/x/net/context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	if err := file.WriteHeader("", g.Target, imports); err != nil {
@@ -397,20 +405,22 @@ func (g *Generator) generateFileServer(file *codegen.SourceFile, fs *design.File
 
 func (g *Generator) generateActionClient(action *design.ActionDefinition, file *codegen.SourceFile, funcs template.FuncMap) error {
 	var (
-		params        []string
-		names         []string
-		queryParams   []*paramData
-		headers       []*paramData
-		signer        string
-		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
-		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
-		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		params          []string
+		names           []string
+		queryParams     []*paramData
+		headers         []*paramData
+		signer          string
+		queryStruct     string
+		clientsTmpl     = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
+		requestsTmpl    = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
+		clientsWSTmpl   = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+		queryStructTmpl = template.Must(template.New("querystruct").Funcs(funcs).Parse(queryStructTmpl))
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
 		names = append(names, "payload")
 	}
-	initParams := func(att *design.AttributeDefinition) []*paramData {
+	initParams := func(att *design.AttributeDefinition, bundle bool) []*paramData {
 		if att == nil {
 			return nil
 		}
@@ -419,6 +429,9 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		var optData []*paramData
 		for n, q := range obj {
 			varName := codegen.Goify(n, false)
+			if bundle {
+				varName = "query." + codegen.Goify(n, true)
+			}
 			param := &paramData{
 				Name:      n,
 				VarName:   varName,
@@ -454,6 +467,11 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		sort.Sort(byParamName(optData))
 
 		// Update closure
+		if bundle {
+			names = append(names, "query")
+			params = append(params, "query *"+queryStruct)
+			return append(pdata, optData...)
+		}
 		for _, p := range pdata {
 			names = append(names, p.VarName)
 			params = append(params, p.VarName+" "+cmdFieldType(p.Attribute.Type, false))
@@ -465,8 +483,11 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 
 		return append(pdata, optData...)
 	}
-	queryParams = initParams(action.QueryParams)
-	headers = initParams(action.Headers)
+	if g.QueryStruct && hasQueryParams(action) {
+		queryStruct = queryStructName(action)
+	}
+	queryParams = initParams(action.QueryParams, queryStruct != "")
+	headers = initParams(action.Headers, false)
 	if action.Security != nil {
 		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
 	}
@@ -476,24 +497,35 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Description     string
 		Routes          []*design.RouteDefinition
 		HasPayload      bool
+		HasFiles        bool
 		Params          string
 		ParamNames      string
 		CanonicalScheme string
 		Signer          string
 		QueryParams     []*paramData
+		QueryStruct     string
 		Headers         []*paramData
+		Retry           bool
 	}{
 		Name:            action.Name,
 		ResourceName:    action.Parent.Name,
 		Description:     action.Description,
 		Routes:          action.Routes,
 		HasPayload:      action.Payload != nil,
+		HasFiles:        action.Payload != nil && action.Payload.HasFiles(),
 		Params:          strings.Join(params, ", "),
 		ParamNames:      strings.Join(names, ", "),
 		CanonicalScheme: action.CanonicalScheme(),
 		Signer:          signer,
 		QueryParams:     queryParams,
+		QueryStruct:     queryStruct,
 		Headers:         headers,
+		Retry:           action.Retryable(),
+	}
+	if queryStruct != "" {
+		if err := queryStructTmpl.Execute(file, data); err != nil {
+			return err
+		}
 	}
 	if action.WebSocket() {
 		return clientsWSTmpl.Execute(file, data)
@@ -592,6 +624,7 @@ func (g *Generator) generateUserTypes(pkgDir string) error {
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
@@ -698,9 +731,9 @@ func cmdFieldTypeString(t design.DataType, point bool) string {
 	if point && !t.IsArray() {
 		pointer = "*"
 	}
-	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
+	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind || t.Kind() == design.DecimalKind {
 		suffix = "string"
-	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
+	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind, design.DecimalKind) {
 		suffix = "[]string"
 	} else {
 		suffix = codegen.GoNativeType(t)
@@ -731,7 +764,7 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 	case design.Primitive:
 		switch actual.Kind() {
 		case design.IntegerKind:
-			return fmt.Sprintf("%s := strconv.Itoa(%s)", target, name)
+			return fmt.Sprintf("%s := strconv.FormatInt(%s, 10)", target, name)
 		case design.BooleanKind:
 			return fmt.Sprintf("%s := strconv.FormatBool(%s)", target, name)
 		case design.NumberKind:
@@ -739,7 +772,12 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 		case design.StringKind:
 			return fmt.Sprintf("%s := %s", target, name)
 		case design.DateTimeKind, design.UUIDKind:
+			if att.TimeFormat != "" {
+				return fmt.Sprintf("%s := %s.Format(%q)", target, strings.Replace(name, "*", "", -1), att.TimeFormat)
+			}
 			return fmt.Sprintf("%s := %s.String()", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
+		case design.DecimalKind:
+			return fmt.Sprintf("%s := %s.String()", target, strings.Replace(name, "*", "", -1))
 		case design.AnyKind:
 			return fmt.Sprintf("%s := fmt.Sprintf(\"%%v\", %s)", target, name)
 		default:
@@ -829,6 +867,37 @@ type paramData struct {
 	CheckNil      bool
 }
 
+// hasQueryParams returns true if the given action defines at least one query parameter.
+func hasQueryParams(action *design.ActionDefinition) bool {
+	return action.QueryParams != nil && len(action.QueryParams.Type.ToObject()) > 0
+}
+
+// queryStructName returns the name of the struct holding the query parameters of the given action
+// when the generator bundles them.
+func queryStructName(action *design.ActionDefinition) string {
+	return codegen.Goify(action.Name+strings.Title(action.Parent.Name), true) + "Query"
+}
+
+// queryField returns the name of the query struct field holding the value of the param.
+func queryField(p *paramData) string {
+	return strings.TrimPrefix(p.VarName, "query.")
+}
+
+// queryFieldTag returns the value of the "param" tag of the query struct field holding the value
+// of the param. The tag includes the collection format of array params so that Service.BindParams
+// splits their values.
+func queryFieldTag(p *paramData) string {
+	if p.IsArray && p.Attribute.CollectionFormat != "" {
+		return p.Name + "," + p.Attribute.CollectionFormat
+	}
+	return p.Name
+}
+
+// queryFieldType returns the Go type of the query struct field holding the value of the param.
+func queryFieldType(p *paramData) string {
+	return cmdFieldType(p.Attribute.Type, p.CheckNil && p.Attribute.Type.IsPrimitive())
+}
+
 type byParamName []*paramData
 
 func (b byParamName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -862,15 +931,22 @@ func {{ $funcName }}({{ pathParams . }}) string {
 }
 {{ end }}`
 
+	queryStructTmpl = `// {{ .QueryStruct }} holds the query string parameters of the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
+type {{ .QueryStruct }} struct {
+{{ range .QueryParams }}	{{ queryField . }} {{ queryFieldType . }} ` + "`" + `json:"{{ .Name }}{{ if .CheckNil }},omitempty{{ end }}" param:"{{ queryFieldTag . }}"` + "`" + `
+{{ end }}}
+
+`
+
 	clientsTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
 */}}{{ if $desc }}{{ multiComment $desc }}{{ else }}{{/*
 */}}// {{ $funcName }} makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ end }}
-func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params}},  {{ .Params }}{{ end }}{{ if .HasPayload }}, contentType string{{ end }}) (*http.Response, error) {
-	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if .HasPayload }}, contentType{{ end }})
+func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params}},  {{ .Params }}{{ end }}{{ if and .HasPayload (not .HasFiles) }}, contentType string{{ end }}) (*http.Response, error) {
+	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload (not .HasFiles) }}, contentType{{ end }})
 	if err != nil {
 		return nil, err
 	}
-	return c.Client.Do(ctx, req)
+	return c.Client.{{ if .Retry }}DoWithRetry{{ else }}Do{{ end }}(ctx, req)
 }
 `
 
@@ -883,7 +959,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
 {{ if .QueryParams }}	values := u.Query()
-{{ range .QueryParams }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
+{{ if .QueryStruct }}	if query != nil {
+{{ end }}{{ range .QueryParams }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}{{/*
 
 // ARRAY
@@ -900,7 +977,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 // STRING
 */}}{{ else }}	values.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}
-{{ end }}{{ end }}	u.RawQuery = values.Encode()
+{{ end }}{{ end }}{{ if .QueryStruct }}	}
+{{ end }}	u.RawQuery = values.Encode()
 {{ end }}	return websocket.Dial(u.String(), "", u.String())
 }
 `
@@ -943,8 +1021,13 @@ func (c * Client) {{ .Name }}(ctx context.Context, {{ if .DirName }}filename, {{
 
 	requestsTmpl = `{{ $funcName := goify (printf "New%s%sRequest" (title .Name) (title .ResourceName)) true }}{{/*
 */}}// {{ $funcName }} create the request corresponding to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
-func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if .HasPayload }}, contentType string{{ end }}) (*http.Request, error) {
-{{ if .HasPayload }}	var body bytes.Buffer
+func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload (not .HasFiles) }}, contentType string{{ end }}) (*http.Request, error) {
+{{ if .HasFiles }}	var body bytes.Buffer
+	contentType, err := goaclient.EncodeMultipart(payload, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode body: %s", err)
+	}
+{{ else if .HasPayload }}	var body bytes.Buffer
 	if contentType == "" {
 		contentType = "*/*" // Use default encoder
 	}
@@ -958,7 +1041,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
 {{ if .QueryParams }}	values := u.Query()
-{{ range .QueryParams }}{{/*
+{{ if .QueryStruct }}	if query != nil {
+{{ end }}{{ range .QueryParams }}{{/*
 
 // ARRAY
 */}}{{ if .IsArray }}		for _, p := range {{ .VarName }} {
@@ -979,7 +1063,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 */}}{{ else }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}	values.Set("{{ .Name }}", {{ .ValueName }})
 {{ if .CheckNil }}	}
-{{ end }}{{ end }}{{ end }}	u.RawQuery = values.Encode()
+{{ end }}{{ end }}{{ end }}{{ if .QueryStruct }}	}
+{{ end }}	u.RawQuery = values.Encode()
 {{ end }}{{ if .HasPayload }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), &body)
 {{ else }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), nil)
 {{ end }}	if err != nil {
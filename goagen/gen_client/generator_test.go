@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_client"
 	"github.com/goadesign/goa/version"
@@ -85,13 +86,71 @@ var _ = Describe("Generate", func() {
 	}
 `))
 			Ω(content).Should(ContainSubstring(`	for _, p := range fieldsBaz {
-		tmp3 := strconv.Itoa(p)
+		tmp3 := strconv.FormatInt(p, 10)
 		values.Add("fields[baz]", tmp3)
 	}
 `))
 		})
 	})
 
+	Context("with the querystruct option", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			os.Args = append(os.Args, "--querystruct")
+			o := design.Object{
+				"name":  &design.AttributeDefinition{Type: design.String},
+				"limit": &design.AttributeDefinition{Type: design.Integer},
+				"tags":  &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}, CollectionFormat: "csv"},
+			}
+			design.Design = &design.APIDefinition{
+				Name: "testapi",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name: "list",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								QueryParams: &design.AttributeDefinition{
+									Type:       o,
+									Validation: &dslengine.ValidationDefinition{Required: []string{"limit"}},
+								},
+							},
+						},
+					},
+				},
+			}
+			res := design.Design.Resources["bottle"]
+			listAct := res.Actions["list"]
+			listAct.Parent = res
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("generates a list method that takes a single query struct with all the fields", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "bottle.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`type ListBottleQuery struct {
+	Limit int64    ` + "`" + `json:"limit" param:"limit"` + "`" + `
+	Name  *string  ` + "`" + `json:"name,omitempty" param:"name"` + "`" + `
+	Tags  []string ` + "`" + `json:"tags,omitempty" param:"tags,csv"` + "`" + `
+}`))
+			Ω(content).Should(ContainSubstring("func (c *Client) ListBottle(ctx context.Context, path string, query *ListBottleQuery) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("req, err := c.NewListBottleRequest(ctx, path, query)"))
+			Ω(content).Should(ContainSubstring("if query != nil {"))
+			Ω(content).Should(ContainSubstring(`values.Set("name", *query.Name)`))
+			Ω(content).Should(ContainSubstring("for _, p := range query.Tags {"))
+			commands, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(commands).Should(ContainSubstring("c.ListBottle(ctx, path, &client.ListBottleQuery{Limit: cmd.Limit, Name: stringFlagVal(\"name\", cmd.Name), Tags: cmd.Tags})"))
+		})
+	})
+
 	Context("with an action with multiple routes", func() {
 		BeforeEach(func() {
 			design.Design = &design.APIDefinition{
@@ -158,6 +217,57 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with safe and unsafe actions", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "testapi",
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+							},
+							"create": {
+								Name:   "create",
+								Routes: []*design.RouteDefinition{{Verb: "POST", Path: ""}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			for _, a := range fooRes.Actions {
+				a.Parent = fooRes
+				a.Routes[0].Parent = a
+			}
+		})
+
+		It("generates retry logic for the GET action only", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(strings.Count(string(content), "return c.Client.DoWithRetry(ctx, req)")).Should(Equal(1))
+			Ω(strings.Count(string(content), "return c.Client.Do(ctx, req)")).Should(Equal(1))
+			show := string(content)[strings.Index(string(content), "func (c *Client) ShowFoo("):]
+			Ω(show[:strings.Index(show, "\n}\n")]).Should(ContainSubstring("DoWithRetry"))
+		})
+
+		Context("with the POST action declared idempotent", func() {
+			BeforeEach(func() {
+				design.Design.Resources["foo"].Actions["create"].Idempotent = true
+			})
+
+			It("generates retry logic for both actions", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(strings.Count(string(content), "return c.Client.DoWithRetry(ctx, req)")).Should(Equal(2))
+			})
+		})
+	})
+
 	Context("with an action with security configured", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -275,4 +385,58 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("uuid \"github.com/goadesign/goa/uuid\""))
 		})
 	})
+
+	Context("with an action with a payload with files", func() {
+		BeforeEach(func() {
+			uploadType := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"name":     &design.AttributeDefinition{Type: design.String},
+						"document": &design.AttributeDefinition{Type: design.File},
+					},
+				},
+				TypeName: "UploadPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Types: map[string]*design.UserTypeDefinition{
+					"UploadPayload": uploadType,
+				},
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"upload": {
+								Name: "upload",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "",
+									},
+								},
+								Payload: uploadType,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			uploadAct := fooRes.Actions["upload"]
+			uploadAct.Parent = fooRes
+			uploadAct.Routes[0].Parent = uploadAct
+		})
+
+		It("sends the payload as a multipart form", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func (c *Client) UploadFoo(ctx context.Context, path string, payload *UploadPayload) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("contentType, err := goaclient.EncodeMultipart(payload, &body)"))
+			types, err := ioutil.ReadFile(filepath.Join(outDir, "client", "user_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(types).Should(ContainSubstring("Document *multipart.FileHeader"))
+		})
+	})
 })
@@ -0,0 +1,7 @@
+/*
+Package genpostman provides a generator for a Postman collection describing the API.
+The collection (https://schema.getpostman.com/json/collection/v2.1.0/collection.json) contains a
+folder per resource and a request per action route. It can be imported in Postman to exercise the
+API manually.
+*/
+package genpostman
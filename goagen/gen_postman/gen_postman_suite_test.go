@@ -0,0 +1,13 @@
+package genpostman_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenPostman(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenPostman Suite")
+}
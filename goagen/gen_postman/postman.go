@@ -0,0 +1,277 @@
+package genpostman
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+)
+
+// SchemaURL is the URL of the JSON schema of the generated collections.
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type (
+	// Collection is a Postman v2.1 collection.
+	Collection struct {
+		Info     *Info       `json:"info"`
+		Item     []*Item     `json:"item"`
+		Variable []*Variable `json:"variable,omitempty"`
+	}
+
+	// Info describes the collection.
+	Info struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version,omitempty"`
+		Schema      string `json:"schema"`
+	}
+
+	// Item is either a folder listing items or a request.
+	Item struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description,omitempty"`
+		Item        []*Item  `json:"item,omitempty"`
+		Request     *Request `json:"request,omitempty"`
+	}
+
+	// Request describes a HTTP request.
+	Request struct {
+		Method      string    `json:"method"`
+		Header      []*Header `json:"header"`
+		URL         *URL      `json:"url"`
+		Body        *Body     `json:"body,omitempty"`
+		Description string    `json:"description,omitempty"`
+	}
+
+	// Header is a request header.
+	Header struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	// URL describes a request URL. Path parameters are written ":name" in Path and listed in
+	// Variable.
+	URL struct {
+		Raw      string      `json:"raw"`
+		Host     []string    `json:"host"`
+		Path     []string    `json:"path"`
+		Query    []*Query    `json:"query,omitempty"`
+		Variable []*Variable `json:"variable,omitempty"`
+	}
+
+	// Query is a query string parameter.
+	Query struct {
+		Key      string `json:"key"`
+		Value    string `json:"value"`
+		Disabled bool   `json:"disabled,omitempty"`
+	}
+
+	// Variable is a collection or path variable.
+	Variable struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
+	// Body is a raw request body.
+	Body struct {
+		Mode string `json:"mode"`
+		Raw  string `json:"raw"`
+	}
+)
+
+// VersionHeader is the name of the header set on each request to the version of the API when the
+// design specifies one.
+const VersionHeader = "X-Api-Version"
+
+const (
+	// baseURLVar is the name of the collection variable holding the API base URL.
+	baseURLVar = "baseUrl"
+	// versionVar is the name of the collection variable holding the API version.
+	versionVar = "version"
+)
+
+// GeneratePostmanCollection returns the JSON representation of the Postman collection describing
+// the given API.
+func GeneratePostmanCollection(api *design.APIDefinition) ([]byte, error) {
+	c, err := New(api)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(c, "", "  ")
+}
+
+// New creates the Postman collection describing the given API. The collection contains a folder
+// per resource and a request per action route. The requests use the "baseUrl" collection
+// variable initialized with the API scheme and host. If the API has a version the requests also
+// set the VersionHeader header to the "version" collection variable.
+func New(api *design.APIDefinition) (*Collection, error) {
+	if api == nil {
+		return nil, nil
+	}
+	c := &Collection{
+		Info: &Info{
+			Name:        api.Name,
+			Description: api.Description,
+			Version:     api.Version,
+			Schema:      SchemaURL,
+		},
+		Item: []*Item{},
+		Variable: []*Variable{
+			{Key: baseURLVar, Value: baseURL(api)},
+		},
+	}
+	if api.Version != "" {
+		c.Variable = append(c.Variable, &Variable{Key: versionVar, Value: api.Version})
+	}
+	err := api.IterateResources(func(r *design.ResourceDefinition) error {
+		folder := &Item{Name: r.Name, Description: r.Description, Item: []*Item{}}
+		err := r.IterateActions(func(a *design.ActionDefinition) error {
+			for i, route := range a.Routes {
+				name := a.Name
+				if len(a.Routes) > 1 {
+					name = fmt.Sprintf("%s (%d)", a.Name, i+1)
+				}
+				folder.Item = append(folder.Item, &Item{
+					Name:    name,
+					Request: newRequest(api, a, route),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		c.Item = append(c.Item, folder)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newRequest builds the Postman request for the given action route.
+func newRequest(api *design.APIDefinition, a *design.ActionDefinition, route *design.RouteDefinition) *Request {
+	req := &Request{
+		Method:      route.Verb,
+		Header:      []*Header{},
+		URL:         newURL(api, a, route),
+		Description: a.Description,
+	}
+	if a.Payload != nil {
+		example := a.Payload.Example
+		if example == nil {
+			example = a.Payload.GenerateExample(api.RandomGenerator(), nil)
+		}
+		if b, err := json.MarshalIndent(example, "", "  "); err == nil {
+			req.Header = append(req.Header, &Header{Key: "Content-Type", Value: "application/json"})
+			req.Body = &Body{Mode: "raw", Raw: string(b)}
+		}
+	}
+	versioned := api.Version != ""
+	a.IterateHeaders(func(name string, required bool, h *design.AttributeDefinition) error {
+		if strings.EqualFold(name, VersionHeader) {
+			versioned = false
+		}
+		req.Header = append(req.Header, &Header{Key: name, Value: exampleValue(api, h)})
+		return nil
+	})
+	if versioned {
+		req.Header = append(req.Header, &Header{Key: VersionHeader, Value: "{{" + versionVar + "}}"})
+	}
+	return req
+}
+
+// newURL builds the URL of the given action route. Path parameters are kept as ":name"
+// placeholders and listed as variables initialized with example values.
+func newURL(api *design.APIDefinition, a *design.ActionDefinition, route *design.RouteDefinition) *URL {
+	path := route.FullPath()
+	var segments []string
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if s == "" {
+			continue
+		}
+		if strings.HasPrefix(s, "*") {
+			s = ":" + s[1:]
+		}
+		segments = append(segments, s)
+	}
+	raw := "{{" + baseURLVar + "}}/" + strings.Join(segments, "/")
+	u := &URL{Host: []string{"{{" + baseURLVar + "}}"}, Path: segments}
+	if u.Path == nil {
+		u.Path = []string{}
+	}
+	params := a.AllParams()
+	for _, name := range route.Params() {
+		var value string
+		if params != nil {
+			if att, ok := params.Type.ToObject()[name]; ok {
+				value = exampleValue(api, att)
+			}
+		}
+		u.Variable = append(u.Variable, &Variable{Key: name, Value: value})
+	}
+	if a.QueryParams != nil {
+		obj := a.QueryParams.Type.ToObject()
+		var query []string
+		for _, name := range sortedKeys(obj) {
+			required := a.QueryParams.IsRequired(name)
+			u.Query = append(u.Query, &Query{
+				Key:      name,
+				Value:    exampleValue(api, obj[name]),
+				Disabled: !required,
+			})
+			if required {
+				query = append(query, name+"="+exampleValue(api, obj[name]))
+			}
+		}
+		if len(query) > 0 {
+			raw += "?" + strings.Join(query, "&")
+		}
+	}
+	u.Raw = raw
+	return u
+}
+
+// baseURL returns the base URL of the API.
+func baseURL(api *design.APIDefinition) string {
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	host := api.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	return scheme + "://" + host
+}
+
+// exampleValue returns the string representation of the example value of att.
+func exampleValue(api *design.APIDefinition, att *design.AttributeDefinition) string {
+	example := att.Example
+	if example == nil && api != nil {
+		example = att.GenerateExample(api.RandomGenerator(), nil)
+	}
+	if example == nil {
+		return ""
+	}
+	if s, ok := example.(string); ok {
+		return s
+	}
+	if b, err := json.Marshal(example); err == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", example)
+}
+
+// sortedKeys returns the names of the object attributes in alphabetical order.
+func sortedKeys(obj design.Object) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
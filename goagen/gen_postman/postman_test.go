@@ -0,0 +1,107 @@
+package genpostman_test
+
+import (
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_postman"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GeneratePostmanCollection", func() {
+	var collection *genpostman.Collection
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("cellar", func() {
+			Version("1.0")
+			Host("cellar.example.com")
+			Scheme("https")
+			BasePath("/cellar")
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer, func() {
+						Example(42)
+					})
+				})
+				Response(OK)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Member("name", String, func() {
+						Example("Number 8")
+					})
+					Required("name")
+				})
+				Response(Created)
+			})
+		})
+		Resource("account", func() {
+			Action("list", func() {
+				Routing(GET("/accounts"))
+				Response(OK)
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		b, err := genpostman.GeneratePostmanCollection(Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		collection = nil
+		Ω(json.Unmarshal(b, &collection)).ShouldNot(HaveOccurred())
+	})
+
+	It("describes the API", func() {
+		Ω(collection.Info.Name).Should(Equal("cellar"))
+		Ω(collection.Info.Version).Should(Equal("1.0"))
+		Ω(collection.Info.Schema).Should(Equal(genpostman.SchemaURL))
+		Ω(collection.Variable).Should(HaveLen(2))
+		Ω(collection.Variable[0].Value).Should(Equal("https://cellar.example.com"))
+		Ω(collection.Variable[1].Key).Should(Equal("version"))
+		Ω(collection.Variable[1].Value).Should(Equal("1.0"))
+	})
+
+	It("sets the version header on each request", func() {
+		for _, folder := range collection.Item {
+			for _, item := range folder.Item {
+				Ω(item.Request.Header).Should(ContainElement(&genpostman.Header{
+					Key:   genpostman.VersionHeader,
+					Value: "{{version}}",
+				}))
+			}
+		}
+	})
+
+	It("contains a folder per resource and a request per action", func() {
+		Ω(collection.Item).Should(HaveLen(2))
+		Ω(collection.Item[0].Name).Should(Equal("account"))
+		Ω(collection.Item[0].Item).Should(HaveLen(1))
+		Ω(collection.Item[1].Name).Should(Equal("bottle"))
+		Ω(collection.Item[1].Item).Should(HaveLen(2))
+
+		create := collection.Item[1].Item[0]
+		Ω(create.Name).Should(Equal("create"))
+		Ω(create.Request.Method).Should(Equal("POST"))
+		Ω(create.Request.URL.Raw).Should(Equal("{{baseUrl}}/cellar/bottles"))
+		Ω(create.Request.Body).ShouldNot(BeNil())
+		Ω(create.Request.Body.Raw).Should(MatchJSON(`{"name":"Number 8"}`))
+
+		show := collection.Item[1].Item[1]
+		Ω(show.Name).Should(Equal("show"))
+		Ω(show.Request.Method).Should(Equal("GET"))
+		Ω(show.Request.URL.Raw).Should(Equal("{{baseUrl}}/cellar/bottles/:id"))
+		Ω(show.Request.URL.Path).Should(Equal([]string{"cellar", "bottles", ":id"}))
+		Ω(show.Request.URL.Variable).Should(HaveLen(1))
+		Ω(show.Request.URL.Variable[0].Key).Should(Equal("id"))
+		Ω(show.Request.URL.Variable[0].Value).Should(Equal("42"))
+	})
+})
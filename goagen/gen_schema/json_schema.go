@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
@@ -41,10 +42,14 @@ type (
 		Pattern              string        `json:"pattern,omitempty"`
 		Minimum              *float64      `json:"minimum,omitempty"`
 		Maximum              *float64      `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool          `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool          `json:"exclusiveMaximum,omitempty"`
+		MultipleOf           *float64      `json:"multipleOf,omitempty"`
 		MinLength            *int          `json:"minLength,omitempty"`
 		MaxLength            *int          `json:"maxLength,omitempty"`
+		UniqueItems          bool          `json:"uniqueItems,omitempty"`
 		Required             []string      `json:"required,omitempty"`
-		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
+		AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
 
 		// Union
 		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
@@ -95,6 +100,9 @@ const (
 // SchemaRef is the JSON Hyper-schema standard href.
 const SchemaRef = "http://json-schema.org/draft-04/hyper-schema"
 
+// DraftSchemaRef is the JSON schema draft 4 standard href used by standalone schemas.
+const DraftSchemaRef = "http://json-schema.org/draft-04/schema#"
+
 var (
 	// Definitions contains the generated JSON schema definitions
 	Definitions map[string]*JSONSchema
@@ -118,7 +126,7 @@ func NewJSONSchema() *JSONSchema {
 // It makes sure the "$schema" standard field is set if needed prior to delegating to the standard
 // JSON marshaler.
 func (s *JSONSchema) JSON() ([]byte, error) {
-	if s.Ref == "" {
+	if s.Ref == "" && s.Schema == "" {
 		s.Schema = SchemaRef
 	}
 	return json.Marshal(s)
@@ -267,6 +275,76 @@ func GenerateMediaTypeDefinition(api *design.APIDefinition, mt *design.MediaType
 	buildMediaTypeSchema(api, mt, view, s)
 }
 
+// MediaTypeSchema produces a standalone JSON schema for the given media type and view that can be
+// handed to third party validators. The schema sets "$schema" to the JSON schema draft 4 and its
+// "id" to the value returned by MediaTypeSchemaID. It embeds the definitions of the types it
+// references directly or indirectly.
+func MediaTypeSchema(api *design.APIDefinition, mt *design.MediaTypeDefinition, view string) *JSONSchema {
+	s := NewJSONSchema()
+	s.Schema = DraftSchemaRef
+	s.ID = MediaTypeSchemaID(api, mt, view)
+	s.Title = fmt.Sprintf("Mediatype identifier: %s", mt.Identifier)
+	buildMediaTypeSchema(api, mt, view, s)
+	addReferencedDefinitions(s, s)
+	return s
+}
+
+// addReferencedDefinitions adds the definitions referenced by s and by the definitions it
+// references to the definitions of root.
+func addReferencedDefinitions(root, s *JSONSchema) {
+	if s == nil {
+		return
+	}
+	if strings.HasPrefix(s.Ref, "#/definitions/") {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		if def, ok := Definitions[name]; ok {
+			if _, ok := root.Definitions[name]; !ok {
+				root.Definitions[name] = def
+				addReferencedDefinitions(root, def)
+			}
+		}
+	}
+	addReferencedDefinitions(root, s.Items)
+	if ap, ok := s.AdditionalProperties.(*JSONSchema); ok {
+		addReferencedDefinitions(root, ap)
+	}
+	for _, p := range s.Properties {
+		addReferencedDefinitions(root, p)
+	}
+	for _, a := range s.AnyOf {
+		addReferencedDefinitions(root, a)
+	}
+	for _, l := range s.Links {
+		addReferencedDefinitions(root, l.Schema)
+		addReferencedDefinitions(root, l.TargetSchema)
+	}
+	if s != root {
+		for _, d := range s.Definitions {
+			addReferencedDefinitions(root, d)
+		}
+	}
+}
+
+// MediaTypeSchemaID returns the stable identifier of the JSON schema of the given media type and
+// view. The identifier is the API schema URL followed by the canonical media type identifier and
+// the view name if not "default", e.g. "http://api.goa.design/schema/application/vnd.bottle/tiny".
+func MediaTypeSchemaID(api *design.APIDefinition, mt *design.MediaTypeDefinition, view string) string {
+	var href string
+	if api.Host != "" {
+		scheme := "http"
+		if len(api.Schemes) > 0 {
+			scheme = api.Schemes[0]
+		}
+		u := url.URL{Scheme: scheme, Host: api.Host}
+		href = u.String()
+	}
+	id := fmt.Sprintf("%s/schema/%s", href, design.CanonicalIdentifier(mt.Identifier))
+	if view != "" && view != "default" {
+		id += "/" + view
+	}
+	return id
+}
+
 // GenerateTypeDefinition produces the JSON schema corresponding to the given type.
 func GenerateTypeDefinition(api *design.APIDefinition, ut *design.UserTypeDefinition) {
 	if _, ok := Definitions[ut.TypeName]; ok {
@@ -289,10 +367,17 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 			s.Format = "uuid"
 		case design.DateTimeKind:
 			s.Format = "date-time"
+		case design.DecimalKind:
+			s.Format = "decimal"
 		case design.NumberKind:
 			s.Format = "double"
 		case design.IntegerKind:
 			s.Format = "int64"
+		case design.FileKind:
+			// "file" is only valid in Swagger formData parameters, files are binary strings in
+			// schemas.
+			s.Type = JSONString
+			s.Format = "binary"
 		}
 	case *design.Array:
 		s.Type = JSONArray
@@ -336,7 +421,7 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Enum, other.Enum, s.Enum == nil},
 		{&s.Format, other.Format, s.Format == ""},
 		{&s.Pattern, other.Pattern, s.Pattern == ""},
-		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == false},
+		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == nil},
 		{
 			a: s.Minimum, b: other.Minimum,
 			needed: (s.Minimum == nil && s.Minimum != nil) ||
@@ -412,8 +497,12 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Pattern:              s.Pattern,
 		Minimum:              s.Minimum,
 		Maximum:              s.Maximum,
+		ExclusiveMinimum:     s.ExclusiveMinimum,
+		ExclusiveMaximum:     s.ExclusiveMaximum,
+		MultipleOf:           s.MultipleOf,
 		MinLength:            s.MinLength,
 		MaxLength:            s.MaxLength,
+		UniqueItems:          s.UniqueItems,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
 	}
@@ -442,6 +531,9 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 		// Ref is exclusive with other fields
 		return s
 	}
+	if ap := additionalPropertiesSchema(api, at); ap != nil {
+		s.AdditionalProperties = ap
+	}
 	s.DefaultValue = toStringMap(at.DefaultValue)
 	s.Description = at.Description
 	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
@@ -454,9 +546,14 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.Pattern = val.Pattern
 	if val.Minimum != nil {
 		s.Minimum = val.Minimum
+		s.ExclusiveMinimum = val.ExclusiveMinimum
 	}
 	if val.Maximum != nil {
 		s.Maximum = val.Maximum
+		s.ExclusiveMaximum = val.ExclusiveMaximum
+	}
+	if val.MultipleOf != nil {
+		s.MultipleOf = val.MultipleOf
 	}
 	if val.MinLength != nil {
 		s.MinLength = val.MinLength
@@ -464,10 +561,24 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	if val.MaxLength != nil {
 		s.MaxLength = val.MaxLength
 	}
+	s.UniqueItems = val.UniqueItems
 	s.Required = val.Required
 	return s
 }
 
+// additionalPropertiesSchema returns the value of the "additionalProperties" field of the schema
+// of att as defined by apidsl.AdditionalProperties: false if additional properties are rejected,
+// the schema of their values if the design gives their type and nil otherwise.
+func additionalPropertiesSchema(api *design.APIDefinition, at *design.AttributeDefinition) interface{} {
+	switch {
+	case at.AdditionalProperties == design.AdditionalPropertiesReject:
+		return false
+	case at.AdditionalPropertiesType != nil:
+		return TypeSchema(api, at.AdditionalPropertiesType)
+	}
+	return nil
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
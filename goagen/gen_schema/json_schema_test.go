@@ -1,6 +1,8 @@
 package genschema_test
 
 import (
+	"encoding/json"
+
 	"github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -78,4 +80,141 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+
+	Context("with a file", func() {
+		BeforeEach(func() {
+			typ = design.File
+		})
+
+		It("returns a binary string schema", func() {
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.Type).Should(BeEquivalentTo("string"))
+			Ω(s.Format).Should(Equal("binary"))
+		})
+	})
+
+	Context("with a decimal", func() {
+		BeforeEach(func() {
+			typ = design.Decimal
+		})
+
+		It("returns a decimal string schema", func() {
+			Ω(s).ShouldNot(BeNil())
+			Ω(s.Type).Should(BeEquivalentTo("string"))
+			Ω(s.Format).Should(Equal("decimal"))
+		})
+	})
+})
+
+var _ = Describe("GenerateTypeDefinition", func() {
+	var s *genschema.JSONSchema
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		Type("Settings", func() {
+			Attribute("theme", design.String)
+			Attribute("labels", func() {
+				AdditionalProperties(design.String)
+			})
+			AdditionalProperties(design.AdditionalPropertiesReject)
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		genschema.GenerateTypeDefinition(design.Design, design.Design.Types["Settings"])
+		s = genschema.Definitions["Settings"]
+	})
+
+	It("describes the additional properties", func() {
+		Ω(s).ShouldNot(BeNil())
+		b, err := json.Marshal(s)
+		Ω(err).ShouldNot(HaveOccurred())
+		var doc map[string]interface{}
+		Ω(json.Unmarshal(b, &doc)).ShouldNot(HaveOccurred())
+		Ω(doc["additionalProperties"]).Should(Equal(false))
+		labels := doc["properties"].(map[string]interface{})["labels"].(map[string]interface{})
+		Ω(labels["additionalProperties"]).Should(Equal(map[string]interface{}{"type": "string"}))
+	})
+})
+
+var _ = Describe("MediaTypeSchema", func() {
+	var mt *design.MediaTypeDefinition
+	var view string
+
+	var s *genschema.JSONSchema
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		view = "default"
+		API("test", func() {
+			Host("api.goa.design")
+			Scheme("https")
+		})
+		winery := Type("Winery", func() {
+			Attribute("name")
+		})
+		Type("Unrelated", func() {
+			Attribute("name")
+		})
+		MediaType("application/vnd.goa.bottle+json", func() {
+			Attributes(func() {
+				Attribute("id", design.Integer)
+				Attribute("name")
+				Attribute("winery", winery)
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				Attribute("winery")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		mt = design.Design.MediaTypes["application/vnd.goa.bottle"]
+	})
+
+	JustBeforeEach(func() {
+		s = genschema.MediaTypeSchema(design.Design, mt, view)
+	})
+
+	It("sets the schema and id fields", func() {
+		Ω(s.Schema).Should(Equal(genschema.DraftSchemaRef))
+		Ω(s.ID).Should(Equal("https://api.goa.design/schema/application/vnd.goa.bottle"))
+		Ω(s.Properties).Should(HaveKey("name"))
+	})
+
+	It("embeds the referenced definitions only", func() {
+		genschema.GenerateTypeDefinition(design.Design, design.Design.Types["Unrelated"])
+		s = genschema.MediaTypeSchema(design.Design, mt, view)
+		Ω(s.Properties["winery"].Ref).Should(Equal("#/definitions/Winery"))
+		Ω(s.Definitions).Should(HaveKey("Winery"))
+		Ω(s.Definitions).ShouldNot(HaveKey("Unrelated"))
+	})
+
+	It("serializes the schema and id fields", func() {
+		b, err := s.JSON()
+		Ω(err).ShouldNot(HaveOccurred())
+		var m map[string]interface{}
+		Ω(json.Unmarshal(b, &m)).ShouldNot(HaveOccurred())
+		Ω(m).Should(HaveKeyWithValue("$schema", "http://json-schema.org/draft-04/schema#"))
+		Ω(m).Should(HaveKeyWithValue("id", "https://api.goa.design/schema/application/vnd.goa.bottle"))
+	})
+
+	Context("with a view", func() {
+		BeforeEach(func() {
+			view = "tiny"
+		})
+
+		It("includes the view in the id", func() {
+			Ω(s.ID).Should(Equal(genschema.MediaTypeSchemaID(design.Design, mt, "tiny")))
+			Ω(s.ID).Should(Equal("https://api.goa.design/schema/application/vnd.goa.bottle/tiny"))
+			Ω(s.Properties).ShouldNot(HaveKey("name"))
+		})
+	})
 })
@@ -63,34 +63,48 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, swaggerDir)
 
-	// JSON
-	rawJSON, err := json.Marshal(s)
-	if err != nil {
+	if err = g.writeSpec(swaggerDir, "swagger", s); err != nil {
 		return nil, err
 	}
-	swaggerFile := filepath.Join(swaggerDir, "swagger.json")
-	if err := ioutil.WriteFile(swaggerFile, rawJSON, 0644); err != nil {
+
+	if err = g.writeSpec(swaggerDir, "openapi", openAPIFromSwagger(s, nil)); err != nil {
 		return nil, err
 	}
-	g.genfiles = append(g.genfiles, swaggerFile)
+
+	return g.genfiles, nil
+}
+
+// writeSpec writes the JSON and YAML representations of the given specification in the files
+// named after name in dir.
+func (g *Generator) writeSpec(dir, name string, spec interface{}) error {
+	// JSON
+	rawJSON, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	specFile := filepath.Join(dir, name+".json")
+	if err := ioutil.WriteFile(specFile, rawJSON, 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, specFile)
 
 	// YAML
 	var yamlSource interface{}
 	if err = json.Unmarshal(rawJSON, &yamlSource); err != nil {
-		return nil, err
+		return err
 	}
 
 	rawYAML, err := yaml.Marshal(yamlSource)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	swaggerFile = filepath.Join(swaggerDir, "swagger.yaml")
-	if err := ioutil.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
-		return nil, err
+	specFile = filepath.Join(dir, name+".yaml")
+	if err := ioutil.WriteFile(specFile, rawYAML, 0644); err != nil {
+		return err
 	}
-	g.genfiles = append(g.genfiles, swaggerFile)
+	g.genfiles = append(g.genfiles, specFile)
 
-	return g.genfiles, nil
+	return nil
 }
 
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
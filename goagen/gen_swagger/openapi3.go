@@ -0,0 +1,625 @@
+package genswagger
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification implemented by the documents
+// produced by NewOpenAPI3.
+const OpenAPIVersion = "3.0.3"
+
+type (
+	// OpenAPI represents an OpenAPI 3.0 document.
+	// See https://spec.openapis.org/oas/v3.0.3
+	OpenAPI struct {
+		OpenAPI      string                 `json:"openapi"`
+		Info         *Info                  `json:"info"`
+		Servers      []*OpenAPIServer       `json:"servers,omitempty"`
+		Paths        map[string]interface{} `json:"paths"`
+		Components   *OpenAPIComponents     `json:"components,omitempty"`
+		Tags         []*Tag                 `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty"`
+	}
+
+	// OpenAPIServer describes a server hosting the API.
+	OpenAPIServer struct {
+		// URL of the server, it may be relative to the location of the document.
+		URL string `json:"url"`
+		// Description of the server.
+		Description string `json:"description,omitempty"`
+	}
+
+	// OpenAPIComponents holds the reusable objects of the document.
+	OpenAPIComponents struct {
+		// Schemas lists the API types and media types.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// Parameters lists the parameters shared by the operations.
+		Parameters map[string]*OpenAPIParameter `json:"parameters,omitempty"`
+		// Responses lists the responses shared by the operations.
+		Responses map[string]*OpenAPIResponse `json:"responses,omitempty"`
+		// SecuritySchemes lists the security schemes used by the operations.
+		SecuritySchemes map[string]*OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// OpenAPIPath holds the operations available on a single path.
+	OpenAPIPath struct {
+		// Ref allows for an external definition of this path item.
+		Ref string `json:"$ref,omitempty"`
+		// Get defines a GET operation on this path.
+		Get *OpenAPIOperation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *OpenAPIOperation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *OpenAPIOperation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *OpenAPIOperation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *OpenAPIOperation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *OpenAPIOperation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *OpenAPIOperation `json:"patch,omitempty"`
+		// Parameters is the list of parameters that are applicable for all the operations
+		// described under this path.
+		Parameters []*OpenAPIParameter `json:"parameters,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIOperation describes a single API operation on a path.
+	OpenAPIOperation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*OpenAPIParameter `json:"parameters,omitempty"`
+		// RequestBody describes the request payload.
+		RequestBody *OpenAPIRequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses as they are returned from executing
+		// this operation.
+		Responses map[string]*OpenAPIResponse `json:"responses"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIParameter describes a single operation parameter.
+	OpenAPIParameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required"`
+		// AllowEmptyValue sets the ability to pass empty-valued query parameters.
+		AllowEmptyValue bool `json:"allowEmptyValue,omitempty"`
+		// Style describes how array values are serialized.
+		Style string `json:"style,omitempty"`
+		// Explode determines whether array values generate separate parameters.
+		Explode *bool `json:"explode,omitempty"`
+		// Schema defines the type of the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIRequestBody describes a request payload.
+	OpenAPIRequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content maps the media types accepted by the operation to the payload schema.
+		Content map[string]*OpenAPIMediaType `json:"content"`
+		// Required determines whether the request body is mandatory.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// OpenAPIMediaType describes the content of a request or response for a given media type.
+	OpenAPIMediaType struct {
+		// Schema defines the content structure.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// OpenAPIResponse describes an operation response.
+	OpenAPIResponse struct {
+		// Description of the response.
+		Description string `json:"description,omitempty"`
+		// Headers is a list of headers that are sent with the response.
+		Headers map[string]*OpenAPIHeader `json:"headers,omitempty"`
+		// Content maps the media types produced by the operation to the response schema.
+		Content map[string]*OpenAPIMediaType `json:"content,omitempty"`
+		// Ref references a response defined in the document components.
+		// This field is exclusive with the other fields of OpenAPIResponse.
+		Ref string `json:"$ref,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIHeader represents a response header.
+	OpenAPIHeader struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Schema defines the type of the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// OpenAPISecurityScheme defines a security scheme that can be used by the operations.
+	OpenAPISecurityScheme struct {
+		// Type of the security scheme. Valid values are "http", "apiKey" or "oauth2".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header or query parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// Flows describes the OAuth2 flow when type is "oauth2".
+		Flows map[string]*OpenAPIOAuthFlow `json:"flows,omitempty"`
+		// Extensions defines the specification extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIOAuthFlow describes an OAuth2 flow.
+	OpenAPIOAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes list the available scopes for the flow.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_OpenAPIPath           OpenAPIPath
+	_OpenAPIOperation      OpenAPIOperation
+	_OpenAPIParameter      OpenAPIParameter
+	_OpenAPIResponse       OpenAPIResponse
+	_OpenAPISecurityScheme OpenAPISecurityScheme
+)
+
+// MarshalJSON returns the JSON encoding of p.
+func (p OpenAPIPath) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIPath(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o OpenAPIOperation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIOperation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p OpenAPIParameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIParameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r OpenAPIResponse) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIResponse(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s OpenAPISecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPISecurityScheme(s), s.Extensions)
+}
+
+// NewOpenAPI3 creates the OpenAPI 3.0 document describing the given API. The document is built
+// from the same traversal of the design as the Swagger 2.0 specification produced by New. servers
+// lists the URLs of the servers hosting the API, if empty the servers are computed from the API
+// host, schemes and base path.
+func NewOpenAPI3(api *design.APIDefinition, servers []string) (*OpenAPI, error) {
+	s, err := New(api)
+	if err != nil {
+		return nil, err
+	}
+	return openAPIFromSwagger(s, servers), nil
+}
+
+// openAPIFromSwagger converts the given Swagger 2.0 specification into an OpenAPI 3.0 document.
+func openAPIFromSwagger(s *Swagger, servers []string) *OpenAPI {
+	o := &OpenAPI{
+		OpenAPI:      OpenAPIVersion,
+		Info:         s.Info,
+		Servers:      serversFromSwagger(s, servers),
+		Paths:        make(map[string]interface{}, len(s.Paths)),
+		Tags:         s.Tags,
+		ExternalDocs: s.ExternalDocs,
+	}
+	if o.Info == nil {
+		o.Info = &Info{}
+	}
+	for k, v := range s.Paths {
+		if p, ok := v.(*Path); ok {
+			o.Paths[k] = pathToOpenAPI(s, p)
+		} else {
+			// Extension
+			o.Paths[k] = v
+		}
+	}
+
+	c := &OpenAPIComponents{}
+	if len(s.Definitions) > 0 {
+		c.Schemas = make(map[string]*genschema.JSONSchema, len(s.Definitions))
+		for n, d := range s.Definitions {
+			c.Schemas[n] = schemaToOpenAPI(d)
+		}
+	}
+	if len(s.Parameters) > 0 {
+		c.Parameters = make(map[string]*OpenAPIParameter, len(s.Parameters))
+		for n, p := range s.Parameters {
+			c.Parameters[n] = paramToOpenAPI(p)
+		}
+	}
+	if len(s.Responses) > 0 {
+		c.Responses = make(map[string]*OpenAPIResponse, len(s.Responses))
+		for n, r := range s.Responses {
+			c.Responses[n] = responseToOpenAPI(r, "", s.Produces)
+		}
+	}
+	if len(s.SecurityDefinitions) > 0 {
+		c.SecuritySchemes = make(map[string]*OpenAPISecurityScheme, len(s.SecurityDefinitions))
+		for n, d := range s.SecurityDefinitions {
+			c.SecuritySchemes[n] = securitySchemeToOpenAPI(d)
+		}
+	}
+	if c.Schemas != nil || c.Parameters != nil || c.Responses != nil || c.SecuritySchemes != nil {
+		o.Components = c
+	}
+	return o
+}
+
+// serversFromSwagger returns the OpenAPI servers for the given URLs or computes them from the
+// Swagger specification host, schemes and base path if there is none.
+func serversFromSwagger(s *Swagger, urls []string) []*OpenAPIServer {
+	if len(urls) == 0 {
+		base := s.BasePath
+		if base == "" {
+			base = "/"
+		}
+		if s.Host == "" {
+			urls = []string{base}
+		} else {
+			schemes := s.Schemes
+			if len(schemes) == 0 {
+				schemes = []string{"http"}
+			}
+			for _, sch := range schemes {
+				urls = append(urls, sch+"://"+s.Host+strings.TrimSuffix(base, "/"))
+			}
+		}
+	}
+	servers := make([]*OpenAPIServer, len(urls))
+	for i, u := range urls {
+		servers[i] = &OpenAPIServer{URL: u}
+	}
+	return servers
+}
+
+func pathToOpenAPI(s *Swagger, p *Path) *OpenAPIPath {
+	op := func(o *Operation) *OpenAPIOperation {
+		if o == nil {
+			return nil
+		}
+		return operationToOpenAPI(s, o)
+	}
+	res := &OpenAPIPath{
+		Ref:        p.Ref,
+		Get:        op(p.Get),
+		Put:        op(p.Put),
+		Post:       op(p.Post),
+		Delete:     op(p.Delete),
+		Options:    op(p.Options),
+		Head:       op(p.Head),
+		Patch:      op(p.Patch),
+		Extensions: p.Extensions,
+	}
+	for _, param := range p.Parameters {
+		res.Parameters = append(res.Parameters, paramToOpenAPI(param))
+	}
+	return res
+}
+
+func operationToOpenAPI(s *Swagger, o *Operation) *OpenAPIOperation {
+	res := &OpenAPIOperation{
+		Tags:         o.Tags,
+		Summary:      o.Summary,
+		Description:  o.Description,
+		ExternalDocs: o.ExternalDocs,
+		OperationID:  o.OperationID,
+		Responses:    make(map[string]*OpenAPIResponse, len(o.Responses)),
+		Deprecated:   o.Deprecated,
+		Security:     o.Security,
+		Extensions:   o.Extensions,
+	}
+	consumes := o.Consumes
+	if len(consumes) == 0 {
+		consumes = s.Consumes
+	}
+	produces := o.Produces
+	if len(produces) == 0 {
+		produces = s.Produces
+	}
+	var form *genschema.JSONSchema
+	for _, p := range o.Parameters {
+		switch p.In {
+		case "body":
+			res.RequestBody = &OpenAPIRequestBody{
+				Description: p.Description,
+				Content:     contentFor(schemaToOpenAPI(p.Schema), consumes),
+				Required:    p.Required,
+			}
+		case "formData":
+			// OpenAPI 3.0 describes forms with the request body schema.
+			if form == nil {
+				form = genschema.NewJSONSchema()
+				form.Type = genschema.JSONObject
+			}
+			prop := schemaToOpenAPI(paramToOpenAPI(p).Schema)
+			prop.Description = p.Description
+			form.Properties[p.Name] = prop
+			if p.Required {
+				form.Required = append(form.Required, p.Name)
+			}
+		default:
+			res.Parameters = append(res.Parameters, paramToOpenAPI(p))
+		}
+	}
+	if form != nil {
+		res.RequestBody = &OpenAPIRequestBody{
+			Content:  contentFor(form, consumes),
+			Required: len(form.Required) > 0,
+		}
+	}
+	for code, r := range o.Responses {
+		res.Responses[code] = responseToOpenAPI(r, code, produces)
+	}
+	return res
+}
+
+// contentFor maps each of the given media types to the schema, it defaults to JSON if there is
+// no media type.
+func contentFor(schema *genschema.JSONSchema, mediaTypes []string) map[string]*OpenAPIMediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"application/json"}
+	}
+	content := make(map[string]*OpenAPIMediaType, len(mediaTypes))
+	for _, mt := range mediaTypes {
+		content[mt] = &OpenAPIMediaType{Schema: schema}
+	}
+	return content
+}
+
+func paramToOpenAPI(p *Parameter) *OpenAPIParameter {
+	res := &OpenAPIParameter{
+		Name:            p.Name,
+		In:              p.In,
+		Description:     p.Description,
+		Required:        p.Required,
+		AllowEmptyValue: p.AllowEmptyValue,
+		Extensions:      p.Extensions,
+	}
+	if p.Schema != nil {
+		res.Schema = schemaToOpenAPI(p.Schema)
+		return res
+	}
+	res.Schema = &genschema.JSONSchema{
+		Type:             genschema.JSONType(p.Type),
+		Format:           p.Format,
+		Items:            itemsToOpenAPI(p.Items),
+		DefaultValue:     p.Default,
+		Enum:             p.Enum,
+		Pattern:          p.Pattern,
+		Minimum:          p.Minimum,
+		Maximum:          p.Maximum,
+		ExclusiveMinimum: p.ExclusiveMinimum,
+		ExclusiveMaximum: p.ExclusiveMaximum,
+		MultipleOf:       multipleOf(p.MultipleOf),
+		MinLength:        p.MinLength,
+		MaxLength:        p.MaxLength,
+		UniqueItems:      p.UniqueItems,
+	}
+	if p.Type == "array" {
+		res.Style, res.Explode = styleFor(p.In, p.CollectionFormat)
+	}
+	return res
+}
+
+// styleFor returns the OpenAPI 3.0 style and explode values that correspond to the given Swagger
+// 2.0 collection format.
+func styleFor(in, collectionFormat string) (string, *bool) {
+	explode := false
+	switch collectionFormat {
+	case "multi":
+		explode = true
+		return "form", &explode
+	case "ssv":
+		return "spaceDelimited", &explode
+	case "pipes":
+		return "pipeDelimited", &explode
+	}
+	if in == "query" {
+		return "form", &explode
+	}
+	return "simple", &explode
+}
+
+func itemsToOpenAPI(items *Items) *genschema.JSONSchema {
+	if items == nil {
+		return nil
+	}
+	return &genschema.JSONSchema{
+		Type:             genschema.JSONType(items.Type),
+		Format:           items.Format,
+		Items:            itemsToOpenAPI(items.Items),
+		DefaultValue:     items.Default,
+		Enum:             items.Enum,
+		Pattern:          items.Pattern,
+		Minimum:          items.Minimum,
+		Maximum:          items.Maximum,
+		ExclusiveMinimum: items.ExclusiveMinimum,
+		ExclusiveMaximum: items.ExclusiveMaximum,
+		MultipleOf:       multipleOf(items.MultipleOf),
+		MinLength:        items.MinLength,
+		MaxLength:        items.MaxLength,
+		UniqueItems:      items.UniqueItems,
+	}
+}
+
+func responseToOpenAPI(r *Response, code string, produces []string) *OpenAPIResponse {
+	if r.Ref != "" {
+		return &OpenAPIResponse{Ref: refToOpenAPI(r.Ref)}
+	}
+	res := &OpenAPIResponse{
+		Description: r.Description,
+		Extensions:  r.Extensions,
+	}
+	if res.Description == "" {
+		// Description is required by OpenAPI 3.0
+		if status, err := strconv.Atoi(code); err == nil {
+			res.Description = http.StatusText(status)
+		}
+	}
+	if r.Schema != nil {
+		res.Content = contentFor(schemaToOpenAPI(r.Schema), produces)
+	}
+	if len(r.Headers) > 0 {
+		res.Headers = make(map[string]*OpenAPIHeader, len(r.Headers))
+		for n, h := range r.Headers {
+			res.Headers[n] = &OpenAPIHeader{
+				Description: h.Description,
+				Schema: &genschema.JSONSchema{
+					Type:             genschema.JSONType(h.Type),
+					Format:           h.Format,
+					Items:            itemsToOpenAPI(h.Items),
+					DefaultValue:     h.Default,
+					Enum:             h.Enum,
+					Pattern:          h.Pattern,
+					Minimum:          h.Minimum,
+					Maximum:          h.Maximum,
+					ExclusiveMinimum: h.ExclusiveMinimum,
+					ExclusiveMaximum: h.ExclusiveMaximum,
+					MultipleOf:       multipleOf(h.MultipleOf),
+					MinLength:        h.MinLength,
+					MaxLength:        h.MaxLength,
+					UniqueItems:      h.UniqueItems,
+				},
+			}
+		}
+	}
+	return res
+}
+
+func securitySchemeToOpenAPI(d *SecurityDefinition) *OpenAPISecurityScheme {
+	res := &OpenAPISecurityScheme{
+		Type:        d.Type,
+		Description: d.Description,
+		Extensions:  d.Extensions,
+	}
+	switch d.Type {
+	case "basic":
+		res.Type = "http"
+		res.Scheme = "basic"
+	case "apiKey":
+		res.Name = d.Name
+		res.In = d.In
+	case "oauth2":
+		flow := &OpenAPIOAuthFlow{
+			AuthorizationURL: d.AuthorizationURL,
+			TokenURL:         d.TokenURL,
+			Scopes:           d.Scopes,
+		}
+		if flow.Scopes == nil {
+			flow.Scopes = make(map[string]string)
+		}
+		name := d.Flow
+		switch d.Flow {
+		case "application":
+			name = "clientCredentials"
+		case "accessCode":
+			name = "authorizationCode"
+		}
+		res.Flows = map[string]*OpenAPIOAuthFlow{name: flow}
+	}
+	return res
+}
+
+// schemaToOpenAPI returns a copy of the given JSON schema where references to definitions point
+// to the document components and where the properties that do not exist in OpenAPI 3.0 schemas
+// are removed.
+func schemaToOpenAPI(s *genschema.JSONSchema) *genschema.JSONSchema {
+	if s == nil {
+		return nil
+	}
+	res := *s
+	res.Schema = ""
+	res.ID = ""
+	res.Media = nil
+	res.PathStart = ""
+	res.Links = nil
+	res.Ref = refToOpenAPI(s.Ref)
+	if res.Type == "file" {
+		res.Type = genschema.JSONType("string")
+		res.Format = "binary"
+	}
+	res.Items = schemaToOpenAPI(s.Items)
+	if ap, ok := s.AdditionalProperties.(*genschema.JSONSchema); ok {
+		res.AdditionalProperties = schemaToOpenAPI(ap)
+	}
+	if s.Properties != nil {
+		res.Properties = make(map[string]*genschema.JSONSchema, len(s.Properties))
+		for n, p := range s.Properties {
+			res.Properties[n] = schemaToOpenAPI(p)
+		}
+	}
+	// Nested definitions are not supported, definitions all live under components.
+	res.Definitions = nil
+	if s.AnyOf != nil {
+		res.AnyOf = make([]*genschema.JSONSchema, len(s.AnyOf))
+		for i, a := range s.AnyOf {
+			res.AnyOf[i] = schemaToOpenAPI(a)
+		}
+	}
+	return &res
+}
+
+// refToOpenAPI rewrites Swagger 2.0 references so that they point to the document components.
+func refToOpenAPI(ref string) string {
+	for _, prefix := range refPrefixes {
+		if strings.HasPrefix(ref, prefix[0]) {
+			return prefix[1] + strings.TrimPrefix(ref, prefix[0])
+		}
+	}
+	return ref
+}
+
+// refPrefixes lists the Swagger 2.0 reference prefixes and their OpenAPI 3.0 counterparts.
+var refPrefixes = [][2]string{
+	{"#/definitions/", "#/components/schemas/"},
+	{"#/parameters/", "#/components/parameters/"},
+	{"#/responses/", "#/components/responses/"},
+}
+
+func multipleOf(m float64) *float64 {
+	if m == 0 {
+		return nil
+	}
+	return &m
+}
@@ -0,0 +1,181 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/goagen/gen_swagger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewOpenAPI3", func() {
+	var servers []string
+	var doc map[string]interface{}
+	var newErr error
+
+	// lookup walks the generic representation of the document following the given keys.
+	lookup := func(keys ...string) interface{} {
+		var v interface{} = doc
+		for _, k := range keys {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[k]
+		}
+		return v
+	}
+
+	BeforeEach(func() {
+		servers = nil
+		doc = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		API("test", func() {
+			Title("test API")
+			Host("goa.design")
+			Scheme("https")
+			BasePath("/api")
+			BasicAuthSecurity("password")
+		})
+		todo := Type("Todo", func() {
+			Attribute("title", String)
+			Required("title")
+		})
+		Resource("todos", func() {
+			Action("list", func() {
+				Routing(GET("/todos"))
+				Params(func() {
+					Param("tags", ArrayOf(String))
+					Param("limit", Integer, func() {
+						Minimum(1)
+					})
+				})
+				Response(OK, ArrayOf(todo))
+			})
+			Action("create", func() {
+				Routing(POST("/todos"))
+				Payload(todo)
+				Response(Created, func() {
+					Headers(func() {
+						Header("Location", String, "URL of the created todo")
+					})
+				})
+			})
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		var o *genswagger.OpenAPI
+		o, newErr = genswagger.NewOpenAPI3(Design, servers)
+		if newErr != nil {
+			return
+		}
+		b, err := json.Marshal(o)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(json.Unmarshal(b, &doc)).ShouldNot(HaveOccurred())
+	})
+
+	It("produces an OpenAPI 3.0 document", func() {
+		Ω(newErr).ShouldNot(HaveOccurred())
+		Ω(lookup("openapi")).Should(HavePrefix("3.0."))
+		Ω(lookup("info", "title")).Should(Equal("test API"))
+		Ω(doc).ShouldNot(HaveKey("swagger"))
+		Ω(doc).ShouldNot(HaveKey("definitions"))
+		Ω(doc).ShouldNot(HaveKey("securityDefinitions"))
+		Ω(lookup("servers")).Should(Equal([]interface{}{
+			map[string]interface{}{"url": "https://goa.design/api"},
+		}))
+	})
+
+	It("moves the definitions to the components", func() {
+		Ω(lookup("components", "schemas", "Todo", "type")).Should(Equal("object"))
+		Ω(lookup("components", "securitySchemes", "password")).Should(Equal(map[string]interface{}{
+			"type": "http", "scheme": "basic",
+		}))
+	})
+
+	It("describes the payload with a request body", func() {
+		post := lookup("paths", "/todos", "post").(map[string]interface{})
+		Ω(post).ShouldNot(HaveKey("parameters"))
+		Ω(lookup("paths", "/todos", "post", "requestBody", "required")).Should(BeTrue())
+		schema := lookup("paths", "/todos", "post", "requestBody", "content", "application/json", "schema")
+		Ω(schema).Should(Equal(map[string]interface{}{"$ref": "#/components/schemas/Todo"}))
+	})
+
+	It("describes the parameters with schemas", func() {
+		params := lookup("paths", "/todos", "get", "parameters").([]interface{})
+		Ω(params).Should(HaveLen(2))
+		byName := make(map[string]map[string]interface{})
+		for _, p := range params {
+			byName[p.(map[string]interface{})["name"].(string)] = p.(map[string]interface{})
+		}
+		Ω(byName["limit"]["in"]).Should(Equal("query"))
+		Ω(byName["limit"]["schema"]).Should(Equal(map[string]interface{}{"type": "integer", "minimum": 1.0}))
+		Ω(byName["tags"]["style"]).Should(Equal("form"))
+		Ω(byName["tags"]["explode"]).Should(BeTrue())
+		Ω(byName["tags"]["schema"]).Should(Equal(map[string]interface{}{
+			"type": "array", "items": map[string]interface{}{"type": "string"},
+		}))
+	})
+
+	It("describes the responses with content and header schemas", func() {
+		items := lookup("paths", "/todos", "get", "responses", "200", "content", "application/json", "schema", "items")
+		Ω(items).Should(Equal(map[string]interface{}{"$ref": "#/components/schemas/Todo"}))
+		location := lookup("paths", "/todos", "post", "responses", "201", "headers", "Location")
+		Ω(location).Should(Equal(map[string]interface{}{
+			"description": "URL of the created todo",
+			"schema":      map[string]interface{}{"type": "string"},
+		}))
+		Ω(lookup("paths", "/todos", "post", "responses", "201", "description")).Should(Equal("Created"))
+	})
+
+	Context("with a payload with files", func() {
+		BeforeEach(func() {
+			upload := Type("Upload", func() {
+				Attribute("name", String)
+				Attribute("document", File)
+				Required("document")
+			})
+			Resource("documents", func() {
+				Action("upload", func() {
+					Routing(POST("/documents"))
+					Payload(upload)
+				})
+			})
+		})
+
+		It("describes the form with a multipart request body", func() {
+			Ω(lookup("paths", "/documents", "post")).ShouldNot(HaveKey("parameters"))
+			Ω(lookup("paths", "/documents", "post", "requestBody", "required")).Should(BeTrue())
+			schema := lookup("paths", "/documents", "post", "requestBody", "content", "multipart/form-data", "schema")
+			Ω(schema).Should(Equal(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":     map[string]interface{}{"type": "string"},
+					"document": map[string]interface{}{"type": "string", "format": "binary"},
+				},
+				"required": []interface{}{"document"},
+			}))
+		})
+	})
+
+	Context("with servers", func() {
+		BeforeEach(func() {
+			servers = []string{"https://api.goa.design", "http://localhost:8080"}
+		})
+
+		It("lists them", func() {
+			Ω(lookup("servers")).Should(Equal([]interface{}{
+				map[string]interface{}{"url": "https://api.goa.design"},
+				map[string]interface{}{"url": "http://localhost:8080"},
+			}))
+		})
+	})
+})
@@ -680,6 +680,22 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 	return res, nil
 }
 
+// paramsFromMultipartPayload returns the formData parameters describing the attributes of a
+// payload sent in a multipart/form-data request body. Object and hash attributes are sent as JSON
+// encoded strings.
+func paramsFromMultipartPayload(payload *design.UserTypeDefinition) []*Parameter {
+	var params []*Parameter
+	payload.Type.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		p := paramFor(at, n, "formData", payload.IsRequired(n))
+		if at.Type.IsObject() || at.Type.IsHash() {
+			p.Type = genschema.JSONString
+		}
+		params = append(params, p)
+		return nil
+	})
+	return params
+}
+
 func paramsFromHeaders(action *design.ActionDefinition) []*Parameter {
 	params := []*Parameter{}
 	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
@@ -702,6 +718,9 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 	if at.Type.IsArray() {
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
 		p.CollectionFormat = "multi"
+		if at.CollectionFormat != "" {
+			p.CollectionFormat = at.CollectionFormat
+		}
 	}
 	p.Extensions = extensionsFromDefinition(at.Metadata)
 	initValidations(at, p)
@@ -765,6 +784,10 @@ func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design
 			schema.Ref = genschema.MediaTypeRef(api, mt, view)
 		}
 	}
+	if schema == nil && r.Type != nil {
+		// Responses may also be described with a type rather than a media type.
+		schema = genschema.TypeSchema(api, r.Type)
+	}
 	headers, err := headersFromDefinition(r.Headers)
 	if err != nil {
 		return nil, err
@@ -812,10 +835,19 @@ func headersFromDefinition(headers *design.AttributeDefinition) (map[string]*Hea
 	res := make(map[string]*Header)
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
 		header := &Header{
-			Default:     at.DefaultValue,
+			Default:     toStringMap(at.DefaultValue),
 			Description: at.Description,
 			Type:        at.Type.Name(),
 		}
+		if at.Type.IsArray() {
+			// Swagger requires the item type of array headers, multi does not apply to
+			// headers so default to the HTTP list syntax.
+			header.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
+			header.CollectionFormat = "csv"
+			if at.CollectionFormat != "" && at.CollectionFormat != "multi" {
+				header.CollectionFormat = at.CollectionFormat
+			}
+		}
 		initValidations(at, header)
 		res[n] = header
 		return nil
@@ -908,7 +940,11 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		responses[strconv.Itoa(r.Status)] = resp
 	}
 
-	if action.Payload != nil {
+	var consumes []string
+	if action.Payload != nil && action.Payload.HasFiles() {
+		params = append(params, paramsFromMultipartPayload(action.Payload)...)
+		consumes = []string{"multipart/form-data"}
+	} else if action.Payload != nil {
 		payloadSchema := genschema.TypeSchema(api, action.Payload)
 		pp := &Parameter{
 			Name:        "payload",
@@ -943,6 +979,7 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
 		ExternalDocs: docsFromDefinition(action.Docs),
 		OperationID:  operationID,
+		Consumes:     consumes,
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
@@ -993,10 +1030,44 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 	case "PATCH":
 		p.Patch = operation
 	}
+	if route.Verb == "GET" && p.Head == nil {
+		// The mux serves HEAD requests using the GET handlers.
+		p.Head = headOperation(operation)
+	}
+	if p.Options == nil && len(action.Parent.AllOrigins()) > 0 {
+		// The generated code serves CORS preflight requests for all resource actions.
+		p.Options = optionsOperation(operation)
+	}
 	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
 	return nil
 }
 
+// headOperation returns the HEAD operation corresponding to the given GET operation. The
+// operation responses are identical except that they do not have a body.
+func headOperation(get *Operation) *Operation {
+	head := *get
+	head.OperationID = get.OperationID + "#head"
+	head.Responses = make(map[string]*Response, len(get.Responses))
+	for code, r := range get.Responses {
+		resp := *r
+		resp.Schema = nil
+		head.Responses[code] = &resp
+	}
+	return &head
+}
+
+// optionsOperation returns the operation describing the CORS preflight requests handled by the
+// path of the given operation.
+func optionsOperation(op *Operation) *Operation {
+	return &Operation{
+		Tags:        op.Tags,
+		Summary:     "CORS preflight",
+		OperationID: op.OperationID + "#options",
+		Responses:   map[string]*Response{"200": {Description: "OK"}},
+		Schemes:     op.Schemes,
+	}
+}
+
 func applySecurity(operation *Operation, security *design.SecurityDefinition) {
 	if security != nil && security.Scheme.Kind != design.NoSecurityKind {
 		if security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
@@ -1046,6 +1117,9 @@ func initEnumValidation(def interface{}, values []interface{}) {
 }
 
 func initFormatValidation(def interface{}, format string) {
+	if format == "" {
+		return
+	}
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Format = format
@@ -1067,31 +1141,42 @@ func initPatternValidation(def interface{}, pattern string) {
 	}
 }
 
-func initMinimumValidation(def interface{}, min *float64) {
+func initMinimumValidation(def interface{}, min *float64, exclusive bool) {
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	case *Header:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	case *Items:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	}
 }
 
-func initMaximumValidation(def interface{}, max *float64) {
+func initMaximumValidation(def interface{}, max *float64, exclusive bool) {
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
 	case *Header:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
 	case *Items:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
+	}
+}
+
+func initMultipleOfValidation(def interface{}, multipleOf float64) {
+	switch actual := def.(type) {
+	case *Parameter:
+		actual.MultipleOf = multipleOf
+	case *Header:
+		actual.MultipleOf = multipleOf
+	case *Items:
+		actual.MultipleOf = multipleOf
 	}
 }
 
@@ -1125,7 +1210,28 @@ func initMaxLengthValidation(def interface{}, isArray bool, max *int) {
 	}
 }
 
+func initUniqueItemsValidation(def interface{}) {
+	switch actual := def.(type) {
+	case *Parameter:
+		actual.UniqueItems = true
+	case *Header:
+		actual.UniqueItems = true
+	case *Items:
+		actual.UniqueItems = true
+	}
+}
+
 func initValidations(attr *design.AttributeDefinition, def interface{}) {
+	// Primitive types that map to a string with a well known format carry that format,
+	// explicit format validations take precedence.
+	switch attr.Type.Kind() {
+	case design.UUIDKind:
+		initFormatValidation(def, "uuid")
+	case design.DateTimeKind:
+		initFormatValidation(def, "date-time")
+	case design.DecimalKind:
+		initFormatValidation(def, "decimal")
+	}
 	val := attr.Validation
 	if val == nil {
 		return
@@ -1134,10 +1240,13 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 	initFormatValidation(def, val.Format)
 	initPatternValidation(def, val.Pattern)
 	if val.Minimum != nil {
-		initMinimumValidation(def, val.Minimum)
+		initMinimumValidation(def, val.Minimum, val.ExclusiveMinimum)
 	}
 	if val.Maximum != nil {
-		initMaximumValidation(def, val.Maximum)
+		initMaximumValidation(def, val.Maximum, val.ExclusiveMaximum)
+	}
+	if val.MultipleOf != nil {
+		initMultipleOfValidation(def, *val.MultipleOf)
 	}
 	if val.MinLength != nil {
 		initMinLengthValidation(def, attr.Type.IsArray(), val.MinLength)
@@ -1145,4 +1254,7 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 	if val.MaxLength != nil {
 		initMaxLengthValidation(def, attr.Type.IsArray(), val.MaxLength)
 	}
+	if val.UniqueItems {
+		initUniqueItemsValidation(def)
+	}
 }
@@ -214,6 +214,252 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with exclusive bounds and multipleOf params", func() {
+			const (
+				basePath = "/p/:priceParam"
+				price    = "priceParam"
+			)
+
+			BeforeEach(func() {
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					BasePath(basePath)
+					Params(func() {
+						Param(price, Number, func() {
+							ExclusiveMinimum(0)
+							ExclusiveMaximum(100)
+							MultipleOf(0.01)
+						})
+					})
+				}
+			})
+
+			It("sets the exclusiveMinimum, exclusiveMaximum and multipleOf fields", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				p := swagger.Parameters[price]
+				Ω(p).ShouldNot(BeNil())
+				Ω(*p.Minimum).Should(Equal(0.0))
+				Ω(p.ExclusiveMinimum).Should(BeTrue())
+				Ω(*p.Maximum).Should(Equal(100.0))
+				Ω(p.ExclusiveMaximum).Should(BeTrue())
+				Ω(p.MultipleOf).Should(Equal(0.01))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
+		Context("with action params", func() {
+			var minLimit, maxLimit = 1.0, 100.0
+
+			BeforeEach(func() {
+				Resource("posts", func() {
+					BasePath("/blogs/:blogID/posts")
+					Action("list", func() {
+						Routing(GET(""))
+						Params(func() {
+							Param("blogID", Integer)
+							Param("since", DateTime)
+							Param("author", UUID)
+							Param("price", Decimal)
+							Param("status", String, func() {
+								Enum("draft", "published")
+							})
+							Param("limit", Integer, func() {
+								Minimum(minLimit)
+								Maximum(maxLimit)
+							})
+							Required("status")
+						})
+						Response(OK)
+					})
+				})
+			})
+
+			It("lists every declared param", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.Paths["/blogs/{blogID}/posts"]).ShouldNot(BeNil())
+				op := swagger.Paths["/blogs/{blogID}/posts"].(*genswagger.Path).Get
+				Ω(op).ShouldNot(BeNil())
+				params := make(map[string]*genswagger.Parameter)
+				for _, p := range op.Parameters {
+					params[p.Name] = p
+				}
+				Ω(params).Should(HaveLen(6))
+				Ω(params["blogID"]).Should(Equal(&genswagger.Parameter{In: "path", Name: "blogID", Type: "integer", Required: true}))
+				Ω(params["since"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "since", Type: "string", Format: "date-time"}))
+				Ω(params["author"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "author", Type: "string", Format: "uuid"}))
+				Ω(params["price"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "price", Type: "string", Format: "decimal"}))
+				Ω(params["status"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "status", Type: "string", Required: true,
+					Enum: []interface{}{"draft", "published"}}))
+				Ω(params["limit"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "limit", Type: "integer",
+					Minimum: &minLimit, Maximum: &maxLimit}))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+			Context("with default values and collections", func() {
+				BeforeEach(func() {
+					Resource("comments", func() {
+						BasePath("/posts/:postID/comments")
+						Action("list", func() {
+							Routing(GET(""))
+							Params(func() {
+								Param("postID", String, func() {
+									Pattern("^[a-z0-9-]+$")
+								})
+								Param("page", Integer, func() {
+									Default(1)
+								})
+								Param("tags", ArrayOf(String, func() {
+									MinLength(2)
+								}))
+							})
+							Response(OK)
+						})
+					})
+				})
+
+				It("sets the defaults, validations and items", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/posts/{postID}/comments"].(*genswagger.Path).Get
+					Ω(op).ShouldNot(BeNil())
+					params := make(map[string]*genswagger.Parameter)
+					for _, p := range op.Parameters {
+						params[p.Name] = p
+					}
+					Ω(params).Should(HaveLen(3))
+					Ω(params["postID"]).Should(Equal(&genswagger.Parameter{In: "path", Name: "postID", Type: "string", Required: true,
+						Pattern: "^[a-z0-9-]+$"}))
+					Ω(params["page"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "page", Type: "integer", Default: 1}))
+					minLength := 2
+					Ω(params["tags"]).Should(Equal(&genswagger.Parameter{In: "query", Name: "tags", Type: "array", CollectionFormat: "multi",
+						Items: &genswagger.Items{Type: "string", MinLength: &minLength}}))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+		})
+
+		Context("with response headers", func() {
+			BeforeEach(func() {
+				Resource("posts", func() {
+					Action("show", func() {
+						Routing(GET("/posts/:id"))
+						Response(OK, "text/html", func() {
+							Headers(func() {
+								Header("Content-Type", String, "Rendered media type", func() {
+									Pattern("^text/html")
+								})
+								Header("Cache-Control", String, "Caching directives", func() {
+									Enum("no-cache", "max-age=60")
+								})
+								Header("Link", ArrayOf(String), "Related resources")
+							})
+						})
+					})
+				})
+			})
+
+			It("documents the headers", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				op := swagger.Paths["/posts/{id}"].(*genswagger.Path).Get
+				Ω(op).ShouldNot(BeNil())
+				Ω(op.Responses["200"]).ShouldNot(BeNil())
+				headers := op.Responses["200"].Headers
+				Ω(headers).Should(HaveLen(3))
+				Ω(headers["Content-Type"]).Should(Equal(&genswagger.Header{Description: "Rendered media type",
+					Type: "string", Pattern: "^text/html"}))
+				Ω(headers["Cache-Control"]).Should(Equal(&genswagger.Header{Description: "Caching directives",
+					Type: "string", Enum: []interface{}{"no-cache", "max-age=60"}}))
+				Ω(headers["Link"]).Should(Equal(&genswagger.Header{Description: "Related resources",
+					Type: "array", Items: &genswagger.Items{Type: "string"}, CollectionFormat: "csv"}))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+			Context("with a create action returning a location", func() {
+				BeforeEach(func() {
+					Resource("todos", func() {
+						Action("create", func() {
+							Routing(POST("/todos"))
+							Response(Created, func() {
+								Headers(func() {
+									Header("Location", String, "URL of the created todo", func() {
+										Pattern("^/todos/[0-9]+$")
+									})
+									Required("Location")
+								})
+							})
+						})
+					})
+				})
+
+				It("documents the Location header", func() {
+					Ω(newErr).ShouldNot(HaveOccurred())
+					op := swagger.Paths["/todos"].(*genswagger.Path).Post
+					Ω(op).ShouldNot(BeNil())
+					Ω(op.Responses["201"]).ShouldNot(BeNil())
+					Ω(op.Responses["201"].Headers).Should(Equal(map[string]*genswagger.Header{
+						"Location": {Description: "URL of the created todo", Type: "string", Pattern: "^/todos/[0-9]+$"},
+					}))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+		})
+
+		Context("with a response described by a type", func() {
+			BeforeEach(func() {
+				task := Type("Task", func() {
+					Attribute("title", String, func() {
+						MinLength(1)
+						MaxLength(100)
+					})
+					Attribute("status", String, func() {
+						Enum("todo", "done")
+					})
+					Attribute("tags", ArrayOf(String))
+					Required("title")
+				})
+				Resource("tasks", func() {
+					Action("show", func() {
+						Routing(GET("/tasks/:id"))
+						Response(OK, task)
+					})
+					Action("list", func() {
+						Routing(GET("/tasks"))
+						Response(OK, ArrayOf(task))
+					})
+				})
+			})
+
+			It("documents the response bodies", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				show := swagger.Paths["/tasks/{id}"].(*genswagger.Path).Get
+				Ω(show.Responses["200"].Schema).ShouldNot(BeNil())
+				Ω(show.Responses["200"].Schema.Ref).Should(Equal("#/definitions/Task"))
+				list := swagger.Paths["/tasks"].(*genswagger.Path).Get
+				Ω(list.Responses["200"].Schema).ShouldNot(BeNil())
+				Ω(list.Responses["200"].Schema.Type).Should(BeEquivalentTo("array"))
+				Ω(list.Responses["200"].Schema.Items.Ref).Should(Equal("#/definitions/Task"))
+
+				Ω(swagger.Definitions).Should(HaveKey("Task"))
+				def := swagger.Definitions["Task"]
+				Ω(def.Type).Should(BeEquivalentTo("object"))
+				Ω(def.Required).Should(Equal([]string{"title"}))
+				Ω(def.Properties).Should(HaveLen(3))
+				Ω(def.Properties["title"].Type).Should(BeEquivalentTo("string"))
+				Ω(*def.Properties["title"].MinLength).Should(Equal(1))
+				Ω(*def.Properties["title"].MaxLength).Should(Equal(100))
+				Ω(def.Properties["status"].Enum).Should(Equal([]interface{}{"todo", "done"}))
+				Ω(def.Properties["tags"].Type).Should(BeEquivalentTo("array"))
+				Ω(def.Properties["tags"].Items.Type).Should(BeEquivalentTo("string"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with required payload", func() {
 			BeforeEach(func() {
 				p := Type("RequiredPayload", func() {
@@ -260,6 +506,41 @@ var _ = Describe("New", func() {
 
 		})
 
+		Context("with a payload with files", func() {
+			BeforeEach(func() {
+				p := Type("UploadPayload", func() {
+					Member("name", String)
+					Member("document", File, "Uploaded document")
+					Member("metadata", HashOf(String, String))
+					Required("document")
+				})
+				Resource("res", func() {
+					Action("upload", func() {
+						Routing(POST("/uploads"))
+						Payload(p)
+					})
+				})
+			})
+
+			It("describes the payload with formData parameters", func() {
+				op := swagger.Paths["/uploads"].(*genswagger.Path).Post
+				Ω(op.Consumes).Should(Equal([]string{"multipart/form-data"}))
+				Ω(op.Parameters).Should(HaveLen(3))
+				byName := make(map[string]*genswagger.Parameter)
+				for _, p := range op.Parameters {
+					Ω(p.In).Should(Equal("formData"))
+					byName[p.Name] = p
+				}
+				Ω(byName["document"].Type).Should(Equal("file"))
+				Ω(byName["document"].Required).Should(BeTrue())
+				Ω(byName["document"].Description).Should(Equal("Uploaded document"))
+				Ω(byName["metadata"].Type).Should(Equal("string"))
+				Ω(byName["name"].Type).Should(Equal("string"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with zero value validations", func() {
 			const (
 				intParam = "intParam"
@@ -566,6 +847,50 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with a GET action", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("show", func() {
+						Routing(GET("/:id"))
+						Response(OK, func() {
+							Media("application/json")
+						})
+					})
+				})
+			})
+
+			It("generates the corresponding HEAD operation", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				p := swagger.Paths["/{id}"].(*genswagger.Path)
+				Ω(p.Get).ShouldNot(BeNil())
+				Ω(p.Head).ShouldNot(BeNil())
+				Ω(p.Head.OperationID).Should(Equal("res#show#head"))
+				Ω(p.Head.Responses).Should(HaveKey("200"))
+				Ω(p.Head.Responses["200"].Schema).Should(BeNil())
+				Ω(p.Options).Should(BeNil())
+			})
+
+			Context("with CORS", func() {
+				BeforeEach(func() {
+					base := Design.DSLFunc
+					Design.DSLFunc = func() {
+						base()
+						Origin("http://example.com", func() {
+							Methods("GET")
+						})
+					}
+				})
+
+				It("generates the OPTIONS operation", func() {
+					p := swagger.Paths["/{id}"].(*genswagger.Path)
+					Ω(p.Options).ShouldNot(BeNil())
+					Ω(p.Options.Responses).Should(HaveKey("200"))
+				})
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+		})
+
 		Context("with metadata", func() {
 			const gat = "gat"
 			const extension = `{"foo":"bar"}`
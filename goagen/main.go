@@ -134,6 +134,14 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(schemaCmd)
 
+	// postmanCmd implements the "postman" command.
+	postmanCmd := &cobra.Command{
+		Use:   "postman",
+		Short: "Generate Postman collection",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genpostman", c) },
+	}
+	rootCmd.AddCommand(postmanCmd)
+
 	// genCmd implements the "gen" command.
 	var (
 		pkgPath string
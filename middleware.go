@@ -78,3 +78,31 @@ func httpHandlerToMiddleware(m http.HandlerFunc) Middleware {
 		}
 	}
 }
+
+// IdempotentAction applies the middleware set in the request context with WithIdempotency, if
+// any, to the given action handler. Middlewares such as middleware.Idempotent use it to handle
+// only the actions declared idempotent in the design.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func IdempotentAction(h Handler) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if m, ok := ctx.Value(idempotencyKey).(Middleware); ok {
+			return m(h)(ctx, rw, req)
+		}
+		return h(ctx, rw, req)
+	}
+}
+
+// CoalesceAction applies the middleware set in the request context with WithCoalescing, if any,
+// to the given action handler. Middlewares such as middleware.Coalesce use it to handle only the
+// actions declared coalesced in the design.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func CoalesceAction(h Handler) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if m, ok := ctx.Value(coalesceKey).(Middleware); ok {
+			return m(h)(ctx, rw, req)
+		}
+		return h(ctx, rw, req)
+	}
+}
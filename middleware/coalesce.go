@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/goadesign/goa"
+
+	"golang.org/x/net/context"
+)
+
+// coalescedCall is a handler execution shared by concurrent identical requests.
+type coalescedCall struct {
+	done     chan struct{}
+	resp     *CachedResponse
+	err      error
+	canceled bool
+}
+
+// Coalesce creates a middleware that shares a single execution of the actions declared coalesced
+// in the design (see the Coalesce DSL) among concurrent identical GET requests. Requests are
+// identical if they are made to the same action with the same path, query string and Accept and
+// Accept-Encoding headers. The first request runs the handler, requests that arrive while it is in
+// progress wait for it to complete and receive a copy of its response (status, headers and body)
+// or of the error it returned. Requests that arrive afterwards run the handler again, Coalesce
+// does not cache responses.
+//
+// Waiting requests return as soon as their own context is done. If the request running the
+// handler is canceled or times out before the handler completes, the waiting requests run the
+// handler again instead of failing with its cancellation error.
+//
+// Requests that carry credentials in the Authorization or Cookie headers are never coalesced.
+// Other request headers are not part of the key: coalesced actions must not produce responses
+// that depend on them, for example on API keys sent in custom headers. Requests made to other
+// actions are left untouched.
+func Coalesce() goa.Middleware {
+	var mu sync.Mutex
+	inflight := make(map[string]*coalescedCall)
+
+	coalesce := func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.Method != "GET" || req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+				return h(ctx, rw, req)
+			}
+			key := goa.ContextController(ctx) + "." + goa.ContextAction(ctx) + ":" +
+				req.URL.Path + "?" + req.URL.RawQuery + "\n" +
+				req.Header.Get("Accept") + "\n" + req.Header.Get("Accept-Encoding")
+			resp := goa.ContextResponse(ctx)
+
+			for {
+				mu.Lock()
+				c, ok := inflight[key]
+				if !ok {
+					break
+				}
+				mu.Unlock()
+				select {
+				case <-c.done:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				if c.canceled {
+					continue
+				}
+				if c.resp != nil {
+					if err := c.resp.replay(resp); err != nil {
+						return err
+					}
+				}
+				return c.err
+			}
+			c := &coalescedCall{done: make(chan struct{})}
+			inflight[key] = c
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				delete(inflight, key)
+				mu.Unlock()
+				close(c.done)
+			}()
+
+			rec := &recordingResponseWriter{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(rec)
+			c.err = h(ctx, rw, req)
+			if c.err != nil && (ctx.Err() != nil || c.err == context.Canceled || c.err == context.DeadlineExceeded) {
+				c.canceled = true
+			} else if rec.header != nil {
+				c.resp = rec.response()
+			}
+			return c.err
+		}
+	}
+
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return h(goa.WithCoalescing(ctx, coalesce), rw, req)
+		}
+	}
+}
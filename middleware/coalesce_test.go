@@ -0,0 +1,232 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Coalesce", func() {
+	const concurrency = 5
+
+	var service *goa.Service
+	var calls int32
+	var started, block chan struct{}
+	var handlerErr error
+	var action, handler goa.Handler
+	var headers []http.Header
+	var setup func(i int, resp *goa.ResponseData)
+	var prepare func(i int, ctx context.Context) context.Context
+
+	newRequest := func(method, url string) (context.Context, *testResponseWriter, *http.Request) {
+		req, err := http.NewRequest(method, url, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := newTestResponseWriter()
+		ctx := goa.WithAction(newContext(service, rw, req, nil), "list")
+		return ctx, rw, req
+	}
+
+	// run sends concurrent requests and releases the handler once they have all been made.
+	run := func(method string, urls ...string) ([]*testResponseWriter, []error) {
+		started, block = make(chan struct{}, len(urls)), make(chan struct{})
+		rws := make([]*testResponseWriter, len(urls))
+		errs := make([]error, len(urls))
+		var wg sync.WaitGroup
+		for i, u := range urls {
+			ctx, rw, req := newRequest(method, u)
+			if i < len(headers) {
+				req.Header = headers[i]
+			}
+			if setup != nil {
+				setup(i, goa.ContextResponse(ctx))
+			}
+			if prepare != nil {
+				ctx = prepare(i, ctx)
+			}
+			rws[i] = rw
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = handler(ctx, rw, req)
+			}(i)
+			if i == 0 {
+				<-started
+			}
+		}
+		// Give the other requests time to reach the middleware.
+		time.Sleep(50 * time.Millisecond)
+		close(block)
+		wg.Wait()
+		return rws, errs
+	}
+
+	BeforeEach(func() {
+		service = newService(nil)
+		calls = 0
+		handlerErr = nil
+		headers = nil
+		setup = nil
+		prepare = nil
+		action = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			n := atomic.AddInt32(&calls, 1)
+			started <- struct{}{}
+			select {
+			case <-block:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if handlerErr != nil {
+				return handlerErr
+			}
+			rw.Header().Set("X-Call", "done")
+			return service.Send(ctx, 200, map[string]interface{}{"call": n})
+		}
+		handler = middleware.Coalesce()(goa.CoalesceAction(action))
+	})
+
+	It("runs the handler once for concurrent identical requests", func() {
+		urls := make([]string, concurrency)
+		for i := range urls {
+			urls[i] = "/bottles?sort=name"
+		}
+		rws, errs := run("GET", urls...)
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(1)))
+		for i, rw := range rws {
+			Ω(errs[i]).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("X-Call")).Should(Equal("done"))
+			Ω(string(rw.Body)).Should(Equal(`{"call":1}` + "\n"))
+		}
+	})
+
+	It("does not coalesce the actions that are not declared coalesced", func() {
+		handler = middleware.Coalesce()(action)
+		_, errs := run("GET", "/bottles", "/bottles")
+		Ω(errs[0]).ShouldNot(HaveOccurred())
+		Ω(errs[1]).ShouldNot(HaveOccurred())
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+	})
+
+	It("runs the handler for each distinct query", func() {
+		_, errs := run("GET", "/bottles?sort=name", "/bottles?sort=year")
+		Ω(errs[0]).ShouldNot(HaveOccurred())
+		Ω(errs[1]).ShouldNot(HaveOccurred())
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+	})
+
+	It("does not coalesce other methods", func() {
+		_, errs := run("POST", "/bottles", "/bottles")
+		Ω(errs[0]).ShouldNot(HaveOccurred())
+		Ω(errs[1]).ShouldNot(HaveOccurred())
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+	})
+
+	It("does not coalesce requests carrying credentials", func() {
+		headers = []http.Header{
+			{"Authorization": {"Bearer alice"}},
+			{"Authorization": {"Bearer bob"}},
+			{"Cookie": {"session=carol"}},
+		}
+		_, errs := run("GET", "/bottles", "/bottles", "/bottles")
+		for _, err := range errs {
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(3)))
+	})
+
+	It("does not coalesce requests accepting different encodings", func() {
+		headers = []http.Header{{"Accept-Encoding": {"gzip"}}, {}}
+		_, errs := run("GET", "/bottles", "/bottles")
+		Ω(errs[0]).ShouldNot(HaveOccurred())
+		Ω(errs[1]).ShouldNot(HaveOccurred())
+		Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+	})
+
+	Context("with a writer altering the response header below the middleware", func() {
+		BeforeEach(func() {
+			setup = func(i int, resp *goa.ResponseData) {
+				resp.Header().Set("Vary", "Accept-Encoding")
+				if i == 0 {
+					resp.SwitchWriter(&encodingWriter{ResponseWriter: resp.SwitchWriter(nil)})
+				}
+			}
+		})
+
+		It("replays the header set by the handler only", func() {
+			rws, errs := run("GET", "/bottles", "/bottles")
+			Ω(errs[0]).ShouldNot(HaveOccurred())
+			Ω(errs[1]).ShouldNot(HaveOccurred())
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(1)))
+			Ω(rws[0].ParentHeader.Get("Content-Encoding")).Should(Equal("gzip"))
+			Ω(rws[1].ParentHeader.Get("Content-Encoding")).Should(BeEmpty())
+			Ω(rws[1].ParentHeader.Get("X-Call")).Should(Equal("done"))
+			Ω(rws[1].ParentHeader["Vary"]).Should(Equal([]string{"Accept-Encoding"}))
+		})
+	})
+
+	Context("with a waiting request that is canceled", func() {
+		BeforeEach(func() {
+			prepare = func(i int, ctx context.Context) context.Context {
+				if i == 0 {
+					return ctx
+				}
+				ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+				released := block
+				go func() { <-released; cancel() }()
+				return ctx
+			}
+		})
+
+		It("returns without waiting for the handler", func() {
+			_, errs := run("GET", "/bottles", "/bottles")
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(1)))
+			Ω(errs[0]).ShouldNot(HaveOccurred())
+			Ω(errs[1]).Should(Equal(context.DeadlineExceeded))
+		})
+	})
+
+	Context("with a request running the handler that is canceled", func() {
+		BeforeEach(func() {
+			prepare = func(i int, ctx context.Context) context.Context {
+				if i > 0 {
+					return ctx
+				}
+				ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+				released := block
+				go func() { <-released; cancel() }()
+				return ctx
+			}
+		})
+
+		It("runs the handler again for the waiting requests", func() {
+			rws, errs := run("GET", "/bottles", "/bottles")
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(2)))
+			Ω(errs[0]).Should(Equal(context.DeadlineExceeded))
+			Ω(errs[1]).ShouldNot(HaveOccurred())
+			Ω(rws[1].Status).Should(Equal(200))
+			Ω(string(rws[1].Body)).Should(Equal(`{"call":2}` + "\n"))
+		})
+	})
+
+	Context("with a handler returning an error", func() {
+		BeforeEach(func() {
+			handlerErr = errors.New("boom")
+		})
+
+		It("returns the error to all the requests", func() {
+			_, errs := run("GET", "/bottles", "/bottles", "/bottles")
+			Ω(atomic.LoadInt32(&calls)).Should(Equal(int32(1)))
+			for _, err := range errs {
+				Ω(err).Should(Equal(handlerErr))
+			}
+		})
+	})
+})
@@ -6,3 +6,6 @@ type middlewareKey int
 
 // ReqIDKey is the context key used by the RequestID middleware to store the request ID value.
 const reqIDKey middlewareKey = 1
+
+// cspNonceKey is the context key used by the CSPNonce middleware to store the nonce value.
+const cspNonceKey middlewareKey = 2
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/cors"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultCORSMethods lists the methods allowed in cross-origin requests by the CORS middleware
+// when CORSOptions does not specify any.
+var DefaultCORSMethods = []string{"GET", "HEAD", "POST"}
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin requests. Origins are
+	// matched using cors.MatchOrigin so that they may contain a wildcard
+	// ("http://*.goa.design"), consist of a regular expression enclosed in slashes or be "*"
+	// to allow all origins.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods allowed in cross-origin requests, defaults to
+	// DefaultCORSMethods.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed in cross-origin requests.
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers that clients may read in addition to the
+	// simple response headers.
+	ExposedHeaders []string
+	// AllowCredentials allows requests to include credentials such as cookies or HTTP
+	// authentication. The origin of such requests is echoed in the Access-Control-Allow-Origin
+	// header as browsers reject the "*" wildcard in this case. AllowCredentials cannot be used
+	// with the "*" origin as it would let any website make authenticated requests and read the
+	// responses, the origins must be listed explicitly.
+	AllowCredentials bool
+	// MaxAge is the duration during which clients may cache the response to preflight
+	// requests. The Access-Control-Max-Age header is not set if MaxAge is zero.
+	MaxAge time.Duration
+}
+
+// corsPolicy holds the CORS headers computed from a CORSOptions value.
+type corsPolicy struct {
+	origins       []string
+	allowed       map[string]bool
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	credentials   bool
+	maxAge        string
+}
+
+// CORS creates a middleware that implements the server side of CORS for requests made from the
+// allowed origins. Preflight requests, that is OPTIONS requests carrying the
+// Access-Control-Request-Method header, are answered directly with the Access-Control-Allow-*
+// headers built from the options when the requested method is allowed. Other requests are given
+// the Access-Control-Allow-Origin header before being handled. Requests made from origins that
+// are not allowed are handled as if the middleware was not mounted.
+//
+// CORS accepts multiple options to configure origins differently, the options used for a request
+// are the first ones whose AllowedOrigins match the request origin. CORS panics if options that
+// allow credentials also allow the "*" origin.
+//
+// CORS is meant to be mounted on the service so that it also handles the preflight requests made
+// to paths that do not define an OPTIONS action:
+//
+//	service.Use(middleware.CORS(middleware.CORSOptions{
+//		AllowedOrigins: []string{"https://*.goa.design"},
+//		AllowedHeaders: []string{"Authorization", "Content-Type"},
+//		MaxAge:         10 * time.Minute,
+//	}, middleware.CORSOptions{
+//		AllowedOrigins:   []string{"https://admin.goa.design"},
+//		AllowedMethods:   []string{"GET", "PUT", "DELETE"},
+//		AllowCredentials: true,
+//	}))
+func CORS(opts ...CORSOptions) goa.Middleware {
+	policies := make([]*corsPolicy, len(opts))
+	for i, o := range opts {
+		methods := o.AllowedMethods
+		if len(methods) == 0 {
+			methods = DefaultCORSMethods
+		}
+		if o.AllowCredentials {
+			for _, origin := range o.AllowedOrigins {
+				if origin == "*" {
+					panic(`CORS: the "*" origin cannot be used with AllowCredentials`)
+				}
+			}
+		}
+		allowed := make(map[string]bool, len(methods))
+		for _, m := range methods {
+			allowed[strings.ToUpper(m)] = true
+		}
+		p := &corsPolicy{
+			origins:       o.AllowedOrigins,
+			allowed:       allowed,
+			allowMethods:  strings.Join(methods, ", "),
+			allowHeaders:  strings.Join(o.AllowedHeaders, ", "),
+			exposeHeaders: strings.Join(o.ExposedHeaders, ", "),
+			credentials:   o.AllowCredentials,
+		}
+		if o.MaxAge > 0 {
+			p.maxAge = strconv.Itoa(int(o.MaxAge / time.Second))
+		}
+		policies[i] = p
+	}
+
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			origin := req.Header.Get("Origin")
+			if origin == "" {
+				return h(ctx, rw, req)
+			}
+			var (
+				policy *corsPolicy
+				spec   string
+			)
+		match:
+			for _, p := range policies {
+				for _, o := range p.origins {
+					if cors.MatchOrigin(origin, o) {
+						policy, spec = p, o
+						break match
+					}
+				}
+			}
+			if policy == nil {
+				// The response would include CORS headers for other origins.
+				rw.Header().Add("Vary", "Origin")
+				return h(ctx, rw, req)
+			}
+			if spec != "*" {
+				rw.Header().Add("Vary", "Origin")
+			}
+			method := req.Header.Get("Access-Control-Request-Method")
+			preflight := req.Method == "OPTIONS" && method != ""
+			if preflight && !policy.allowed[strings.ToUpper(method)] {
+				return h(ctx, rw, req)
+			}
+			if spec == "*" {
+				rw.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				rw.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if policy.credentials {
+				rw.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			if !preflight {
+				if policy.exposeHeaders != "" {
+					rw.Header().Set("Access-Control-Expose-Headers", policy.exposeHeaders)
+				}
+				return h(ctx, rw, req)
+			}
+			rw.Header().Set("Access-Control-Allow-Methods", policy.allowMethods)
+			if policy.allowHeaders != "" {
+				rw.Header().Set("Access-Control-Allow-Headers", policy.allowHeaders)
+			}
+			if policy.maxAge != "" {
+				rw.Header().Set("Access-Control-Max-Age", policy.maxAge)
+			}
+			rw.WriteHeader(http.StatusOK)
+			return nil
+		}
+	}
+}
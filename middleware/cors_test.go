@@ -0,0 +1,185 @@
+package middleware_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("CORS", func() {
+	var opts middleware.CORSOptions
+	var req *http.Request
+	var rw *testResponseWriter
+	var called bool
+
+	BeforeEach(func() {
+		opts = middleware.CORSOptions{
+			AllowedOrigins: []string{"http://*.goa.design"},
+			AllowedMethods: []string{"GET", "PUT"},
+			AllowedHeaders: []string{"Authorization", "Content-Type"},
+			MaxAge:         10 * time.Minute,
+		}
+		req, _ = http.NewRequest("GET", "/bottles", nil)
+		req.Header.Set("Origin", "http://swagger.goa.design")
+		called = false
+	})
+
+	JustBeforeEach(func() {
+		service := newService(nil)
+		service.Use(middleware.CORS(opts))
+		ctrl := service.NewController("bottle")
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return service.Send(ctx, 200, "ok")
+		}
+		service.Mux.Handle("GET", "/bottles", ctrl.MuxHandler("list", h, nil))
+		rw = newTestResponseWriter()
+		service.Mux.ServeHTTP(rw, req)
+	})
+
+	It("adds the allowed origin to responses", func() {
+		Ω(called).Should(BeTrue())
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(Equal("http://swagger.goa.design"))
+		Ω(rw.ParentHeader.Get("Vary")).Should(Equal("Origin"))
+		Ω(rw.ParentHeader.Get("Access-Control-Allow-Methods")).Should(BeEmpty())
+	})
+
+	Context("with a preflight request", func() {
+		BeforeEach(func() {
+			req.Method = "OPTIONS"
+			req.Header.Set("Access-Control-Request-Method", "PUT")
+		})
+
+		It("responds with the CORS headers", func() {
+			Ω(called).Should(BeFalse())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(Equal("http://swagger.goa.design"))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Methods")).Should(Equal("GET, PUT"))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Headers")).Should(Equal("Authorization, Content-Type"))
+			Ω(rw.ParentHeader.Get("Access-Control-Max-Age")).Should(Equal("600"))
+		})
+
+		Context("for a method that is not allowed", func() {
+			BeforeEach(func() {
+				req.Header.Set("Access-Control-Request-Method", "DELETE")
+			})
+
+			It("does not allow the request", func() {
+				Ω(rw.Status).Should(Equal(200))
+				Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(BeEmpty())
+				Ω(rw.ParentHeader.Get("Access-Control-Allow-Methods")).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with an origin that is not allowed", func() {
+		BeforeEach(func() {
+			req.Header.Set("Origin", "http://example.com")
+		})
+
+		It("does not add the CORS headers", func() {
+			Ω(called).Should(BeTrue())
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(BeEmpty())
+			Ω(rw.ParentHeader.Get("Vary")).Should(Equal("Origin"))
+		})
+	})
+
+	Context("allowing all origins", func() {
+		BeforeEach(func() {
+			opts.AllowedOrigins = []string{"*"}
+		})
+
+		It("uses a wildcard", func() {
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(Equal("*"))
+			Ω(rw.ParentHeader.Get("Vary")).Should(BeEmpty())
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Credentials")).Should(BeEmpty())
+		})
+	})
+
+	Context("with credentials", func() {
+		BeforeEach(func() {
+			opts.AllowCredentials = true
+		})
+
+		It("echoes the origin", func() {
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(Equal("http://swagger.goa.design"))
+			Ω(rw.ParentHeader.Get("Vary")).Should(Equal("Origin"))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Credentials")).Should(Equal("true"))
+		})
+	})
+
+	Context("with exposed headers", func() {
+		BeforeEach(func() {
+			opts.ExposedHeaders = []string{"X-Request-Id", "Link"}
+		})
+
+		It("adds them to responses", func() {
+			Ω(called).Should(BeTrue())
+			Ω(rw.ParentHeader.Get("Access-Control-Expose-Headers")).Should(Equal("X-Request-Id, Link"))
+		})
+	})
+})
+
+var _ = Describe("CORS with per-origin options", func() {
+	var req *http.Request
+	var rw *testResponseWriter
+
+	BeforeEach(func() {
+		req, _ = http.NewRequest("OPTIONS", "/bottles", nil)
+		req.Header.Set("Access-Control-Request-Method", "DELETE")
+	})
+
+	JustBeforeEach(func() {
+		service := newService(nil)
+		service.Use(middleware.CORS(middleware.CORSOptions{
+			AllowedOrigins:   []string{"https://admin.goa.design"},
+			AllowedMethods:   []string{"GET", "DELETE"},
+			AllowCredentials: true,
+		}, middleware.CORSOptions{
+			AllowedOrigins: []string{"https://*.goa.design"},
+		}))
+		ctrl := service.NewController("bottle")
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 204, nil)
+		}
+		service.Mux.Handle("DELETE", "/bottles", ctrl.MuxHandler("delete", h, nil))
+		rw = newTestResponseWriter()
+		service.Mux.ServeHTTP(rw, req)
+	})
+
+	Context("from an origin with its own options", func() {
+		BeforeEach(func() {
+			req.Header.Set("Origin", "https://admin.goa.design")
+		})
+
+		It("uses them", func() {
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(Equal("https://admin.goa.design"))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Methods")).Should(Equal("GET, DELETE"))
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Credentials")).Should(Equal("true"))
+		})
+	})
+
+	Context("from another origin", func() {
+		BeforeEach(func() {
+			req.Header.Set("Origin", "https://www.goa.design")
+		})
+
+		It("uses the options matching the origin", func() {
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Origin")).Should(BeEmpty())
+			Ω(rw.ParentHeader.Get("Access-Control-Allow-Credentials")).Should(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("CORS with credentials", func() {
+	It("rejects the wildcard origin", func() {
+		opts := middleware.CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}
+		Ω(func() { middleware.CORS(opts) }).Should(Panic())
+	})
+})
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	// CSPHeader is the name of the header used to transmit the content security policy.
+	CSPHeader = "Content-Security-Policy"
+
+	// CSPNoncePlaceholder is the placeholder replaced with the request nonce in the policies
+	// given to CSPNonce.
+	CSPNoncePlaceholder = "{nonce}"
+
+	// DefaultCSPPolicy is the policy used by CSPNonce when none is given. It only allows
+	// scripts that carry the request nonce.
+	DefaultCSPPolicy = "script-src 'nonce-{nonce}'"
+)
+
+// CSPNonce is a middleware that generates a random nonce for each request and sets the
+// Content-Security-Policy response header to the given policy where all occurrences of
+// CSPNoncePlaceholder are replaced with the nonce. DefaultCSPPolicy is used if policy is empty.
+// Handlers that render HTML retrieve the nonce using ContextCSPNonce and set it on inline scripts:
+//
+//	<script nonce="{{ .Nonce }}">...</script>
+func CSPNonce(policy string) goa.Middleware {
+	if policy == "" {
+		policy = DefaultCSPPolicy
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var buf [16]byte
+			if _, err := rand.Read(buf[:]); err != nil {
+				return err
+			}
+			nonce := base64.StdEncoding.EncodeToString(buf[:])
+			rw.Header().Set(CSPHeader, strings.Replace(policy, CSPNoncePlaceholder, nonce, -1))
+			ctx = context.WithValue(ctx, cspNonceKey, nonce)
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// ContextCSPNonce extracts the nonce generated by the CSPNonce middleware from the context.
+func ContextCSPNonce(ctx context.Context) (nonce string) {
+	if n := ctx.Value(cspNonceKey); n != nil {
+		nonce = n.(string)
+	}
+	return
+}
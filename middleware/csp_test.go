@@ -0,0 +1,51 @@
+package middleware_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("CSPNonce", func() {
+	var policy string
+	var rw *testResponseWriter
+	var nonce string
+
+	BeforeEach(func() {
+		policy = ""
+		nonce = ""
+	})
+
+	JustBeforeEach(func() {
+		service := newService(nil)
+		req, _ := http.NewRequest("GET", "/page", nil)
+		rw = newTestResponseWriter()
+		ctx := newContext(service, rw, req, nil)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			nonce = middleware.ContextCSPNonce(ctx)
+			return service.Send(ctx, 200, "ok")
+		}
+		var m goa.Middleware = middleware.CSPNonce(policy)
+		Ω(m(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+	})
+
+	It("exposes the nonce to the handler and sets the header", func() {
+		Ω(nonce).ShouldNot(BeEmpty())
+		Ω(rw.ParentHeader.Get(middleware.CSPHeader)).Should(Equal("script-src 'nonce-" + nonce + "'"))
+	})
+
+	Context("with a custom policy", func() {
+		BeforeEach(func() {
+			policy = "default-src 'self'; script-src 'nonce-{nonce}'; style-src 'nonce-{nonce}'"
+		})
+
+		It("replaces all the placeholders", func() {
+			Ω(rw.ParentHeader.Get(middleware.CSPHeader)).Should(Equal(
+				"default-src 'self'; script-src 'nonce-" + nonce + "'; style-src 'nonce-" + nonce + "'"))
+		})
+	})
+})
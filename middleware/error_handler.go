@@ -12,7 +12,8 @@ import (
 // ErrorHandler turns a Go error into an HTTP response. It should be placed in the middleware chain
 // below the logger middleware so the logger properly logs the HTTP response. ErrorHandler
 // understands instances of goa.ServiceError and returns the status and response body embodied in
-// them, it turns other Go error types into a 500 internal error response.
+// them, it turns other Go error types into a 500 internal error response. The body of responses
+// produced for goa.MultiError errors lists all the individual errors.
 // If verbose is false the details of internal errors is not included in HTTP responses.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
 func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
@@ -25,7 +26,12 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 			cause := errors.Cause(e)
 			status := http.StatusInternalServerError
 			var respBody interface{}
-			if err, ok := cause.(goa.ServiceError); ok {
+			if merr, ok := cause.(goa.MultiError); ok {
+				status = merr.ResponseStatus()
+				respBody = merr
+				goa.ContextResponse(ctx).ErrorCode = merr.Token()
+				rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier+"; type=collection")
+			} else if err, ok := cause.(goa.ServiceError); ok {
 				status = err.ResponseStatus()
 				respBody = err
 				goa.ContextResponse(ctx).ErrorCode = err.Token()
@@ -126,6 +126,34 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with a handler that panics behind the Recover middleware", func() {
+		BeforeEach(func() {
+			service = newService(nil)
+			h = middleware.Recover()(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				panic("boom")
+			})
+		})
+
+		It("includes the panic stack in the response", func() {
+			Ω(rw.Status).Should(Equal(500))
+			Ω(string(rw.Body)).Should(ContainSubstring("panic: boom"))
+			Ω(string(rw.Body)).Should(ContainSubstring("error_handler_test.go"))
+		})
+
+		Context("not verbose", func() {
+			BeforeEach(func() {
+				verbose = false
+			})
+
+			It("responds with a generic message", func() {
+				Ω(rw.Status).Should(Equal(500))
+				Ω(string(rw.Body)).Should(ContainSubstring("Internal Server Error"))
+				Ω(string(rw.Body)).ShouldNot(ContainSubstring("panic: boom"))
+				Ω(string(rw.Body)).ShouldNot(ContainSubstring("error_handler_test.go"))
+			})
+		})
+	})
+
 	Context("with a handler returning a goa error", func() {
 		var gerr error
 
@@ -147,6 +175,32 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with a handler returning multiple validation errors", func() {
+		var merr goa.MultiError
+
+		BeforeEach(func() {
+			service = newService(nil)
+			merr = goa.MultiError{
+				goa.InvalidAttributeTypeError("payload.name", 1, "string"),
+				goa.MissingAttributeError("payload", "count"),
+			}
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return merr
+			}
+		})
+
+		It("responds with the list of all the errors", func() {
+			var decoded []*errorResponse
+			Ω(rw.Status).Should(Equal(400))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal(goa.ErrorMediaIdentifier + "; type=collection"))
+			err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(HaveLen(2))
+			Ω(decoded[0].Error()).Should(Equal(merr[0].Error()))
+			Ω(decoded[1].Error()).Should(Equal(merr[1].Error()))
+		})
+	})
+
 	Context("with a handler returning a pkg errors wrapped error", func() {
 		var wrappedError error
 		var logger *testLogger
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+type (
+	// FeatureProvider evaluates feature flags. Implementations may key the evaluation on any
+	// property of the request or of the context, for example the authenticated user.
+	FeatureProvider interface {
+		// FeatureEnabled returns true if the feature flag with the given name is enabled
+		// for the request.
+		FeatureEnabled(ctx context.Context, req *http.Request, name string) bool
+	}
+
+	// FeatureProviderFunc is an adapter that makes it possible to use a function as a
+	// FeatureProvider.
+	FeatureProviderFunc func(ctx context.Context, req *http.Request, name string) bool
+)
+
+// FeatureEnabled calls f(ctx, req, name).
+func (f FeatureProviderFunc) FeatureEnabled(ctx context.Context, req *http.Request, name string) bool {
+	return f(ctx, req, name)
+}
+
+// Features is a middleware that makes the feature flags evaluated by the given provider
+// available to actions via the request data Feature method:
+//
+//	if goa.ContextRequest(ctx).Feature("new-checkout") {
+//		// ...
+//	}
+//
+// Flags are evaluated lazily the first time they are looked up and the result is cached for the
+// duration of the request. The provider is given the context as it exists when the middleware
+// runs, mount it after any middleware that sets values the provider depends on.
+func Features(p FeatureProvider) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			reqData := goa.ContextRequest(ctx)
+			if reqData == nil {
+				return h(ctx, rw, req)
+			}
+			var (
+				mu    sync.Mutex
+				flags = make(map[string]bool)
+			)
+			reqData.FeatureFlags = func(name string) bool {
+				mu.Lock()
+				defer mu.Unlock()
+				enabled, ok := flags[name]
+				if !ok {
+					enabled = p.FeatureEnabled(ctx, req, name)
+					flags[name] = enabled
+				}
+				return enabled
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
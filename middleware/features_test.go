@@ -0,0 +1,55 @@
+package middleware_test
+
+import (
+	"net/http"
+
+	"golang.org/x/net/context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Features", func() {
+	var service *goa.Service
+	var req *http.Request
+	var rw http.ResponseWriter
+	var ctx context.Context
+	var calls int
+	var provider middleware.FeatureProvider
+
+	BeforeEach(func() {
+		service = newService(nil)
+		var err error
+		req, err = http.NewRequest("GET", "/goo", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("X-User", "beta")
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		calls = 0
+		provider = middleware.FeatureProviderFunc(func(ctx context.Context, req *http.Request, name string) bool {
+			calls++
+			return name == "new-checkout" && req.Header.Get("X-User") == "beta"
+		})
+	})
+
+	It("exposes the flags evaluated by the provider to the action", func() {
+		var enabled, other bool
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			r := goa.ContextRequest(ctx)
+			enabled = r.Feature("new-checkout")
+			other = r.Feature("other")
+			r.Feature("new-checkout")
+			return service.Send(ctx, 200, "ok")
+		}
+		Ω(middleware.Features(provider)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(enabled).Should(BeTrue())
+		Ω(other).Should(BeFalse())
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("reports flags as disabled when no provider is mounted", func() {
+		Ω(goa.ContextRequest(ctx).Feature("new-checkout")).Should(BeFalse())
+	})
+})
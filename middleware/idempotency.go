@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/goadesign/goa"
+
+	"golang.org/x/net/context"
+)
+
+// IdempotencyKeyHeader is the name of the header clients use to identify retries of a request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// ErrIdempotencyConflict is the error returned when a request is made with an idempotency key
+// while another request with the same key is still being handled.
+var ErrIdempotencyConflict = goa.NewErrorClass("idempotency_conflict", 409)
+
+type (
+	// IdempotencyStore is the interface implemented by the stores used by the Idempotent
+	// middleware to cache responses.
+	IdempotencyStore interface {
+		// Get returns the response cached for the given key if any.
+		Get(key string) (*CachedResponse, bool)
+		// Set caches the response for the given key.
+		Set(key string, resp *CachedResponse)
+	}
+
+	// CachedResponse is a response recorded by the Idempotent middleware.
+	CachedResponse struct {
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+	}
+
+	// memoryIdempotencyStore is an IdempotencyStore that keeps responses in memory.
+	memoryIdempotencyStore struct {
+		sync.RWMutex
+		responses map[string]*CachedResponse
+	}
+
+	// recordingResponseWriter wraps an http.ResponseWriter and records the response status,
+	// header and body.
+	recordingResponseWriter struct {
+		http.ResponseWriter
+		status int
+		header http.Header
+		body   bytes.Buffer
+	}
+)
+
+// NewMemoryIdempotencyStore returns an IdempotencyStore that keeps responses in memory. The
+// store does not expire responses and is thus only suitable for services handling a bounded
+// number of keys or for tests.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{responses: make(map[string]*CachedResponse)}
+}
+
+// Idempotent creates a middleware that makes the actions declared idempotent in the design safe
+// to retry (see the Idempotent DSL). Requests that carry the IdempotencyKeyHeader header are
+// handled once, the response is cached in store and replayed for subsequent requests made to the
+// same action with the same key. Requests made with a key that is being handled concurrently fail
+// with ErrIdempotencyConflict. Requests made to other actions are left untouched.
+func Idempotent(store IdempotencyStore) goa.Middleware {
+	var mu sync.Mutex
+	inflight := make(map[string]bool)
+
+	idempotent := func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := req.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return h(ctx, rw, req)
+			}
+			key = goa.ContextController(ctx) + "." + goa.ContextAction(ctx) + ":" + key
+			resp := goa.ContextResponse(ctx)
+			if cached, ok := store.Get(key); ok {
+				return cached.replay(resp)
+			}
+
+			mu.Lock()
+			if inflight[key] {
+				mu.Unlock()
+				msg := fmt.Sprintf("a request with idempotency key %#v is already in progress", req.Header.Get(IdempotencyKeyHeader))
+				return ErrIdempotencyConflict(msg)
+			}
+			inflight[key] = true
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				delete(inflight, key)
+				mu.Unlock()
+			}()
+			// A request with the same key may have completed between the lookup above and
+			// the registration of this one.
+			if cached, ok := store.Get(key); ok {
+				return cached.replay(resp)
+			}
+
+			rec := &recordingResponseWriter{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(rec)
+			err := h(ctx, rw, req)
+			if err == nil && rec.header != nil && rec.status < 500 {
+				store.Set(key, rec.response())
+			}
+			return err
+		}
+	}
+
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return h(goa.WithIdempotency(ctx, idempotent), rw, req)
+		}
+	}
+}
+
+// WriteHeader records the status code and the response header and calls the underlying writer.
+func (w *recordingResponseWriter) WriteHeader(status int) {
+	w.record(status)
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the data and writes it to the underlying writer.
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	w.record(http.StatusOK)
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// record records the response status code and header the first time it is called. The header
+// is copied before the underlying writer gets a chance to alter it, the compression of the
+// service responses for example sets the Content-Encoding header of the response it compresses
+// but the recorded body is not compressed. The Vary and Server-Timing headers are left out as
+// they describe how the response was produced for the recorded request.
+func (w *recordingResponseWriter) record(status int) {
+	if w.header != nil {
+		return
+	}
+	w.status = status
+	w.header = make(http.Header, len(w.Header()))
+	for k, v := range w.Header() {
+		if k == "Vary" || k == "Server-Timing" {
+			continue
+		}
+		w.header[k] = append([]string(nil), v...)
+	}
+}
+
+// response returns the recorded response.
+func (w *recordingResponseWriter) response() *CachedResponse {
+	return &CachedResponse{Status: w.status, Header: w.header, Body: w.body.Bytes()}
+}
+
+// replay writes the cached response to resp.
+func (c *CachedResponse) replay(resp *goa.ResponseData) error {
+	for k, v := range c.Header {
+		resp.Header()[k] = append([]string(nil), v...)
+	}
+	resp.WriteHeader(c.Status)
+	_, err := resp.Write(c.Body)
+	return err
+}
+
+// Get returns the response cached for the given key if any.
+func (s *memoryIdempotencyStore) Get(key string) (*CachedResponse, bool) {
+	s.RLock()
+	defer s.RUnlock()
+	resp, ok := s.responses[key]
+	return resp, ok
+}
+
+// Set caches the response for the given key.
+func (s *memoryIdempotencyStore) Set(key string, resp *CachedResponse) {
+	s.Lock()
+	defer s.Unlock()
+	s.responses[key] = resp
+}
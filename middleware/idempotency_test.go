@@ -0,0 +1,134 @@
+package middleware_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Idempotent", func() {
+	var service *goa.Service
+	var store middleware.IdempotencyStore
+	var calls int
+	var started, block chan struct{}
+	var h, handler goa.Handler
+
+	newRequest := func() (context.Context, *testResponseWriter, *http.Request) {
+		req, err := http.NewRequest("POST", "/payments", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set(middleware.IdempotencyKeyHeader, "abc")
+		rw := newTestResponseWriter()
+		ctx := goa.WithAction(newContext(service, rw, req, nil), "create")
+		return ctx, rw, req
+	}
+
+	BeforeEach(func() {
+		service = newService(nil)
+		store = middleware.NewMemoryIdempotencyStore()
+		calls = 0
+		started, block = nil, nil
+		h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			calls++
+			if block != nil {
+				started <- struct{}{}
+				<-block
+			}
+			rw.Header().Set("Location", "/payments/1")
+			return service.Send(ctx, 201, map[string]interface{}{"call": calls})
+		}
+		handler = middleware.Idempotent(store)(goa.IdempotentAction(h))
+	})
+
+	It("handles the first request", func() {
+		ctx, rw, req := newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Status).Should(Equal(201))
+		Ω(string(rw.Body)).Should(Equal(`{"call":1}` + "\n"))
+	})
+
+	It("handles every request made to actions not declared idempotent", func() {
+		handler = middleware.Idempotent(store)(h)
+		ctx, rw, req := newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		ctx, rw, req = newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(2))
+		Ω(string(rw.Body)).Should(Equal(`{"call":2}` + "\n"))
+	})
+
+	It("replays the response of retries", func() {
+		ctx, rw, req := newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		ctx, rw, req = newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Status).Should(Equal(201))
+		Ω(string(rw.Body)).Should(Equal(`{"call":1}` + "\n"))
+		Ω(rw.ParentHeader.Get("Location")).Should(Equal("/payments/1"))
+	})
+
+	It("rejects concurrent requests with the same key", func() {
+		started, block = make(chan struct{}), make(chan struct{})
+		done := make(chan error)
+		go func() {
+			ctx, rw, req := newRequest()
+			done <- handler(ctx, rw, req)
+		}()
+		<-started
+		ctx, rw, req := newRequest()
+		err := handler(ctx, rw, req)
+		close(block)
+		Ω(<-done).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(*goa.ErrorResponse).Status).Should(Equal(409))
+	})
+
+	It("replays responses stored while the request was being registered", func() {
+		racing := &racingStore{IdempotencyStore: store}
+		handler = middleware.Idempotent(racing)(goa.IdempotentAction(h))
+		racing.after = func() {
+			ctx, rw, req := newRequest()
+			Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		}
+		ctx, rw, req := newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Status).Should(Equal(201))
+		Ω(string(rw.Body)).Should(Equal(`{"call":1}` + "\n"))
+	})
+
+	It("does not replay the header set below the middleware", func() {
+		ctx, rw, req := newRequest()
+		resp := goa.ContextResponse(ctx)
+		resp.SwitchWriter(&encodingWriter{ResponseWriter: resp.SwitchWriter(nil)})
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(rw.ParentHeader.Get("Content-Encoding")).Should(Equal("gzip"))
+		ctx, rw, req = newRequest()
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw.ParentHeader.Get("Content-Encoding")).Should(BeEmpty())
+		Ω(rw.ParentHeader.Get("Location")).Should(Equal("/payments/1"))
+	})
+})
+
+// racingStore is an idempotency store that calls after once the first lookup completes to
+// simulate a concurrent request completing right after it.
+type racingStore struct {
+	middleware.IdempotencyStore
+	after func()
+}
+
+func (s *racingStore) Get(key string) (*middleware.CachedResponse, bool) {
+	resp, ok := s.IdempotencyStore.Get(key)
+	if after := s.after; after != nil {
+		s.after = nil
+		after()
+	}
+	return resp, ok
+}
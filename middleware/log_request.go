@@ -20,8 +20,23 @@ import (
 // request ID for logging.
 // If verbose is true then the middlware logs the request and response bodies.
 func LogRequest(verbose bool) goa.Middleware {
+	return LogRequestExcept(verbose)
+}
+
+// LogRequestExcept creates a request logger middleware that behaves like LogRequest but does
+// not log requests made to the given actions. This is useful to silence health checks and other
+// high volume endpoints. Actions are identified by their controller and action names separated
+// by a dot, for example "health.check".
+func LogRequestExcept(verbose bool, silent ...string) goa.Middleware {
+	silenced := make(map[string]bool, len(silent))
+	for _, a := range silent {
+		silenced[a] = true
+	}
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if silenced[goa.ContextController(ctx)+"."+goa.ContextAction(ctx)] {
+				return h(ctx, rw, req)
+			}
 			reqID := ctx.Value(reqIDKey)
 			if reqID == nil {
 				reqID = shortID()
@@ -96,11 +96,33 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[1].Data[4]).Should(Equal("error"))
 		Ω(logger.InfoEntries[1].Data[5]).Should(HaveLen(8)) // Error ID
 		Ω(logger.InfoEntries[1].Data[6]).Should(Equal("bytes"))
-		Ω(logger.InfoEntries[1].Data[7]).Should(Equal(126))
+		Ω(logger.InfoEntries[1].Data[7]).Should(Equal(218))
 		Ω(logger.InfoEntries[1].Data[8]).Should(Equal("time"))
 		Ω(logger.InfoEntries[1].Data[10]).Should(Equal("ctrl"))
 		Ω(logger.InfoEntries[1].Data[11]).Should(Equal("test"))
 		Ω(logger.InfoEntries[1].Data[12]).Should(Equal("action"))
 		Ω(logger.InfoEntries[1].Data[13]).Should(Equal("<unknown>"))
 	})
+	Context("with silenced actions", func() {
+		var lg goa.Handler
+
+		BeforeEach(func() {
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return service.Send(ctx, 200, "ok")
+			}
+			lg = middleware.LogRequestExcept(false, "test.health")(h)
+		})
+
+		It("does not log requests made to silenced actions", func() {
+			ctx = goa.WithAction(ctx, "health")
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logger.InfoEntries).Should(BeEmpty())
+		})
+
+		It("logs requests made to other actions", func() {
+			ctx = goa.WithAction(ctx, "goo")
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logger.InfoEntries).Should(HaveLen(2))
+		})
+	})
 })
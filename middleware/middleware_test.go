@@ -72,3 +72,14 @@ func (t *testResponseWriter) Write(b []byte) (int, error) {
 func (t *testResponseWriter) WriteHeader(s int) {
 	t.Status = s
 }
+
+// encodingWriter mimics the response compression by setting the Content-Encoding header when the
+// response header is written.
+type encodingWriter struct {
+	http.ResponseWriter
+}
+
+func (w *encodingWriter) WriteHeader(status int) {
+	w.Header().Set("Content-Encoding", "gzip")
+	w.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/goadesign/goa"
+
+	"golang.org/x/net/context"
+)
+
+// ParamsFromBody is a middleware that loads the request parameters from a JSON object sent in the
+// request body. This makes it possible to implement actions that are semantically reads but that
+// accept complex inputs via POST (e.g. search endpoints). The generated code then coerces and
+// validates the parameters exactly as if they had been given in the query string.
+//
+// Scalar members (strings, numbers and booleans) set the parameter with the same name while arrays
+// of scalar values set one value per element. Nested objects are flattened: the names of their
+// members are prefixed with the name of the parent object followed by a dot, for example
+// {"filters":{"status":"open"}} sets the parameter "filters.status".
+//
+// Parameters given in the request path or query string take precedence over the body members.
+// Only requests with a JSON content type are considered. The actions using this middleware must
+// not define a payload as the request body is consumed.
+func ParamsFromBody() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.Body == nil || !strings.Contains(req.Header.Get("Content-Type"), "json") {
+				return h(ctx, rw, req)
+			}
+			var body map[string]interface{}
+			dec := json.NewDecoder(req.Body)
+			dec.UseNumber()
+			if err := dec.Decode(&body); err != nil {
+				return goa.ErrBadRequest(fmt.Sprintf("failed to load parameters from request body: %s", err))
+			}
+			reqData := goa.ContextRequest(ctx)
+			if reqData.Params == nil {
+				reqData.Params = make(url.Values)
+			}
+			loaded := make(url.Values)
+			if err := flattenParams("", body, loaded); err != nil {
+				return goa.ErrBadRequest(err)
+			}
+			for name, vals := range loaded {
+				if _, ok := reqData.Params[name]; !ok {
+					reqData.Params[name] = vals
+				}
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// flattenParams records the members of obj in params, prefixing the names with prefix.
+func flattenParams(prefix string, obj map[string]interface{}, params url.Values) error {
+	for name, val := range obj {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		switch actual := val.(type) {
+		case nil:
+		case map[string]interface{}:
+			if err := flattenParams(name, actual, params); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, elem := range actual {
+				s, ok := scalarParam(elem)
+				if !ok {
+					return fmt.Errorf("invalid value for parameter %#v, arrays may only contain scalar values", name)
+				}
+				params.Add(name, s)
+			}
+		default:
+			s, ok := scalarParam(actual)
+			if !ok {
+				return fmt.Errorf("invalid value for parameter %#v", name)
+			}
+			params.Set(name, s)
+		}
+	}
+	return nil
+}
+
+// scalarParam returns the string representation of a scalar JSON value.
+func scalarParam(val interface{}) (string, bool) {
+	switch actual := val.(type) {
+	case string:
+		return actual, true
+	case json.Number:
+		return actual.String(), true
+	case bool:
+		if actual {
+			return "true", true
+		}
+		return "false", true
+	}
+	return "", false
+}
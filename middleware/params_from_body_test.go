@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("ParamsFromBody", func() {
+	var body string
+	var params url.Values
+	var loaded url.Values
+	var err error
+
+	BeforeEach(func() {
+		params = url.Values{"limit": {"5"}}
+		loaded = nil
+	})
+
+	JustBeforeEach(func() {
+		service := newService(nil)
+		req, _ := http.NewRequest("POST", "/search", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rw := newTestResponseWriter()
+		ctx := newContext(service, rw, req, params)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			loaded = goa.ContextRequest(ctx).Params
+			return nil
+		}
+		err = middleware.ParamsFromBody()(h)(ctx, rw, req)
+	})
+
+	Context("with a search body", func() {
+		BeforeEach(func() {
+			body = `{"filters":{"status":"open","min":2.5},"tags":["a","b"],"limit":10,"all":true}`
+		})
+
+		It("populates the params", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(loaded.Get("filters.status")).Should(Equal("open"))
+			Ω(loaded.Get("filters.min")).Should(Equal("2.5"))
+			Ω(loaded["tags"]).Should(Equal([]string{"a", "b"}))
+			Ω(loaded.Get("all")).Should(Equal("true"))
+		})
+
+		It("gives precedence to the query string params", func() {
+			Ω(loaded.Get("limit")).Should(Equal("5"))
+		})
+	})
+
+	Context("with an array of objects", func() {
+		BeforeEach(func() {
+			body = `{"tags":[{"name":"a"}]}`
+		})
+
+		It("returns a bad request error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+		})
+	})
+})
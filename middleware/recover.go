@@ -11,7 +11,9 @@ import (
 	"golang.org/x/net/context"
 )
 
-// Recover is a middleware that recovers panics and maps them to errors.
+// Recover is a middleware that recovers panics and maps them to errors. The error message includes
+// the stack trace of the panic. Mount the ErrorHandler middleware with verbose set to false to
+// prevent the stack trace from being sent to clients and use a generic message instead.
 func Recover() goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
@@ -41,7 +41,7 @@ func init() {
 }
 
 // RequestIDWithHeader behaves like the middleware RequestID, but it takes the request id header
-// as the (first) argument.
+// as the (first) argument. The same header is used to echo the ID back in the response.
 func RequestIDWithHeader(requestIDHeader string) goa.Middleware {
 	return RequestIDWithHeaderAndLengthLimit(requestIDHeader, DefaultRequestIDLengthLimit)
 }
@@ -59,6 +59,10 @@ func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int)
 				id = id[:lengthLimit]
 			}
 			ctx = context.WithValue(ctx, reqIDKey, id)
+			if reqData := goa.ContextRequest(ctx); reqData != nil {
+				reqData.RequestID = id
+			}
+			rw.Header().Set(requestIDHeader, id)
 
 			return h(ctx, rw, req)
 		}
@@ -66,8 +70,9 @@ func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int)
 }
 
 // RequestID is a middleware that injects a request ID into the context of each request.
-// Retrieve it using ctx.Value(ReqIDKey). If the incoming request has a RequestIDHeader header then
-// that value is used else a random value is generated.
+// Retrieve it using ContextRequestID or the RequestID field of the request data. If the incoming
+// request has a RequestIDHeader header then that value is used else a random value is generated.
+// The ID is echoed back to the client in the response RequestIDHeader header.
 func RequestID() goa.Middleware {
 	return RequestIDWithHeader(RequestIDHeader)
 }
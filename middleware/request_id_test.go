@@ -27,7 +27,7 @@ var _ = Describe("RequestID", func() {
 		req, err = http.NewRequest("GET", "/goo", nil)
 		Ω(err).ShouldNot(HaveOccurred())
 		req.Header.Set(middleware.RequestIDHeader, reqID)
-		rw = new(testResponseWriter)
+		rw = newTestResponseWriter()
 		params = url.Values{"query": []string{"value"}}
 		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
 		ctx = newContext(service, rw, req, params)
@@ -44,6 +44,19 @@ var _ = Describe("RequestID", func() {
 		Ω(middleware.ContextRequestID(newCtx)).Should(Equal(reqID))
 	})
 
+	It("exposes the request ID to the action and echoes it in the response", func() {
+		var id string
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			id = goa.ContextRequest(ctx).RequestID
+			return service.Send(ctx, 200, "ok")
+		}
+		req.Header.Del(middleware.RequestIDHeader)
+		rg := middleware.RequestID()(h)
+		Ω(rg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(id).ShouldNot(BeEmpty())
+		Ω(rw.Header().Get(middleware.RequestIDHeader)).Should(Equal(id))
+	})
+
 	It("truncates request ID when it exceeds a default limit", func() {
 		var newCtx context.Context
 		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
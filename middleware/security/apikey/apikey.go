@@ -0,0 +1,48 @@
+package apikey
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+// ErrAPIKeyAuthFailed is the error returned when the request does not carry a valid API key.
+var ErrAPIKeyAuthFailed = goa.NewErrorClass("api_key_auth_failed", 401)
+
+// Verifier is the function used to verify API keys. It should return an error if the key is not
+// valid. Errors created with a goa error class are returned as is by the middleware, other errors
+// are wrapped in a ErrAPIKeyAuthFailed error.
+type Verifier func(ctx context.Context, key string) error
+
+// New creates a middleware that enforces the presence of the API key described by scheme. It
+// reads the key from the header or query string parameter named by the scheme and returns a 401
+// error if the key is absent. Present keys are checked by calling verify which is responsible for
+// the actual authentication.
+//
+// Example:
+//
+//	app.UseAPIKeyMiddleware(service, apikey.New(app.NewAPIKeySecurity(), verifyKey))
+func New(scheme *goa.APIKeySecurity, verify Verifier) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var key string
+			if scheme.In == goa.LocQuery {
+				key = req.URL.Query().Get(scheme.Name)
+			} else {
+				key = req.Header.Get(scheme.Name)
+			}
+			if key == "" {
+				return ErrAPIKeyAuthFailed(fmt.Sprintf("missing API key %#v in %s", scheme.Name, scheme.In))
+			}
+			if err := verify(ctx, key); err != nil {
+				if _, ok := err.(goa.ServiceError); !ok {
+					err = ErrAPIKeyAuthFailed(err)
+				}
+				return err
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package apikey_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Middleware", func() {
+	var securityScheme *goa.APIKeySecurity
+	var request *http.Request
+	var called bool
+	var verifiedKey string
+	var dispatchResult error
+
+	BeforeEach(func() {
+		securityScheme = &goa.APIKeySecurity{In: goa.LocHeader, Name: "X-Api-Key"}
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+		called = false
+		verifiedKey = ""
+	})
+
+	JustBeforeEach(func() {
+		verify := func(ctx context.Context, key string) error {
+			verifiedKey = key
+			if key == "revoked" {
+				return goa.ErrUnauthorized("revoked key")
+			}
+			if key != "secret" {
+				return errors.New("invalid key")
+			}
+			return nil
+		}
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		middleware := apikey.New(securityScheme, verify)
+		dispatchResult = middleware(handler)(context.Background(), httptest.NewRecorder(), request)
+	})
+
+	It("rejects requests without credentials", func() {
+		Ω(dispatchResult).Should(HaveOccurred())
+		Ω(dispatchResult.(*goa.ErrorResponse).Status).Should(Equal(401))
+		Ω(called).Should(BeFalse())
+	})
+
+	Context("with a valid key", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-Api-Key", "secret")
+		})
+
+		It("calls the handler", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(verifiedKey).Should(Equal("secret"))
+			Ω(called).Should(BeTrue())
+		})
+	})
+
+	Context("with an invalid key", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-Api-Key", "guess")
+		})
+
+		It("wraps the verifier error", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(*goa.ErrorResponse).Status).Should(Equal(401))
+			Ω(dispatchResult.(*goa.ErrorResponse).Code).Should(Equal("api_key_auth_failed"))
+			Ω(dispatchResult.Error()).Should(ContainSubstring("invalid key"))
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with a key rejected with a service error", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-Api-Key", "revoked")
+		})
+
+		It("returns the verifier error", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(*goa.ErrorResponse).Code).Should(Equal("unauthorized"))
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with a key in the query string", func() {
+		BeforeEach(func() {
+			securityScheme.In = goa.LocQuery
+			securityScheme.Name = "key"
+			request.URL.RawQuery = "key=secret"
+		})
+
+		It("calls the handler", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(called).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,40 @@
+package bearer
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"golang.org/x/net/context"
+)
+
+// ErrBearerAuthFailed is the error returned when the request does not carry a valid bearer token.
+var ErrBearerAuthFailed = goa.NewErrorClass("bearer_auth_failed", 401)
+
+// Validator is the function used to validate bearer tokens, for example by verifying a JWT
+// signature or by looking up an opaque token. It should return an error if the token is not
+// valid.
+type Validator func(ctx context.Context, token string) error
+
+// New creates a middleware that requires requests to carry a bearer token in the Authorization
+// header as described in RFC 6750. Requests with a missing or malformed header and requests whose
+// token is rejected by validate fail with a 401 error and a WWW-Authenticate response header.
+//
+// Example:
+//
+//	app.UseOAuth2Middleware(service, bearer.New(validateToken))
+func New(validate Validator) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			token, ok := (&goa.RequestData{Request: req}).BearerToken()
+			if !ok {
+				rw.Header().Set("WWW-Authenticate", "Bearer")
+				return ErrBearerAuthFailed("missing or malformed bearer token")
+			}
+			if err := validate(ctx, token); err != nil {
+				rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				return ErrBearerAuthFailed(err)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
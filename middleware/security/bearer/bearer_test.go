@@ -0,0 +1,99 @@
+package bearer_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/bearer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("Middleware", func() {
+	var request *http.Request
+	var respRecord *httptest.ResponseRecorder
+	var called bool
+	var dispatchResult error
+	var goaContext bool
+
+	BeforeEach(func() {
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+		respRecord = httptest.NewRecorder()
+		called = false
+		goaContext = true
+	})
+
+	JustBeforeEach(func() {
+		validate := func(ctx context.Context, token string) error {
+			if token != "valid" {
+				return errors.New("unknown token")
+			}
+			return nil
+		}
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		ctx := context.Background()
+		if goaContext {
+			ctx = goa.NewContext(ctx, respRecord, request, nil)
+		}
+		dispatchResult = bearer.New(validate)(handler)(ctx, respRecord, request)
+	})
+
+	It("rejects requests without a token", func() {
+		Ω(dispatchResult).Should(HaveOccurred())
+		Ω(dispatchResult.(*goa.ErrorResponse).Status).Should(Equal(401))
+		Ω(respRecord.Header().Get("WWW-Authenticate")).Should(Equal("Bearer"))
+		Ω(called).Should(BeFalse())
+	})
+
+	Context("with a malformed header", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(respRecord.Header().Get("WWW-Authenticate")).Should(Equal("Bearer"))
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with an invalid token", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer forged")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(respRecord.Header().Get("WWW-Authenticate")).Should(Equal(`Bearer error="invalid_token"`))
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with a valid token", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer valid")
+		})
+
+		It("calls the handler", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(called).Should(BeTrue())
+		})
+
+		Context("and a context that is not a goa context", func() {
+			BeforeEach(func() {
+				goaContext = false
+			})
+
+			It("reads the token from the request", func() {
+				Ω(dispatchResult).ShouldNot(HaveOccurred())
+				Ω(called).Should(BeTrue())
+			})
+		})
+	})
+})
@@ -0,0 +1,312 @@
+package goa
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultMaxMultipartMemory is the default maximum number of bytes of multipart request bodies
+// kept in memory when decoding payloads, see Service.MaxMultipartMemory.
+const DefaultMaxMultipartMemory int64 = 32 << 20 // 32MB
+
+type (
+	// Part is a part of a multipart response, see Service.RespondMultipart.
+	Part struct {
+		// ID identifies the part, PartNamingID uses it as the part name.
+		ID string
+		// ContentType is the part content type, defaults to application/octet-stream.
+		ContentType string
+		// Body is the part content.
+		Body []byte
+	}
+
+	// PartNaming computes the name of the i-th part of a multipart response.
+	PartNaming func(i int, p *Part) string
+)
+
+// PartNamingID names the parts of multipart responses after their ID.
+func PartNamingID(_ int, p *Part) string { return p.ID }
+
+// PartNamingIndex names the parts of multipart responses after their position in the response
+// starting at 0.
+func PartNamingIndex(i int, _ *Part) string { return strconv.Itoa(i) }
+
+// quoteEscaper escapes the part names written in the Content-Disposition headers.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+var (
+	fileHeaderType    = reflect.TypeOf(multipart.FileHeader{})
+	fileHeaderPtrType = reflect.TypeOf(&multipart.FileHeader{})
+	textUnmarshalerT  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RespondMultipart sends a multipart/form-data response made of the given parts in order, for
+// example to return the results of a bulk operation. The name of each part is computed by naming,
+// parts are named after their ID if naming is nil. Use PartNamingIndex to name the parts after
+// their position or any function to implement a custom scheme.
+func (service *Service) RespondMultipart(ctx context.Context, code int, parts []*Part, naming PartNaming) error {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return fmt.Errorf("no response data in context")
+	}
+	if naming == nil {
+		naming = PartNamingID
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for i, p := range parts {
+		ct := p.ContentType
+		if ct == "" {
+			ct = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(naming(i, p))))
+		h.Set("Content-Type", ct)
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(p.Body); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	return service.RespondBytes(ctx, code, mw.FormDataContentType(), buf.Bytes())
+}
+
+// decodeMultipart parses the multipart/form-data body of req and loads the form values and
+// uploaded files into v. v must be a pointer to a struct, a map with string keys or an empty
+// interface. Struct fields are matched with the form part names using their "form" tag, their
+// "json" tag or their name in this order. Fields of type multipart.FileHeader or
+// []multipart.FileHeader, or pointers to these, receive the uploaded files. The values loaded in
+// maps are coerced to the map element type and the values loaded in struct or map fields are
+// decoded from JSON.
+func (service *Service) decodeMultipart(req *http.Request, v interface{}) error {
+	max := service.MaxMultipartMemory
+	if max <= 0 {
+		max = DefaultMaxMultipartMemory
+	}
+	if err := req.ParseMultipartForm(max); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("cannot decode multipart form into non pointer %T", v)
+	}
+	return loadMultipart(rv.Elem(), req.MultipartForm)
+}
+
+// loadMultipart loads the values and files of form into v.
+func loadMultipart(v reflect.Value, form *multipart.Form) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return loadMultipart(v.Elem(), form)
+	case reflect.Map:
+		if t := v.Type(); t.Key().Kind() == reflect.String && t.Elem().Kind() != reflect.Interface {
+			return loadMultipartMap(v, form)
+		}
+		fallthrough
+	case reflect.Interface:
+		m := make(map[string]interface{}, len(form.Value)+len(form.File))
+		for n, vals := range form.Value {
+			if len(vals) == 1 {
+				m[n] = vals[0]
+			} else {
+				m[n] = vals
+			}
+		}
+		for n, files := range form.File {
+			if len(files) == 1 {
+				m[n] = files[0]
+			} else {
+				m[n] = files
+			}
+		}
+		mv := reflect.ValueOf(m)
+		if !mv.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("cannot decode multipart form into %s", v.Type())
+		}
+		v.Set(mv)
+		return nil
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue // unexported
+			}
+			name := formFieldName(sf)
+			if name == "-" {
+				continue
+			}
+			if files := form.File[name]; len(files) > 0 {
+				if err := setFiles(v.Field(i), files); err != nil {
+					return fmt.Errorf("field %s: %s", name, err)
+				}
+			} else if vals := form.Value[name]; len(vals) > 0 {
+				if err := setValues(v.Field(i), vals); err != nil {
+					return fmt.Errorf("field %s: %s", name, err)
+				}
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot decode multipart form into %s", v.Type())
+}
+
+// loadMultipartMap loads the values and files of form into the map v whose keys are strings. Each
+// value is coerced to the map element type, e.g. a form field "count" with value "2" produces the
+// element 2 of a map[string]int.
+func loadMultipartMap(v reflect.Value, form *multipart.Form) error {
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, len(form.Value)+len(form.File))
+	for n, vals := range form.Value {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := setValues(elem, vals); err != nil {
+			return fmt.Errorf("field %s: %s", n, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(n).Convert(t.Key()), elem)
+	}
+	for n, files := range form.File {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := setFiles(elem, files); err != nil {
+			return fmt.Errorf("field %s: %s", n, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(n).Convert(t.Key()), elem)
+	}
+	v.Set(m)
+	return nil
+}
+
+// formFieldName returns the name of the form part loaded into the given struct field.
+func formFieldName(sf reflect.StructField) string {
+	for _, key := range []string{"form", "json"} {
+		if tag := sf.Tag.Get(key); tag != "" {
+			if name := strings.Split(tag, ",")[0]; name != "" {
+				return name
+			}
+		}
+	}
+	return sf.Name
+}
+
+// setFiles stores the uploaded files in f.
+func setFiles(f reflect.Value, files []*multipart.FileHeader) error {
+	switch f.Type() {
+	case fileHeaderPtrType:
+		f.Set(reflect.ValueOf(files[0]))
+		return nil
+	case fileHeaderType:
+		f.Set(reflect.ValueOf(*files[0]))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Ptr:
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		return setFiles(f.Elem(), files)
+	case reflect.Interface:
+		if len(files) == 1 {
+			f.Set(reflect.ValueOf(files[0]))
+		} else {
+			f.Set(reflect.ValueOf(files))
+		}
+		return nil
+	case reflect.Slice:
+		elems := reflect.MakeSlice(f.Type(), len(files), len(files))
+		for i, file := range files {
+			if err := setFiles(elems.Index(i), []*multipart.FileHeader{file}); err != nil {
+				return err
+			}
+		}
+		f.Set(elems)
+		return nil
+	}
+	return fmt.Errorf("cannot store uploaded files in %s", f.Type())
+}
+
+// setValues stores the form values in f.
+func setValues(f reflect.Value, vals []string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+		elems := reflect.MakeSlice(f.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(elems.Index(i), val); err != nil {
+				return err
+			}
+		}
+		f.Set(elems)
+		return nil
+	}
+	return setValue(f, vals[0])
+}
+
+// setValue parses val and stores the result in f.
+func setValue(f reflect.Value, val string) error {
+	if f.Kind() == reflect.Ptr {
+		v := reflect.New(f.Type().Elem())
+		if err := setValue(v.Elem(), val); err != nil {
+			return err
+		}
+		f.Set(v)
+		return nil
+	}
+	if reflect.PtrTo(f.Type()).Implements(textUnmarshalerT) {
+		return f.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(val)
+	case reflect.Interface:
+		f.Set(reflect.ValueOf(val))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(val, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(fl)
+	case reflect.Map, reflect.Struct:
+		// Objects and hashes given as a form value are JSON encoded.
+		dec := json.NewDecoder(strings.NewReader(val))
+		dec.UseNumber()
+		if err := dec.Decode(f.Addr().Interface()); err != nil {
+			return fmt.Errorf("cannot store form value in %s: %s", f.Type(), err)
+		}
+	default:
+		return fmt.Errorf("cannot store form value in %s", f.Type())
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package goa_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("multipart payloads", func() {
+	type avatarPayload struct {
+		Name   *string                 `form:"name,omitempty" json:"name,omitempty"`
+		Size   *int                    `form:"size,omitempty" json:"size,omitempty"`
+		Avatar *multipart.FileHeader   `form:"avatar,omitempty" json:"avatar,omitempty"`
+		Extras []*multipart.FileHeader `form:"extras,omitempty" json:"extras,omitempty"`
+	}
+
+	type upload struct {
+		field, filename, content string
+	}
+
+	var service *goa.Service
+	var values map[string]string
+	var uploads []upload
+	var payload interface{}
+	var rw *TestResponseWriter
+	var decodeErr error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.MaxMultipartMemory = 16
+		values = map[string]string{"name": "Vincent"}
+		uploads = []upload{{"avatar", "avatar.png", "avatar image"}}
+		payload = &avatarPayload{}
+		decodeErr = nil
+	})
+
+	JustBeforeEach(func() {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for n, v := range values {
+			mw.WriteField(n, v)
+		}
+		for _, u := range uploads {
+			w, err := mw.CreateFormFile(u.field, u.filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			w.Write([]byte(u.content))
+		}
+		mw.Close()
+		req, _ := http.NewRequest("POST", "/avatars", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+			decodeErr = service.DecodeRequest(req, payload)
+			return decodeErr
+		}
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if err := goa.ContextError(ctx); err != nil {
+				rw.WriteHeader(400)
+				return nil
+			}
+			rw.WriteHeader(201)
+			return nil
+		}
+		service.NewController("avatar").MuxHandler("upload", handler, unmarshaler)(rw, req, nil)
+	})
+
+	readFile := func(fh *multipart.FileHeader) string {
+		f, err := fh.Open()
+		Ω(err).ShouldNot(HaveOccurred())
+		defer f.Close()
+		b, err := ioutil.ReadAll(f)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("loads the form values and the uploaded file", func() {
+		Ω(decodeErr).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(201))
+		p := payload.(*avatarPayload)
+		Ω(p.Name).ShouldNot(BeNil())
+		Ω(*p.Name).Should(Equal("Vincent"))
+		Ω(p.Size).Should(BeNil())
+		Ω(p.Avatar).ShouldNot(BeNil())
+		Ω(p.Avatar.Filename).Should(Equal("avatar.png"))
+		Ω(readFile(p.Avatar)).Should(Equal("avatar image"))
+	})
+
+	Context("with multiple files", func() {
+		BeforeEach(func() {
+			values["size"] = "2"
+			uploads = append(uploads,
+				upload{"extras", "one.txt", "one"},
+				upload{"extras", "two.txt", "a file larger than the memory limit"},
+			)
+		})
+
+		It("loads all the files", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			p := payload.(*avatarPayload)
+			Ω(*p.Size).Should(Equal(2))
+			Ω(p.Avatar).ShouldNot(BeNil())
+			Ω(p.Extras).Should(HaveLen(2))
+			Ω(p.Extras[0].Filename).Should(Equal("one.txt"))
+			Ω(readFile(p.Extras[0])).Should(Equal("one"))
+			Ω(p.Extras[1].Filename).Should(Equal("two.txt"))
+			Ω(readFile(p.Extras[1])).Should(Equal("a file larger than the memory limit"))
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			values["size"] = "large"
+		})
+
+		It("fails to decode the payload", func() {
+			Ω(decodeErr).Should(HaveOccurred())
+			Ω(decodeErr.Error()).Should(ContainSubstring("field size"))
+			Ω(rw.Status).Should(Equal(400))
+		})
+	})
+
+	Context("with a UUID field", func() {
+		type ownerPayload struct {
+			Owner *uuid.UUID `form:"owner,omitempty" json:"owner,omitempty"`
+		}
+
+		BeforeEach(func() {
+			values = map[string]string{"owner": "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}"}
+			uploads = nil
+			payload = &ownerPayload{}
+		})
+
+		It("parses the textual representations", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(payload.(*ownerPayload).Owner.String()).Should(Equal("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+		})
+
+		Context("given as 16 bytes", func() {
+			BeforeEach(func() {
+				u, _ := uuid.FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+				values["owner"] = string(u[:])
+			})
+
+			It("rejects the binary representation", func() {
+				Ω(decodeErr).Should(HaveOccurred())
+				Ω(decodeErr.Error()).Should(ContainSubstring("field owner"))
+			})
+		})
+
+		Context("given a malformed value", func() {
+			BeforeEach(func() {
+				values["owner"] = "6ba7b810-9dad"
+			})
+
+			It("fails to decode the payload", func() {
+				Ω(decodeErr).Should(HaveOccurred())
+				Ω(decodeErr.Error()).Should(ContainSubstring("field owner"))
+			})
+		})
+	})
+
+	Context("with a hash payload", func() {
+		BeforeEach(func() {
+			values = map[string]string{"small": "1", "large": "2147483648"}
+			uploads = nil
+			payload = &map[string]int64{}
+		})
+
+		It("coerces the values to the hash element type", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(*(payload.(*map[string]int64))).Should(Equal(map[string]int64{"small": 1, "large": 2147483648}))
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				values["small"] = "one"
+			})
+
+			It("fails to decode the payload", func() {
+				Ω(decodeErr).Should(HaveOccurred())
+				Ω(decodeErr.Error()).Should(ContainSubstring("field small"))
+			})
+		})
+	})
+
+	Context("with a hash of hashes payload", func() {
+		BeforeEach(func() {
+			values = map[string]string{"wines": `{"red": 2, "white": 3}`, "beers": `{}`}
+			uploads = nil
+			payload = &map[string]map[string]int{}
+		})
+
+		It("decodes each element into a typed map", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(*(payload.(*map[string]map[string]int))).Should(Equal(map[string]map[string]int{
+				"wines": {"red": 2, "white": 3},
+				"beers": {},
+			}))
+		})
+	})
+
+	Context("with a hash field given as JSON", func() {
+		type countsPayload struct {
+			Counts map[string]int `form:"counts,omitempty" json:"counts,omitempty"`
+		}
+
+		BeforeEach(func() {
+			values = map[string]string{"counts": `{"red": 1, "blue": 2}`}
+			uploads = nil
+			payload = &countsPayload{}
+		})
+
+		It("decodes the field", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(payload.(*countsPayload).Counts).Should(Equal(map[string]int{"red": 1, "blue": 2}))
+		})
+	})
+
+	Context("with a generic payload", func() {
+		BeforeEach(func() {
+			var raw interface{}
+			payload = &raw
+		})
+
+		It("loads the values and files in a map", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			m := *(payload.(*interface{}))
+			Ω(m).Should(HaveKeyWithValue("name", "Vincent"))
+			Ω(m).Should(HaveKey("avatar"))
+			Ω(m.(map[string]interface{})["avatar"].(*multipart.FileHeader).Filename).Should(Equal("avatar.png"))
+		})
+	})
+})
+
+var _ = Describe("RespondMultipart", func() {
+	var service *goa.Service
+	var naming goa.PartNaming
+	var rw *TestResponseWriter
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		naming = nil
+	})
+
+	JustBeforeEach(func() {
+		req, _ := http.NewRequest("POST", "/bottles/bulk", nil)
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		ctx := goa.NewContext(nil, rw, req, nil)
+		parts := []*goa.Part{
+			{ID: "bottle-1", ContentType: "application/json", Body: []byte(`{"id":1}`)},
+			{ID: "bottle-2", Body: []byte("raw")},
+		}
+		Ω(service.RespondMultipart(ctx, 207, parts, naming)).ShouldNot(HaveOccurred())
+	})
+
+	readParts := func() (names, types, bodies []string) {
+		mediaType, params, err := mime.ParseMediaType(rw.ParentHeader.Get("Content-Type"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mediaType).Should(Equal("multipart/form-data"))
+		mr := multipart.NewReader(bytes.NewReader(rw.Body), params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadAll(p)
+			Ω(err).ShouldNot(HaveOccurred())
+			names = append(names, p.FormName())
+			types = append(types, p.Header.Get("Content-Type"))
+			bodies = append(bodies, string(b))
+		}
+	}
+
+	It("names the parts after their ID", func() {
+		Ω(rw.Status).Should(Equal(207))
+		names, types, bodies := readParts()
+		Ω(names).Should(Equal([]string{"bottle-1", "bottle-2"}))
+		Ω(types).Should(Equal([]string{"application/json", "application/octet-stream"}))
+		Ω(bodies).Should(Equal([]string{`{"id":1}`, "raw"}))
+	})
+
+	Context("using the index naming", func() {
+		BeforeEach(func() {
+			naming = goa.PartNamingIndex
+		})
+
+		It("names the parts after their position", func() {
+			names, _, _ := readParts()
+			Ω(names).Should(Equal([]string{"0", "1"}))
+		})
+	})
+
+	Context("using a custom naming", func() {
+		BeforeEach(func() {
+			naming = func(i int, p *goa.Part) string {
+				return fmt.Sprintf("result[%d].%s", i, p.ID)
+			}
+		})
+
+		It("uses it to name the parts", func() {
+			names, _, _ := readParts()
+			Ω(names).Should(Equal([]string{"result[0].bottle-1", "result[1].bottle-2"}))
+		})
+	})
+})
@@ -1,8 +1,12 @@
 package goa
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -21,12 +25,19 @@ type (
 		Handle(method, path string, handle MuxHandler)
 		// HandleNotFound sets the MuxHandler invoked for requests that don't match any
 		// handler registered with Handle. The values argument given to the handler is
-		// always nil.
+		// always nil. The handler is also invoked for requests made to a registered path
+		// with a method that has no handler, in this case the response Allow header lists
+		// the methods handled for the path and the handler is expected to respond to
+		// OPTIONS requests with 200.
 		HandleNotFound(handle MuxHandler)
 		// Lookup returns the MuxHandler associated with the given HTTP method and path.
 		Lookup(method, path string) MuxHandler
 	}
 
+	// RequestMatcher returns true if the request may be handled by the route it is attached to,
+	// see Service.Match.
+	RequestMatcher func(*http.Request) bool
+
 	// Muxer implements an adapter that given a request handler can produce a mux handler.
 	Muxer interface {
 		MuxHandler(string, Handler, Unmarshaler) MuxHandler
@@ -34,45 +45,133 @@ type (
 
 	// mux is the default ServeMux implementation.
 	mux struct {
-		router  *httptreemux.TreeMux
-		handles map[string]MuxHandler
+		router   *httptreemux.TreeMux
+		handles  map[string]MuxHandler
+		matchers map[string][]RequestMatcher
+		notFound MuxHandler
+	}
+
+	// versionMux is the ServeMux returned by NewVersionMux.
+	versionMux struct {
+		ServeMux
+		prefix string
 	}
 )
 
-// NewMux returns a Mux.
+// DefaultVersionPathFormat is the format used by NewVersionMux to build the path prefix of the
+// versioned routes when none is given.
+const DefaultVersionPathFormat = "/v%s"
+
+// NewMux returns a Mux. The mux responds to OPTIONS requests made to paths that do not have an
+// explicit OPTIONS handler with a 200 response whose Allow header lists the methods handled for
+// the path.
 func NewMux() ServeMux {
 	r := httptreemux.New()
 	r.EscapeAddedRoutes = true
-	return &mux{
-		router:  r,
-		handles: make(map[string]MuxHandler),
+	m := &mux{
+		router:   r,
+		handles:  make(map[string]MuxHandler),
+		matchers: make(map[string][]RequestMatcher),
 	}
+	r.MethodNotAllowedHandler = m.methodNotAllowed
+	return m
 }
 
 // Handle sets the handler for the given verb and path.
 func (m *mux) Handle(method, path string, handle MuxHandler) {
+	key := method + path
 	hthandle := func(rw http.ResponseWriter, req *http.Request, htparams map[string]string) {
+		for _, match := range m.matchers[key] {
+			if !match(req) {
+				if m.notFound != nil {
+					m.notFound(rw, req, nil)
+				} else {
+					http.NotFound(rw, req)
+				}
+				return
+			}
+		}
 		params := req.URL.Query()
 		for n, p := range htparams {
 			params.Set(n, p)
 		}
 		handle(rw, req, params)
 	}
-	m.handles[method+path] = handle
+	m.handles[key] = handle
 	m.router.Handle(method, path, hthandle)
 }
 
 // HandleNotFound sets the MuxHandler invoked for requests that don't match any
 // handler registered with Handle.
 func (m *mux) HandleNotFound(handle MuxHandler) {
+	m.notFound = handle
 	nfh := func(rw http.ResponseWriter, req *http.Request) {
 		handle(rw, req, nil)
 	}
 	m.router.NotFoundHandler = nfh
-	mna := func(rw http.ResponseWriter, req *http.Request, methods map[string]httptreemux.HandlerFunc) {
-		handle(rw, req, nil)
+}
+
+// methodNotAllowed handles requests made to a registered path with a method that has no handler.
+// It sets the Allow header to the list of allowed methods then invokes the NotFound handler. OPTIONS
+// requests are given a 200 response if there is no NotFound handler.
+func (m *mux) methodNotAllowed(rw http.ResponseWriter, req *http.Request, methods map[string]httptreemux.HandlerFunc) {
+	rw.Header().Set("Allow", m.allow(methods))
+	if m.notFound != nil {
+		m.notFound(rw, req, nil)
+		return
+	}
+	if req.Method == "OPTIONS" {
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+	httptreemux.MethodNotAllowedHandler(rw, req, methods)
+}
+
+// allow returns the value of the Allow header listing the given methods, OPTIONS and HEAD if GET
+// requests are handled.
+func (m *mux) allow(methods map[string]httptreemux.HandlerFunc) string {
+	verbs := []string{"OPTIONS"}
+	for verb := range methods {
+		verbs = append(verbs, verb)
+	}
+	if _, ok := methods["GET"]; ok && m.router.HeadCanUseGet {
+		if _, ok := methods["HEAD"]; !ok {
+			verbs = append(verbs, "HEAD")
+		}
+	}
+	sort.Strings(verbs)
+	return strings.Join(verbs, ", ")
+}
+
+// Match attaches matchers to the handler registered with Handle for the given method and path.
+// Requests that do not satisfy all the matchers are handled by the NotFound handler.
+func (m *mux) Match(method, path string, matchers ...RequestMatcher) error {
+	key := method + path
+	if _, ok := m.handles[key]; !ok {
+		return fmt.Errorf("no handler registered for %s %s", method, path)
+	}
+	m.matchers[key] = append(m.matchers[key], matchers...)
+	return nil
+}
+
+// HeaderMatcher returns a request matcher that matches requests whose header name has the given
+// value.
+func HeaderMatcher(name, value string) RequestMatcher {
+	return func(req *http.Request) bool {
+		return req.Header.Get(name) == value
+	}
+}
+
+// HostMatcher returns a request matcher that matches requests made to the given host. The port
+// is not taken into account when the host does not specify one.
+func HostMatcher(host string) RequestMatcher {
+	return func(req *http.Request) bool {
+		if req.Host == host {
+			return true
+		}
+		h, _, err := net.SplitHostPort(req.Host)
+		return err == nil && h == host
 	}
-	m.router.MethodNotAllowedHandler = mna
 }
 
 // Lookup returns the MuxHandler associated with the given method and path.
@@ -84,3 +183,43 @@ func (m *mux) Lookup(method, path string) MuxHandler {
 func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	m.router.ServeHTTP(rw, req)
 }
+
+// NewVersionMux returns a ServeMux that registers the handlers given to Handle under a path prefix
+// identifying the API version in addition to their design path. This makes it possible for
+// clients that cannot set a version header to select the version through the request path:
+//
+//	service.Mux = goa.NewVersionMux(service.Mux, "1.0", "")
+//	app.MountBottleController(service, c) // serves both /bottles and /v1.0/bottles
+//
+// format is a fmt format string applied to version to produce the prefix, it defaults to
+// DefaultVersionPathFormat. Routes mounted at their design path are unaffected and may still
+// select the version with request matchers such as HeaderMatcher so that both strategies coexist.
+func NewVersionMux(mux ServeMux, version, format string) ServeMux {
+	if format == "" {
+		format = DefaultVersionPathFormat
+	}
+	prefix := strings.TrimSuffix(fmt.Sprintf(format, version), "/")
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return &versionMux{ServeMux: mux, prefix: prefix}
+}
+
+// Handle sets the handler for the given verb and path and for the same path prefixed with the
+// version.
+func (m *versionMux) Handle(method, path string, handle MuxHandler) {
+	m.ServeMux.Handle(method, path, handle)
+	m.ServeMux.Handle(method, m.prefix+path, handle)
+}
+
+// Match attaches matchers to the handler registered for the given method and path. Matchers
+// attached to the design path do not apply to the versioned path and vice versa.
+func (m *versionMux) Match(method, path string, matchers ...RequestMatcher) error {
+	mm, ok := m.ServeMux.(interface {
+		Match(string, string, ...RequestMatcher) error
+	})
+	if !ok {
+		return fmt.Errorf("mux %T does not support request matchers", m.ServeMux)
+	}
+	return mm.Match(method, path, matchers...)
+}
@@ -66,4 +66,116 @@ var _ = Describe("Mux", func() {
 		})
 	})
 
+	Context("with an OPTIONS request", func() {
+		var optionsCalled bool
+
+		BeforeEach(func() {
+			optionsCalled = false
+			noop := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {}
+			mux.Handle("GET", "/bottles/:id", noop)
+			mux.Handle("PUT", "/bottles/:id", noop)
+			mux.Handle("POST", "/bottles", noop)
+			mux.Handle("OPTIONS", "/bottles", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				optionsCalled = true
+				rw.WriteHeader(204)
+			})
+			var err error
+			req, err = http.NewRequest("OPTIONS", "/bottles/1", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("responds with the allowed methods", func() {
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("Allow")).Should(Equal("GET, HEAD, OPTIONS, PUT"))
+			Ω(optionsCalled).Should(BeFalse())
+		})
+
+		Context("made to a path with an explicit OPTIONS handler", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("OPTIONS", "/bottles", nil)
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("uses the handler", func() {
+				Ω(optionsCalled).Should(BeTrue())
+				Ω(rw.Status).Should(Equal(204))
+				Ω(rw.ParentHeader.Get("Allow")).Should(BeEmpty())
+			})
+		})
+
+		Context("made to an unknown path", func() {
+			BeforeEach(func() {
+				var err error
+				req, err = http.NewRequest("OPTIONS", "/unknown", nil)
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("returns 404", func() {
+				Ω(rw.Status).Should(Equal(404))
+			})
+		})
+	})
+
+	Context("with a version mux", func() {
+		var format string
+		var called int
+
+		BeforeEach(func() {
+			format = ""
+			called = 0
+		})
+
+		JustBeforeEach(func() {
+			mux = goa.NewVersionMux(goa.NewMux(), "1.0", format)
+			mux.Handle("GET", "/bottles/:id", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				Ω(vals.Get("id")).Should(Equal("1"))
+				called++
+			})
+			Ω(mux.(interface {
+				Match(string, string, ...goa.RequestMatcher) error
+			}).Match("GET", "/bottles/:id", goa.HeaderMatcher("X-Api-Version", "1.0"))).ShouldNot(HaveOccurred())
+		})
+
+		serve := func(path string, header http.Header) *TestResponseWriter {
+			r, err := http.NewRequest("GET", path, nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			for k, v := range header {
+				r.Header[k] = v
+			}
+			w := &TestResponseWriter{ParentHeader: http.Header{}}
+			mux.ServeHTTP(w, r)
+			return w
+		}
+
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "/", nil)
+		})
+
+		It("serves the versioned path", func() {
+			serve("/v1.0/bottles/1", nil)
+			Ω(called).Should(Equal(1))
+		})
+
+		It("keeps selecting the version with the header on the design path", func() {
+			serve("/bottles/1", http.Header{"X-Api-Version": {"1.0"}})
+			Ω(called).Should(Equal(1))
+			w := serve("/bottles/1", nil)
+			Ω(called).Should(Equal(1))
+			Ω(w.Status).Should(Equal(404))
+		})
+
+		Context("with a custom format", func() {
+			BeforeEach(func() {
+				format = "api/%s/"
+			})
+
+			It("uses it to build the path prefix", func() {
+				serve("/api/1.0/bottles/1", nil)
+				Ω(called).Should(Equal(1))
+				w := serve("/v1.0/bottles/1", nil)
+				Ω(w.Status).Should(Equal(404))
+			})
+		})
+	})
 })
@@ -0,0 +1,154 @@
+package goa
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// acceptedType is a media range read from an Accept header.
+type acceptedType struct {
+	typ, subtype string
+	q            float64
+	params       map[string]string
+}
+
+// NegotiateMediaType returns the element of offered that best matches the media ranges listed
+// in the request Accept header. It returns the first offered media type if the request does not
+// specify an Accept header and a NotAcceptableError if no offered media type is acceptable.
+//
+// Media ranges are considered in decreasing order of quality, ranges with a quality of 0 are
+// ignored. Besides wildcards (e.g. "*/*" or "application/*") a range also matches the media
+// types that use it as structured syntax suffix so that "application/json" matches
+// "application/vnd.goa.example.bottle+json" and the other way around. Parameters are ignored.
+// The service encoders are selected using the same rules, see HTTPEncoder.Negotiate.
+func (r *RequestData) NegotiateMediaType(offered ...string) (string, error) {
+	accept := r.Header.Get("Accept")
+	if len(offered) == 0 {
+		return "", nil
+	}
+	if accept == "" {
+		return offered[0], nil
+	}
+	if mt := negotiateMediaType(accept, offered); mt != "" {
+		return mt, nil
+	}
+	return "", NotAcceptableError(accept, offered)
+}
+
+// negotiateMediaType returns the element of offered that best matches the media ranges listed
+// in the given Accept header value or an empty string if none matches.
+func negotiateMediaType(accept string, offered []string) string {
+	for _, a := range parseAccept(accept) {
+		for _, o := range offered {
+			if a.matches(o) || a.suffixMatches(o) {
+				return o
+			}
+		}
+	}
+	return ""
+}
+
+type byQuality []*acceptedType
+
+func (b byQuality) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byQuality) Less(i, j int) bool { return b[i].q > b[j].q }
+func (b byQuality) Len() int           { return len(b) }
+
+// RequestedView returns the name of the view requested by the client. The view may be given
+// using the query string parameter named queryParam (e.g. "?view=tiny") or as a parameter of
+// the media ranges listed in the Accept header (e.g. "application/vnd.acme.task+json; view=tiny").
+// The query string takes precedence, media ranges are considered in decreasing order of quality.
+// RequestedView returns an empty string if the request does not specify a view.
+func (r *RequestData) RequestedView(queryParam string) string {
+	if queryParam != "" {
+		if v := r.URL.Query().Get(queryParam); v != "" {
+			return v
+		}
+	}
+	for _, a := range parseAccept(r.Header.Get("Accept")) {
+		if v := a.params["view"]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseAccept returns the media ranges listed in the given Accept header value sorted by
+// decreasing quality.
+func parseAccept(accept string) []*acceptedType {
+	var types []*acceptedType
+	for _, elem := range strings.Split(accept, ",") {
+		elem = strings.TrimSpace(elem)
+		if elem == "" {
+			continue
+		}
+		mt, params, err := mime.ParseMediaType(elem)
+		if err != nil {
+			mt = strings.TrimSpace(strings.SplitN(elem, ";", 2)[0])
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		typ, subtype := splitMediaType(mt)
+		types = append(types, &acceptedType{typ: typ, subtype: subtype, q: q, params: params})
+	}
+	sort.Stable(byQuality(types))
+	return types
+}
+
+// matches returns true if the media range accepts the given media type.
+func (a *acceptedType) matches(mediaType string) bool {
+	if mt, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = mt
+	}
+	typ, subtype := splitMediaType(mediaType)
+	if a.typ == "*" {
+		return true
+	}
+	if a.typ != typ {
+		return false
+	}
+	return a.subtype == "*" || a.subtype == subtype || strings.HasSuffix(subtype, "+"+a.subtype)
+}
+
+// suffixMatches returns true if the media range uses a structured syntax suffix that names the
+// given media type, e.g. "application/vnd.goa.example+json" and "application/json".
+func (a *acceptedType) suffixMatches(mediaType string) bool {
+	i := strings.LastIndex(a.subtype, "+")
+	if i < 0 {
+		return false
+	}
+	typ, subtype := splitMediaType(mediaType)
+	return a.typ == typ && a.subtype[i+1:] == subtype
+}
+
+// mediaTypeFormat returns the structured syntax suffix of the given media type or its subtype if
+// it does not have one, e.g. "json" for both "application/json" and "application/vnd.foo+json".
+func mediaTypeFormat(mediaType string) string {
+	if mt, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = mt
+	}
+	_, subtype := splitMediaType(mediaType)
+	if i := strings.LastIndex(subtype, "+"); i >= 0 {
+		return subtype[i+1:]
+	}
+	return subtype
+}
+
+// splitMediaType returns the lowercase type and subtype of the given media type.
+func splitMediaType(mt string) (string, string) {
+	mt = strings.ToLower(mt)
+	elems := strings.SplitN(mt, "/", 2)
+	if len(elems) == 1 {
+		return elems[0], "*"
+	}
+	return elems[0], elems[1]
+}
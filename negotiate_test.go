@@ -0,0 +1,104 @@
+package goa_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NegotiateMediaType", func() {
+	var accept string
+	var offered []string
+	var mediaType string
+	var err error
+
+	BeforeEach(func() {
+		accept = ""
+		offered = []string{"application/vnd.goa.example.bottle+json", "application/xml"}
+	})
+
+	JustBeforeEach(func() {
+		req, _ := http.NewRequest("GET", "/", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		mediaType, err = (&goa.RequestData{Request: req}).NegotiateMediaType(offered...)
+	})
+
+	Context("with no Accept header", func() {
+		It("returns the first offered media type", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(mediaType).Should(Equal("application/vnd.goa.example.bottle+json"))
+		})
+	})
+
+	Context("with an exact match", func() {
+		BeforeEach(func() {
+			accept = "application/xml"
+		})
+
+		It("returns it", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(mediaType).Should(Equal("application/xml"))
+		})
+	})
+
+	Context("with a structured syntax suffix match", func() {
+		BeforeEach(func() {
+			accept = "application/json"
+		})
+
+		It("returns the vendor media type", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(mediaType).Should(Equal("application/vnd.goa.example.bottle+json"))
+		})
+	})
+
+	Context("with a media range using a structured syntax suffix", func() {
+		BeforeEach(func() {
+			accept = "application/vnd.goa.example.bottle+json"
+			offered = []string{"application/xml", "application/json"}
+		})
+
+		It("returns the media type named after the suffix", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(mediaType).Should(Equal("application/json"))
+		})
+	})
+
+	Context("with quality values", func() {
+		BeforeEach(func() {
+			accept = "application/json;q=0.5, application/xml"
+		})
+
+		It("prefers the media range with the highest quality", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(mediaType).Should(Equal("application/xml"))
+		})
+	})
+
+	Context("with a wildcard", func() {
+		BeforeEach(func() {
+			accept = "text/html, */*;q=0.1"
+		})
+
+		It("returns the first offered media type", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(mediaType).Should(Equal("application/vnd.goa.example.bottle+json"))
+		})
+	})
+
+	Context("with no acceptable media type", func() {
+		BeforeEach(func() {
+			accept = "text/html, application/xml;q=0"
+		})
+
+		It("returns a not acceptable error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+			Ω(err.Error()).Should(ContainSubstring(`matches the Accept header "text/html, application/xml;q=0"`))
+		})
+	})
+})
@@ -0,0 +1,341 @@
+package goa
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/uuid"
+)
+
+// RepeatedParamPolicy defines how a parameter that accepts a single value is resolved when the
+// request specifies it multiple times, e.g. "?id=1&id=2".
+type RepeatedParamPolicy int
+
+const (
+	// ParamFirst uses the first value given for the parameter.
+	ParamFirst RepeatedParamPolicy = iota
+	// ParamLast uses the last value given for the parameter.
+	ParamLast
+	// ParamError causes the request to be rejected with a RepeatedParamError.
+	ParamError
+)
+
+// ScalarParam returns the value of a parameter that accepts a single value given the values
+// read from the request. vals must not be empty. The value returned when vals contains more than
+// one element is dictated by the service RepeatedParams policy, ScalarParam returns the first
+// value together with a RepeatedParamError if the policy is ParamError.
+func (service *Service) ScalarParam(name string, vals []string) (string, error) {
+	if len(vals) == 1 {
+		return vals[0], nil
+	}
+	switch service.RepeatedParams {
+	case ParamLast:
+		return vals[len(vals)-1], nil
+	case ParamError:
+		return vals[0], RepeatedParamError(name, vals)
+	default:
+		return vals[0], nil
+	}
+}
+
+// RegisterBoolStrings registers additional strings recognized as boolean values when coercing
+// parameters, for example:
+//
+//	service.RegisterBoolStrings(map[string]bool{"yes": true, "no": false, "on": true, "off": false})
+//
+// Strings are matched case insensitively. Registered strings take precedence over the values
+// recognized by strconv.ParseBool. RegisterBoolStrings must be called before the service starts.
+func (service *Service) RegisterBoolStrings(strs map[string]bool) {
+	if service.boolStrings == nil {
+		service.boolStrings = make(map[string]bool, len(strs))
+	}
+	for s, b := range strs {
+		service.boolStrings[strings.ToLower(s)] = b
+	}
+}
+
+// ParseBool returns the boolean value represented by s. It accepts the strings registered with
+// RegisterBoolStrings and the ones accepted by strconv.ParseBool.
+func (service *Service) ParseBool(s string) (bool, error) {
+	if b, ok := service.boolStrings[strings.ToLower(s)]; ok {
+		return b, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// ParseTime parses the time represented by s. If unit is "seconds" or "milliseconds" and s is an
+// integer then s is the number of such units elapsed since the Unix epoch. Other values are parsed
+// using layout, or as RFC3339 timestamps if layout is empty. The code generated for DateTime
+// parameters whose design specifies a time unit uses ParseTime.
+func ParseTime(s, layout, unit string) (time.Time, error) {
+	if unit != "" {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return EpochTime(n, unit)
+		}
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return time.Parse(layout, s)
+}
+
+// EpochTime returns the UTC time n units after the Unix epoch. unit must be "seconds" or
+// "milliseconds".
+func EpochTime(n int64, unit string) (time.Time, error) {
+	switch unit {
+	case "seconds":
+		return time.Unix(n, 0).UTC(), nil
+	case "milliseconds":
+		return time.Unix(n/1000, n%1000*int64(time.Millisecond)).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown time unit %#v, must be \"seconds\" or \"milliseconds\"", unit)
+}
+
+// BindParams sets the fields of the struct pointed to by v from the given request parameters.
+// Fields are matched using their "param" struct tag, fields without the tag or with the tag
+// set to "-" are left untouched. It supports fields of type string, bool, integer, float,
+// time.Time (RFC3339) and uuid.UUID, pointers to these types and slices of these types. The
+// structs generated by the client generator when bundling query parameters carry the tags
+// expected by BindParams:
+//
+//	var query ListBottleQuery
+//	if err := ctrl.Service.BindParams(goa.ContextRequest(ctx).Params, &query); err != nil {
+//		return err
+//	}
+//
+// The tag of a slice field may specify the collection format of the parameter after the name,
+// e.g. `param:"tags,csv"`, the values are then split with SplitCollectionParam. Parameters that
+// accept a single value are resolved using ScalarParam and booleans are parsed using ParseBool.
+func (service *Service) BindParams(params url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("goa: BindParams requires a non-nil pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		name, format := f.Tag.Get("param"), ""
+		if i := strings.IndexByte(name, ','); i >= 0 {
+			name, format = name[:i], name[i+1:]
+		}
+		if name == "" || name == "-" || f.PkgPath != "" {
+			continue
+		}
+		vals, ok := params[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Slice {
+			vals = SplitCollectionParam(vals, format)
+			slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for j, val := range vals {
+				if err := service.bindParam(name, val, slice.Index(j)); err != nil {
+					return err
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+		val, err := service.ScalarParam(name, vals)
+		if err != nil {
+			return err
+		}
+		if err := service.bindParam(name, val, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+)
+
+// bindParam sets v to the value represented by val.
+func (service *Service) bindParam(name, val string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := service.bindParam(name, val, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if v.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return InvalidParamTypeError(name, val, "datetime")
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if v.Type() == uuidType {
+		u, err := uuid.FromString(val)
+		if err != nil {
+			return InvalidParamTypeError(name, val, "uuid")
+		}
+		v.Set(reflect.ValueOf(u))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		b, err := service.ParseBool(val)
+		if err != nil {
+			return InvalidParamTypeError(name, val, "boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, v.Type().Bits())
+		if err != nil {
+			return InvalidParamTypeError(name, val, "integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, v.Type().Bits())
+		if err != nil {
+			return InvalidParamTypeError(name, val, "integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, v.Type().Bits())
+		if err != nil {
+			return InvalidParamTypeError(name, val, "number")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("goa: unsupported type %s for parameter %#v", v.Type(), name)
+	}
+	return nil
+}
+
+// BracketedParams assembles the request parameters that encode an array of objects using
+// bracketed keys into a slice of maps, for example:
+//
+//	?items[0][name]=x&items[0][qty]=2&items[1][name]=y
+//
+// produces for the name "items":
+//
+//	[]map[string]interface{}{{"name": "x", "qty": "2"}, {"name": "y"}}
+//
+// Additional brackets produce nested maps (items[0][size][width]=2). Parameters given multiple
+// times produce a []interface{} containing each value. Elements are sorted by index, missing
+// indices are skipped. The values are strings, use design.Coerce with the parameter type to
+// coerce and validate the result. BracketedParams returns nil if params does not contain any key
+// for the given name.
+func BracketedParams(params url.Values, name string) ([]map[string]interface{}, error) {
+	elems := make(map[int]map[string]interface{})
+	prefix := name + "["
+	for key, vals := range params {
+		if !strings.HasPrefix(key, prefix) || len(vals) == 0 {
+			continue
+		}
+		segments, ok := bracketSegments(key[len(name):])
+		if !ok || len(segments) < 2 {
+			return nil, InvalidParamTypeError(key, vals[0], "bracketed object field")
+		}
+		idx, err := strconv.Atoi(segments[0])
+		if err != nil || idx < 0 {
+			return nil, InvalidParamTypeError(key, segments[0], "array index")
+		}
+		elem, ok := elems[idx]
+		if !ok {
+			elem = make(map[string]interface{})
+			elems[idx] = elem
+		}
+		m := elem
+		fields := segments[1:]
+		for _, f := range fields[:len(fields)-1] {
+			child, ok := m[f]
+			if !ok {
+				child = make(map[string]interface{})
+				m[f] = child
+			}
+			if m, ok = child.(map[string]interface{}); !ok {
+				return nil, InvalidParamTypeError(key, vals[0], "bracketed object field")
+			}
+		}
+		last := fields[len(fields)-1]
+		if _, ok := m[last]; ok {
+			return nil, InvalidParamTypeError(key, vals[0], "bracketed object field")
+		}
+		if len(vals) == 1 {
+			m[last] = vals[0]
+		} else {
+			vs := make([]interface{}, len(vals))
+			for i, v := range vals {
+				vs[i] = v
+			}
+			m[last] = vs
+		}
+	}
+	if len(elems) == 0 {
+		return nil, nil
+	}
+	indices := make([]int, 0, len(elems))
+	for idx := range elems {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+	res := make([]map[string]interface{}, len(indices))
+	for i, idx := range indices {
+		res[i] = elems[idx]
+	}
+	return res, nil
+}
+
+// bracketSegments returns the content of the brackets in s, e.g. "[0][name]" returns
+// []string{"0", "name"}. It returns false if s is not a sequence of non empty bracketed segments.
+func bracketSegments(s string) ([]string, bool) {
+	var segments []string
+	for s != "" {
+		if s[0] != '[' {
+			return nil, false
+		}
+		end := strings.IndexByte(s, ']')
+		if end < 2 {
+			return nil, false
+		}
+		segments = append(segments, s[1:end])
+		s = s[end+1:]
+	}
+	return segments, true
+}
+
+// SplitCollectionParam returns the elements of an array parameter given the values read from the
+// request and the parameter collection format: "csv" splits the values on commas, "ssv" on
+// spaces, "tsv" on tabs and "pipes" on "|". Each value given for the parameter is split so that
+// repeated keys and separated values may be combined (e.g. "?label=a,b&label=c" with "csv").
+// The values are returned unchanged for the "multi" format and for unknown formats.
+func SplitCollectionParam(vals []string, format string) []string {
+	var sep string
+	switch format {
+	case "csv":
+		sep = ","
+	case "ssv":
+		sep = " "
+	case "tsv":
+		sep = "\t"
+	case "pipes":
+		sep = "|"
+	default:
+		return vals
+	}
+	res := make([]string, 0, len(vals))
+	for _, v := range vals {
+		if v == "" {
+			continue
+		}
+		res = append(res, strings.Split(v, sep)...)
+	}
+	return res
+}
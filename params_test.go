@@ -0,0 +1,290 @@
+package goa_test
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScalarParam", func() {
+	var vals []string
+	var policy goa.RepeatedParamPolicy
+	var val string
+	var err error
+
+	BeforeEach(func() {
+		vals = []string{"1", "2"}
+		policy = goa.ParamFirst
+	})
+
+	JustBeforeEach(func() {
+		service := goa.New("test")
+		service.RepeatedParams = policy
+		val, err = service.ScalarParam("id", vals)
+	})
+
+	Context("with a single value", func() {
+		BeforeEach(func() {
+			vals = []string{"1"}
+			policy = goa.ParamError
+		})
+
+		It("returns it", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(val).Should(Equal("1"))
+		})
+	})
+
+	Context("with the ParamFirst policy", func() {
+		It("returns the first value", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(val).Should(Equal("1"))
+		})
+	})
+
+	Context("with the ParamLast policy", func() {
+		BeforeEach(func() {
+			policy = goa.ParamLast
+		})
+
+		It("returns the last value", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(val).Should(Equal("2"))
+		})
+	})
+
+	Context("with the ParamError policy", func() {
+		BeforeEach(func() {
+			policy = goa.ParamError
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`parameter "id" must be given once`))
+		})
+	})
+})
+
+var _ = Describe("ParseBool", func() {
+	var service *goa.Service
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.RegisterBoolStrings(map[string]bool{"yes": true, "No": false})
+	})
+
+	It("recognizes the registered strings", func() {
+		b, err := service.ParseBool("yes")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(BeTrue())
+		b, err = service.ParseBool("NO")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(BeFalse())
+	})
+
+	It("recognizes the standard strings", func() {
+		b, err := service.ParseBool("true")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(BeTrue())
+	})
+
+	It("rejects unknown strings", func() {
+		_, err := service.ParseBool("maybe")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("ParseTime", func() {
+	const ts = "1700000000000"
+
+	It("parses integers as milliseconds", func() {
+		t, err := goa.ParseTime(ts, "", "milliseconds")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t).Should(Equal(time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)))
+	})
+
+	It("parses integers as seconds", func() {
+		t, err := goa.ParseTime(ts, "", "seconds")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t).Should(Equal(time.Unix(1700000000000, 0).UTC()))
+	})
+
+	It("parses other values using the layout", func() {
+		t, err := goa.ParseTime("2016-03-15", "2006-01-02", "milliseconds")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t).Should(Equal(time.Date(2016, 3, 15, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("rejects integers if no unit is given", func() {
+		_, err := goa.ParseTime(ts, "", "")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("BindParams", func() {
+	type query struct {
+		Name    *string    `json:"name,omitempty" param:"name"`
+		Limit   int        `json:"limit" param:"limit"`
+		Ratio   *float64   `json:"ratio,omitempty" param:"ratio"`
+		Active  *bool      `json:"active,omitempty" param:"active"`
+		Tags    []string   `json:"tags,omitempty" param:"tags"`
+		IDs     []int      `json:"ids,omitempty" param:"ids,csv"`
+		Owner   *uuid.UUID `json:"owner,omitempty" param:"owner"`
+		Ignored string
+	}
+
+	var params url.Values
+	var q query
+	var err error
+
+	JustBeforeEach(func() {
+		q = query{}
+		err = goa.New("test").BindParams(params, &q)
+	})
+
+	Context("with valid parameters", func() {
+		BeforeEach(func() {
+			params = url.Values{
+				"name":    {"foo"},
+				"limit":   {"10"},
+				"ratio":   {"0.5"},
+				"active":  {"true"},
+				"tags":    {"a", "b"},
+				"ids":     {"1,2", "3"},
+				"owner":   {"6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+				"Ignored": {"bar"},
+			}
+		})
+
+		It("sets the tagged fields", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(q.Name).ShouldNot(BeNil())
+			Ω(*q.Name).Should(Equal("foo"))
+			Ω(q.Limit).Should(Equal(10))
+			Ω(q.Ratio).ShouldNot(BeNil())
+			Ω(*q.Ratio).Should(Equal(0.5))
+			Ω(q.Active).ShouldNot(BeNil())
+			Ω(*q.Active).Should(BeTrue())
+			Ω(q.Tags).Should(Equal([]string{"a", "b"}))
+			Ω(q.IDs).Should(Equal([]int{1, 2, 3}))
+			Ω(q.Owner).ShouldNot(BeNil())
+			Ω(q.Owner.String()).Should(Equal("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+			Ω(q.Ignored).Should(BeEmpty())
+		})
+	})
+
+	Context("with missing parameters", func() {
+		BeforeEach(func() {
+			params = url.Values{"limit": {"10"}}
+		})
+
+		It("leaves the fields unset", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(q.Name).Should(BeNil())
+			Ω(q.Tags).Should(BeNil())
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			params = url.Values{"limit": {"ten"}}
+		})
+
+		It("returns an invalid param type error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`invalid value "ten" for parameter "limit", must be a integer`))
+		})
+	})
+
+	Context("with an invalid uuid", func() {
+		BeforeEach(func() {
+			params = url.Values{"owner": {"not-a-uuid"}}
+		})
+
+		It("returns an invalid param type error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`invalid value "not-a-uuid" for parameter "owner", must be a uuid`))
+		})
+	})
+})
+
+var _ = Describe("BracketedParams", func() {
+	var params url.Values
+	var items []map[string]interface{}
+	var err error
+
+	JustBeforeEach(func() {
+		items, err = goa.BracketedParams(params, "items")
+	})
+
+	Context("with bracket-indexed keys", func() {
+		BeforeEach(func() {
+			params = url.Values{
+				"items[1][name]": {"y"},
+				"items[0][name]": {"x"},
+				"items[0][qty]":  {"2"},
+				"items[1][tags]": {"a", "b"},
+				"other":          {"z"},
+			}
+		})
+
+		It("assembles an array of objects", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(items).Should(Equal([]map[string]interface{}{
+				{"name": "x", "qty": "2"},
+				{"name": "y", "tags": []interface{}{"a", "b"}},
+			}))
+		})
+	})
+
+	Context("with nested brackets", func() {
+		BeforeEach(func() {
+			params = url.Values{"items[0][size][width]": {"2"}}
+		})
+
+		It("produces nested objects", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(items).Should(Equal([]map[string]interface{}{
+				{"size": map[string]interface{}{"width": "2"}},
+			}))
+		})
+	})
+
+	Context("with an invalid index", func() {
+		BeforeEach(func() {
+			params = url.Values{"items[foo][name]": {"x"}}
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("must be a array index"))
+		})
+	})
+})
+
+var _ = Describe("SplitCollectionParam", func() {
+	It("returns a single value unchanged", func() {
+		Ω(goa.SplitCollectionParam([]string{"a"}, "multi")).Should(Equal([]string{"a"}))
+		Ω(goa.SplitCollectionParam([]string{"a"}, "csv")).Should(Equal([]string{"a"}))
+	})
+
+	It("keeps repeated values", func() {
+		Ω(goa.SplitCollectionParam([]string{"a", "b"}, "multi")).Should(Equal([]string{"a", "b"}))
+		Ω(goa.SplitCollectionParam([]string{"a,b"}, "multi")).Should(Equal([]string{"a,b"}))
+	})
+
+	It("splits separated values", func() {
+		Ω(goa.SplitCollectionParam([]string{"a,b", "c"}, "csv")).Should(Equal([]string{"a", "b", "c"}))
+		Ω(goa.SplitCollectionParam([]string{"a b"}, "ssv")).Should(Equal([]string{"a", "b"}))
+		Ω(goa.SplitCollectionParam([]string{"a\tb"}, "tsv")).Should(Equal([]string{"a", "b"}))
+		Ω(goa.SplitCollectionParam([]string{"a|b"}, "pipes")).Should(Equal([]string{"a", "b"}))
+	})
+
+	It("skips empty values", func() {
+		Ω(goa.SplitCollectionParam([]string{""}, "csv")).Should(BeEmpty())
+	})
+})
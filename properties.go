@@ -0,0 +1,108 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PropertiesRule describes how the additional properties of a JSON object, that is the keys that
+// do not match any of the object attributes, are validated. The code generated for the payloads
+// whose design uses AdditionalProperties decodes the request body with DecodeProperties.
+type PropertiesRule struct {
+	// Known lists the names of the object attributes.
+	Known []string
+	// Reject causes additional properties to be rejected.
+	Reject bool
+	// Type is the JSON type of the additional properties values if any: "string", "integer",
+	// "number", "boolean", "array" or "object".
+	Type string
+	// Attributes lists the rules that apply to the values of the object attributes indexed by
+	// attribute name. The rule of an attribute whose value is an array applies to each element.
+	Attributes map[string]*PropertiesRule
+}
+
+// DecodeProperties validates the additional properties of the JSON object in data against rule
+// and decodes data into v. ctx is the name of the object used in error messages, e.g. "raw".
+// DecodeProperties returns all the validation errors at once.
+func DecodeProperties(ctx string, data []byte, v interface{}, rule *PropertiesRule) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if err := rule.validate(ctx, raw); err != nil {
+		return err
+	}
+	dec = json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// validate validates the additional properties of val.
+func (r *PropertiesRule) validate(ctx string, val interface{}) error {
+	var err error
+	switch actual := val.(type) {
+	case []interface{}:
+		for i, e := range actual {
+			err = AppendError(err, r.validate(fmt.Sprintf("%s[%d]", ctx, i), e))
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(actual))
+		for k := range actual {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := actual[k]
+			if r.isKnown(k) {
+				if child, ok := r.Attributes[k]; ok && v != nil {
+					err = AppendError(err, child.validate(ctx+"."+k, v))
+				}
+				continue
+			}
+			if r.Reject {
+				err = AppendError(err, AdditionalPropertyError(ctx, k))
+			} else if r.Type != "" && !hasJSONType(v, r.Type) {
+				err = AppendError(err, InvalidAttributeTypeError(ctx+"."+k, v, r.Type))
+			}
+		}
+	}
+	return err
+}
+
+// isKnown returns true if name is the name of one of the object attributes.
+func (r *PropertiesRule) isKnown(name string) bool {
+	for _, k := range r.Known {
+		if k == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasJSONType returns true if val, the result of decoding JSON with UseNumber, is of the given JSON
+// type.
+func hasJSONType(val interface{}, typ string) bool {
+	switch actual := val.(type) {
+	case nil:
+		return true
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case json.Number:
+		if typ == "integer" {
+			return !strings.ContainsAny(string(actual), ".eE")
+		}
+		return typ == "number"
+	case []interface{}:
+		return typ == "array"
+	case map[string]interface{}:
+		return typ == "object"
+	}
+	return false
+}
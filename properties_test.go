@@ -0,0 +1,88 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DecodeProperties", func() {
+	type settings struct {
+		Theme *string `json:"theme,omitempty"`
+	}
+	type payload struct {
+		Name     *string     `json:"name,omitempty"`
+		Settings *settings   `json:"settings,omitempty"`
+		Extra    interface{} `json:"extra,omitempty"`
+	}
+
+	var rule *goa.PropertiesRule
+	var body string
+	var decoded payload
+	var err error
+
+	BeforeEach(func() {
+		rule = &goa.PropertiesRule{
+			Known:  []string{"extra", "name", "settings"},
+			Reject: true,
+			Attributes: map[string]*goa.PropertiesRule{
+				"settings": {Known: []string{"theme"}, Type: "integer"},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		decoded = payload{}
+		err = goa.DecodeProperties("raw", []byte(body), &decoded, rule)
+	})
+
+	Context("with known properties only", func() {
+		BeforeEach(func() {
+			body = `{"name": "foo", "settings": {"theme": "dark"}, "extra": 9007199254740993}`
+		})
+
+		It("decodes the value", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*decoded.Name).Should(Equal("foo"))
+			Ω(*decoded.Settings.Theme).Should(Equal("dark"))
+			Ω(decoded.Extra).Should(Equal(json.Number("9007199254740993")))
+		})
+	})
+
+	Context("with an additional property in a rejecting object", func() {
+		BeforeEach(func() {
+			body = `{"name": "foo", "unknown": true}`
+		})
+
+		It("rejects it", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring(`attribute "unknown" of raw is not allowed`))
+		})
+	})
+
+	Context("with typed nested additional properties", func() {
+		BeforeEach(func() {
+			body = `{"settings": {"theme": "dark", "size": 12, "color": "red"}}`
+		})
+
+		It("validates their type", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("type of raw.settings.color must be integer"))
+			Ω(err.Error()).ShouldNot(ContainSubstring("raw.settings.size"))
+		})
+	})
+
+	Context("with no restriction", func() {
+		BeforeEach(func() {
+			rule = &goa.PropertiesRule{Known: []string{"name"}}
+			body = `{"name": "foo", "unknown": true}`
+		})
+
+		It("ignores the additional properties", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*decoded.Name).Should(Equal("foo"))
+		})
+	})
+})
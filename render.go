@@ -0,0 +1,400 @@
+package goa
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// View lists the names of the members rendered by a media type view. The application generator
+// produces the views of each media type defined in the design, e.g. BottleViews for the Bottle
+// media type.
+type View []string
+
+type (
+	// memberFilter returns true if the member with the given name of an object found level
+	// objects below the top-level value must be rendered. tag is the tag of the struct field
+	// holding the member, it is empty for the members of maps.
+	memberFilter func(level int, name string, tag reflect.StructTag) bool
+
+	// projectedField is a struct field whose value was projected.
+	projectedField struct {
+		reflect.StructField
+		// value is the projected field value.
+		value reflect.Value
+		// embedded is true if the field is an embedded struct whose members are promoted.
+		embedded bool
+	}
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	xmlMarshalerType  = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+	xmlNameType       = reflect.TypeOf(xml.Name{})
+	interfaceType     = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// LimitDepth returns a copy of v where embedded objects nested more than depth levels below the
+// top-level value are replaced with their link. The link of an object consists of its "href" and
+// "id" members, objects that have neither are omitted altogether. Arrays do not count as a
+// level: the elements of an array are at the same depth as the array itself.
+//
+// LimitDepth makes it possible to bound the size of responses built from recursive media types
+// (e.g. blog -> posts -> comments -> author). A depth of 0 only renders the top-level object
+// fully. The omitted members are absent from the copy: the objects that lose members are copied
+// to values of new struct types that only hold the rendered members, so that the copy may be
+// encoded with any encoder. v is returned as is if nothing is omitted.
+func LimitDepth(v interface{}, depth int) interface{} {
+	return render(v, func(level int, name string, _ reflect.StructTag) bool {
+		return level <= depth || name == "href" || name == "id"
+	})
+}
+
+// RenderCollection renders a collection whose items may each use a different view. items must be
+// a slice. selector is called with each item and returns the name of the view used to render it,
+// views maps the view names to the members they render. This makes it possible to render mixed
+// collections, for example rendering more members for the items the user administers:
+//
+//	res, err := goa.RenderCollection(users, app.UserViews, func(item interface{}) string {
+//		if item.(*app.User).Admin {
+//			return "admin"
+//		}
+//		return "default"
+//	})
+//
+// The result is a slice of the same type as items unless the views omit members of the items, see
+// LimitDepth.
+func RenderCollection(items interface{}, views map[string]View, selector func(item interface{}) string) (interface{}, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot render %T as a collection", items)
+	}
+	vals := make([]reflect.Value, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		name := selector(item.Interface())
+		view, ok := views[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown view %#v for collection item %d", name, i)
+		}
+		vals[i], _ = project(item, 0, view.filter())
+	}
+	return makeSlice(v.Type(), vals).Interface(), nil
+}
+
+// RenderView returns a copy of v that only contains the members of the view with the given name.
+// v is typically a media type or a media type collection, views maps the view names to the
+// members they render. Use it together with RequestData.RequestedView to let clients select the
+// representation:
+//
+//	res, err := goa.RenderView(task, app.TaskViews, goa.ContextRequest(ctx).RequestedView("view"))
+//
+// RenderView renders the "default" view if name is empty. The members that are not part of the
+// view are absent from the copy, see LimitDepth.
+func RenderView(v interface{}, views map[string]View, name string) (interface{}, error) {
+	if name == "" {
+		name = "default"
+	}
+	view, ok := views[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown view %#v", name)
+	}
+	return render(v, view.filter()), nil
+}
+
+// RenderScoped returns a copy of v where the members that require scopes not held by the caller
+// are omitted at any depth. The scopes required by a member are listed in the "scopes" tag of the
+// struct field holding it, the application generator produces the tag from the "scopes" metadata
+// of the media type attributes:
+//
+//	Attribute("status", String, func() {
+//		Metadata("scopes", "admin")
+//	})
+//
+// granted lists the scopes held by the caller, see ContextGrantedScopes. Filtering applies on top
+// of the view used to render v so that for example a "status" member may only be rendered for
+// admins:
+//
+//	res := goa.RenderScoped(blogger, goa.ContextGrantedScopes(ctx))
+//
+// The omitted members are absent from the copy, see LimitDepth.
+func RenderScoped(v interface{}, granted []string) interface{} {
+	held := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		held[s] = true
+	}
+	return render(v, func(_ int, _ string, tag reflect.StructTag) bool {
+		scopes := tag.Get("scopes")
+		if scopes == "" {
+			return true
+		}
+		for _, s := range strings.Split(scopes, ",") {
+			if !held[s] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// filter returns the member filter that only renders the members of the view at the top level.
+func (v View) filter() memberFilter {
+	return func(level int, name string, _ reflect.StructTag) bool {
+		if level > 0 {
+			return true
+		}
+		for _, m := range v {
+			if m == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// render returns a copy of v where the members rejected by keep are omitted.
+func render(v interface{}, keep memberFilter) interface{} {
+	if v == nil {
+		return nil
+	}
+	res, _ := project(reflect.ValueOf(v), 0, keep)
+	return res.Interface()
+}
+
+// project returns a copy of v where the members of the objects rejected by keep are omitted.
+// Objects are structs and maps with string keys, v is projected without going through any encoding
+// so that the copy may be encoded with any encoder. Structs that lose members are copied to values
+// of new struct types, the copy has the same type as v otherwise. Values that implement a
+// marshaler interface such as time.Time are not considered objects. The boolean return value is
+// false if v is an object whose members were all rejected or are nil, in which case v should be
+// omitted from its parent.
+func project(v reflect.Value, level int, keep memberFilter) (reflect.Value, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return v, true
+		}
+		elem, ok := project(v.Elem(), level, keep)
+		if !ok {
+			return reflect.Zero(v.Type()), false
+		}
+		if v.Kind() == reflect.Interface {
+			if !elem.Type().AssignableTo(v.Type()) {
+				return elem, true
+			}
+			res := reflect.New(v.Type()).Elem()
+			res.Set(elem)
+			return res, true
+		}
+		res := reflect.New(elem.Type())
+		res.Elem().Set(elem)
+		return res, true
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v, true
+		}
+		vals := make([]reflect.Value, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if elem, ok := project(v.Index(i), level, keep); ok {
+				vals = append(vals, elem)
+			}
+		}
+		return makeSlice(v.Type(), vals), true
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v, true
+		}
+		var keys, vals []reflect.Value
+		rejected := false
+		for _, k := range v.MapKeys() {
+			if !keep(level, k.String(), "") {
+				rejected = true
+				continue
+			}
+			if val, ok := project(v.MapIndex(k), level+1, keep); ok {
+				keys = append(keys, k)
+				vals = append(vals, val)
+			}
+		}
+		res := reflect.MakeMap(reflect.MapOf(v.Type().Key(), elemType(v.Type().Elem(), vals)))
+		for i, k := range keys {
+			res.SetMapIndex(k, vals[i])
+		}
+		return res, !rejected || res.Len() > 0
+	case reflect.Struct:
+		if isOpaque(v.Type()) {
+			return v, true
+		}
+		var fields []projectedField
+		rejected, present, changed := false, false, false
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := memberName(f)
+			if name == "-" {
+				fields = append(fields, projectedField{StructField: f, value: v.Field(i)})
+				continue
+			}
+			embedded := f.Anonymous && name == f.Name && indirect(f.Type).Kind() == reflect.Struct
+			if !embedded && !keep(level, name, f.Tag) {
+				rejected, changed = true, true
+				continue
+			}
+			lvl := level + 1
+			if embedded {
+				lvl = level
+			}
+			val, ok := project(v.Field(i), lvl, keep)
+			if !ok {
+				rejected = rejected || embedded
+				changed = true
+				continue
+			}
+			changed = changed || val.Type() != f.Type
+			present = present || !isNil(val)
+			fields = append(fields, projectedField{StructField: f, value: val, embedded: embedded})
+		}
+		if !changed {
+			res := reflect.New(v.Type()).Elem()
+			res.Set(v)
+			for _, f := range fields {
+				res.FieldByIndex(f.Index).Set(f.value)
+			}
+			return res, true
+		}
+		return newStruct(v.Type(), fields, level), !rejected || present
+	}
+	return v, true
+}
+
+// newStruct returns a value of a new struct type made of the given fields. The members of the
+// embedded structs are promoted to the new struct. Top-level objects of named types are given a
+// XMLName field holding the type name so that their XML encoding uses the same element name.
+func newStruct(t reflect.Type, fields []projectedField, level int) reflect.Value {
+	var (
+		sfs  []reflect.StructField
+		vals []reflect.Value
+	)
+	seen := make(map[string]bool)
+	var add func(fields []projectedField)
+	add = func(fields []projectedField) {
+		for _, f := range fields {
+			if !f.embedded {
+				seen[f.Name] = true
+			}
+		}
+		for _, f := range fields {
+			if !f.embedded {
+				sfs = append(sfs, reflect.StructField{Name: f.Name, Type: f.value.Type(), Tag: f.Tag})
+				vals = append(vals, f.value)
+				continue
+			}
+			val := f.value
+			if val.Kind() == reflect.Ptr {
+				if val.IsNil() {
+					continue
+				}
+				val = val.Elem()
+			}
+			var promoted []projectedField
+			for i := 0; i < val.NumField(); i++ {
+				sf := val.Type().Field(i)
+				if sf.PkgPath != "" || seen[sf.Name] {
+					continue
+				}
+				embedded := sf.Anonymous && memberName(sf) == sf.Name && indirect(sf.Type).Kind() == reflect.Struct
+				promoted = append(promoted, projectedField{StructField: sf, value: val.Field(i), embedded: embedded})
+			}
+			add(promoted)
+		}
+	}
+	add(fields)
+	if level == 0 && t.Name() != "" && !seen["XMLName"] {
+		tag := reflect.StructTag(`json:"-" xml:"` + t.Name() + `"`)
+		sfs = append([]reflect.StructField{{Name: "XMLName", Type: xmlNameType, Tag: tag}}, sfs...)
+		vals = append([]reflect.Value{reflect.Zero(xmlNameType)}, vals...)
+	}
+	res := reflect.New(reflect.StructOf(sfs)).Elem()
+	for i, val := range vals {
+		res.Field(i).Set(val)
+	}
+	return res
+}
+
+// makeSlice returns a slice holding the given projected values. The slice is of type t if the
+// values may be stored in it, see elemType.
+func makeSlice(t reflect.Type, vals []reflect.Value) reflect.Value {
+	et := elemType(t.Elem(), vals)
+	if et != t.Elem() {
+		t = reflect.SliceOf(et)
+	}
+	res := reflect.MakeSlice(t, len(vals), len(vals))
+	for i, val := range vals {
+		res.Index(i).Set(val)
+	}
+	return res
+}
+
+// elemType returns the type of the elements of a slice or map holding the given projected values:
+// t if they may all be assigned to it, their type if they share the same or the empty interface
+// type otherwise.
+func elemType(t reflect.Type, vals []reflect.Value) reflect.Type {
+	assignable, common := true, reflect.Type(nil)
+	for i, val := range vals {
+		assignable = assignable && val.Type().AssignableTo(t)
+		if i == 0 {
+			common = val.Type()
+		} else if common != val.Type() {
+			common = interfaceType
+		}
+	}
+	if assignable {
+		return t
+	}
+	return common
+}
+
+// indirect returns the type pointed to by t if t is a pointer type, t otherwise.
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// memberName returns the name of the member held by the given struct field.
+func memberName(f reflect.StructField) string {
+	if tag := f.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// isOpaque returns true if the values of type t are encoded using a custom marshaler and thus
+// must not be projected.
+func isOpaque(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	for _, m := range []reflect.Type{jsonMarshalerType, textMarshalerType, xmlMarshalerType} {
+		if t.Implements(m) || pt.Implements(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNil returns true if v is a nil pointer, interface, map or slice.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RenderCollection", func() {
+	type user struct {
+		ID    int     `json:"id"`
+		Name  *string `json:"name,omitempty"`
+		Email *string `json:"email,omitempty"`
+		Admin bool    `json:"-"`
+	}
+	var views map[string]goa.View
+	var selector func(interface{}) string
+
+	BeforeEach(func() {
+		views = map[string]goa.View{
+			"default": {"id", "name"},
+			"admin":   {"id", "name", "email"},
+		}
+		selector = func(item interface{}) string {
+			if item.(*user).Admin {
+				return "admin"
+			}
+			return "default"
+		}
+	})
+
+	It("renders each item with the selected view", func() {
+		ann, annEmail, bob, bobEmail := "ann", "ann@example.com", "bob", "bob@example.com"
+		items := []*user{
+			{ID: 1, Name: &ann, Email: &annEmail, Admin: true},
+			{ID: 2, Name: &bob, Email: &bobEmail},
+		}
+		res, err := goa.RenderCollection(items, views, selector)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(`[{"id":1,"name":"ann","email":"ann@example.com"},{"id":2,"name":"bob"}]`))
+		Ω(items[1].Email).Should(Equal(&bobEmail))
+	})
+
+	It("fails on unknown views", func() {
+		selector = func(interface{}) string { return "tiny" }
+		_, err := goa.RenderCollection([]*user{{ID: 1}}, views, selector)
+		Ω(err).Should(MatchError(`unknown view "tiny" for collection item 0`))
+	})
+})
+
+var _ = Describe("RenderView", func() {
+	type task struct {
+		ID          int     `json:"id"`
+		Title       *string `json:"title,omitempty"`
+		Description *string `json:"description,omitempty"`
+		Priority    int     `json:"priority"`
+	}
+	title, description := "t", "d"
+	t := &task{ID: 1, Title: &title, Description: &description, Priority: 2}
+	views := map[string]goa.View{
+		"default": {"id", "title", "description", "priority"},
+		"tiny":    {"id"},
+	}
+	var req *http.Request
+	var rendered interface{}
+	var err error
+
+	BeforeEach(func() {
+		req, _ = http.NewRequest("GET", "/tasks/1", nil)
+	})
+
+	JustBeforeEach(func() {
+		view := (&goa.RequestData{Request: req}).RequestedView("view")
+		rendered, err = goa.RenderView(t, views, view)
+	})
+
+	It("renders the default view", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rendered).Should(Equal(t))
+	})
+
+	Context("with an Accept header carrying a view parameter", func() {
+		BeforeEach(func() {
+			req.Header.Set("Accept", "application/vnd.acme.task+json; view=tiny")
+		})
+
+		It("renders the tiny view", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := json.Marshal(rendered)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b).Should(MatchJSON(`{"id":1}`))
+		})
+
+		It("omits the members that are not pointers", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := json.Marshal(rendered)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b).ShouldNot(ContainSubstring(`"priority"`))
+			Ω(t.Priority).Should(Equal(2))
+		})
+	})
+
+	Context("with a view given in both the query string and the Accept header", func() {
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "/tasks/1?view=default", nil)
+			req.Header.Set("Accept", "application/vnd.acme.task+json; view=tiny")
+		})
+
+		It("uses the query string", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(rendered).Should(Equal(t))
+		})
+	})
+
+	Context("with an unknown view", func() {
+		BeforeEach(func() {
+			req.Header.Set("Accept", "application/vnd.acme.task+json; view=huge")
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(MatchError(`unknown view "huge"`))
+		})
+	})
+})
+
+var _ = Describe("RenderScoped", func() {
+	type account struct {
+		Plan    *string `json:"plan,omitempty" xml:"plan,omitempty" scopes:"admin"`
+		Balance *int    `json:"balance,omitempty" xml:"balance,omitempty" scopes:"admin,billing"`
+	}
+	type blogger struct {
+		ID      int      `json:"id" xml:"id"`
+		Name    *string  `json:"name,omitempty" xml:"name,omitempty"`
+		Status  *string  `json:"status,omitempty" xml:"status,omitempty" scopes:"admin"`
+		Role    string   `json:"role" xml:"role" scopes:"admin"`
+		Account *account `json:"account,omitempty" xml:"account,omitempty"`
+	}
+	var b *blogger
+
+	BeforeEach(func() {
+		name, status, plan, balance := "ann", "suspended", "pro", 10
+		b = &blogger{ID: 1, Name: &name, Status: &status, Role: "editor", Account: &account{Plan: &plan, Balance: &balance}}
+	})
+
+	It("omits the members requiring scopes not held by readers", func() {
+		res := goa.RenderScoped(b, []string{"read"})
+		js, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(js).Should(MatchJSON(`{"id":1,"name":"ann"}`))
+		Ω(b.Status).ShouldNot(BeNil())
+	})
+
+	It("omits the members that are not pointers", func() {
+		res := goa.RenderScoped(b, []string{"read"})
+		js, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		var members map[string]interface{}
+		Ω(json.Unmarshal(js, &members)).ShouldNot(HaveOccurred())
+		Ω(members).ShouldNot(HaveKey("role"))
+		x, err := xml.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(x)).Should(Equal(`<blogger><id>1</id><name>ann</name></blogger>`))
+	})
+
+	It("filters nested members", func() {
+		res := goa.RenderScoped(b, []string{"admin"})
+		js, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(js).Should(MatchJSON(`{"id":1,"name":"ann","status":"suspended","role":"editor","account":{"plan":"pro"}}`))
+	})
+
+	It("renders the members requiring scopes held by admins", func() {
+		res := goa.RenderScoped(b, []string{"read", "admin", "billing"})
+		Ω(res).Should(Equal(b))
+	})
+
+	It("filters collections", func() {
+		res := goa.RenderScoped([]*blogger{b}, nil)
+		js, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(js).Should(MatchJSON(`[{"id":1,"name":"ann"}]`))
+	})
+})
+
+var _ = Describe("LimitDepth", func() {
+	type author struct {
+		ID   int     `json:"id" xml:"id"`
+		Name *string `json:"name,omitempty" xml:"name,omitempty"`
+	}
+	type comment struct {
+		ID     int     `json:"id" xml:"id"`
+		Author *author `json:"author,omitempty" xml:"author,omitempty"`
+	}
+	type post struct {
+		XMLName  xml.Name   `json:"-" xml:"post"`
+		ID       int        `json:"id" xml:"id"`
+		Comments []*comment `json:"comments,omitempty" xml:"comment,omitempty"`
+	}
+
+	It("replaces the nested objects with their links", func() {
+		name := "ann"
+		p := &post{ID: 1, Comments: []*comment{{ID: 2, Author: &author{ID: 3, Name: &name}}}}
+		res := goa.LimitDepth(p, 1)
+		js, err := json.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(js).Should(MatchJSON(`{"id":1,"comments":[{"id":2,"author":{"id":3}}]}`))
+		b, err := xml.Marshal(res)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`<post><id>1</id><comment><id>2</id><author><id>3</id></author></comment></post>`))
+	})
+
+	It("keeps the type of values that are rendered fully", func() {
+		p := &post{ID: 1, Comments: []*comment{{ID: 2}}}
+		Ω(goa.LimitDepth(p, 1)).Should(Equal(p))
+	})
+})
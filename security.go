@@ -27,6 +27,21 @@ func WithRequiredScopes(ctx context.Context, scopes []string) context.Context {
 	return context.WithValue(ctx, securityScopesKey, scopes)
 }
 
+// ContextGrantedScopes extracts the scopes granted to the caller from the given context. Security
+// middlewares store the scopes held by the caller, e.g. the scopes listed in a JWT claim, so that
+// handlers may use them to filter responses with RenderScoped.
+func ContextGrantedScopes(ctx context.Context) []string {
+	if s := ctx.Value(grantedScopesKey); s != nil {
+		return s.([]string)
+	}
+	return nil
+}
+
+// WithGrantedScopes builds a context containing the scopes granted to the caller.
+func WithGrantedScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, grantedScopesKey, scopes)
+}
+
 // OAuth2Security represents the `oauth2` security scheme. It is instantiated by the generated code
 // accordingly to the use of the different `*Security()` DSL functions and `Security()` in the
 // design.
@@ -1,16 +1,23 @@
 package goa
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 )
@@ -35,9 +42,85 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
-
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+		// RenderDepthParam is the name of the query string parameter clients may use to
+		// limit the depth of rendered response bodies, see LimitDepth. Depth limiting is
+		// disabled if empty.
+		RenderDepthParam string
+		// EnvelopeErrors causes Send to wrap the bodies of responses with status code 400 or
+		// more that are not already errors into error responses, so that all error responses
+		// share the same shape regardless of how they were produced. Success responses are
+		// left untouched.
+		EnvelopeErrors bool
+		// ProblemDetails causes Send to render the bodies of responses with status code 400
+		// or more that are errors or strings as RFC 7807 "application/problem+json"
+		// documents, see ProblemDetails. The error message, including the validation errors
+		// produced when loading the request, is used as the problem detail. Bodies of other
+		// types are left untouched. ProblemDetails takes precedence over EnvelopeErrors.
+		ProblemDetails bool
+		// NoContentForNilBody causes Send to respond with status code 204 No Content instead of
+		// 200 OK when the response body is nil.
+		NoContentForNilBody bool
+		// PrettyJSON causes JSON response bodies to be indented with two spaces to make them
+		// easier to read when debugging. It is off by default as indenting comes at a cost.
+		PrettyJSON bool
+		// UseNumber causes the request decoders that support it such as the encoding/json
+		// decoder to decode the numbers held in generic payload values (interface{}) as
+		// json.Number rather than float64 so that integers larger than 2^53 keep their
+		// precision. It is off by default as actions must then handle json.Number values.
+		UseNumber bool
+		// CompressResponses causes response bodies to be compressed using gzip or deflate
+		// when the request Accept-Encoding header advertises support for it. Responses
+		// whose Content-Encoding header is set by the handler are left untouched so that
+		// already compressed content is not compressed twice.
+		CompressResponses bool
+		// CompressMinSize is the minimum size in bytes of the response bodies compressed
+		// when CompressResponses is set. Smaller bodies are sent as is since compressing
+		// them costs more than it saves. The default value 0 compresses all bodies.
+		CompressMinSize int
+		// DecompressRequests causes request bodies sent with a gzip or deflate
+		// Content-Encoding header to be decompressed before they are decoded. The length
+		// of the decompressed body is capped by MaxDecompressedLength to protect against
+		// decompression bombs.
+		DecompressRequests bool
+		// MaxDecompressedLength is the maximum length of request bodies once decompressed,
+		// see DecompressRequests. Defaults to DefaultMaxDecompressedLength. The request body
+		// length limit applies instead if it is lower (see RequestBudget and
+		// Controller.MaxRequestBodyLength).
+		MaxDecompressedLength int64
+		// ServerTiming causes responses to include a "Server-Timing" header reporting the
+		// time spent loading the request payload ("payload"), running the middleware chain
+		// and action ("controller") and handling the request overall ("total"). Durations
+		// are measured when the response header is written.
+		ServerTiming bool
+		// ValidateResponses causes Send to validate the bodies of non-error responses that
+		// implement a Validate method, such as the media types generated by goagen for each
+		// view, before sending them. Responses that fail to validate are replaced with a 500
+		// invalid_response error listing the offending attributes, e.g. view members that
+		// the controller did not set. This is intended to catch bugs during development,
+		// validating every response comes at a cost.
+		ValidateResponses bool
+		// StrictNegotiation causes the contexts created by the generated code to fail with a
+		// 406 Not Acceptable error when the request Accept header does not match any of the
+		// media types of the action responses, see RequestData.NegotiateMediaType. Such
+		// requests are handled otherwise and Send encodes the response using the default
+		// encoder.
+		StrictNegotiation bool
+		// MaxMultipartMemory is the maximum number of bytes of multipart/form-data request
+		// bodies kept in memory when decoding payloads, the remainder of the uploaded files is
+		// stored in temporary files. Defaults to DefaultMaxMultipartMemory. This does not
+		// bound the length of request bodies, see RequestBudget,
+		// Controller.MaxRequestBodyLength and LimitPayload for that.
+		MaxMultipartMemory int64
+		// RepeatedParams is the policy used by the generated code to resolve the parameters
+		// that accept a single value when the request specifies them multiple times, see
+		// ScalarParam. Defaults to ParamFirst.
+		RepeatedParams RepeatedParamPolicy
+
+		middleware     []Middleware       // Middleware chain
+		cancel         context.CancelFunc // Service context cancel signal trigger
+		maxBodyLength  int64              // Maximum length of request bodies, see RequestBudget
+		bodyReadBudget time.Duration      // Maximum time spent reading request bodies
+		boolStrings    map[string]bool    // Additional boolean strings, see RegisterBoolStrings
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -51,6 +134,10 @@ type (
 		// MaxRequestBodyLength is the maximum length read from request bodies.
 		// Set to 0 to remove the limit altogether. Defaults to 1GB.
 		MaxRequestBodyLength int64
+		// MaxPayloadDepth is the maximum nesting depth of JSON and XML request bodies.
+		// Requests whose body is nested deeper are rejected with a 400 response.
+		// Set to 0 to remove the limit altogether. Defaults to 32.
+		MaxPayloadDepth int
 
 		middleware []Middleware // Controller specific middleware if any
 	}
@@ -90,7 +177,8 @@ func New(name string) *Service {
 		notFoundHandler Handler
 	)
 
-	// Setup default NotFound handler
+	// Setup default NotFound handler, the handler also responds to OPTIONS requests made to
+	// known paths so that service middleware such as CORS may handle them.
 	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
 		if resp := ContextResponse(ctx); resp != nil && resp.Written() {
 			return
@@ -98,7 +186,15 @@ func New(name string) *Service {
 		// Use closure to do lazy computation of middleware chain so all middlewares are
 		// registered.
 		if notFoundHandler == nil {
-			notFoundHandler = func(_ context.Context, _ http.ResponseWriter, req *http.Request) error {
+			notFoundHandler = func(_ context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if allow := rw.Header().Get("Allow"); allow != "" {
+					if req.Method == "OPTIONS" {
+						rw.WriteHeader(http.StatusOK)
+						return nil
+					}
+					msg := fmt.Sprintf("method %s not allowed for %s", req.Method, req.URL.Path)
+					return ErrMethodNotAllowed(msg, "method", req.Method, "allow", allow)
+				}
 				return ErrNotFound(req.URL.Path)
 			}
 			chain := service.middleware
@@ -110,7 +206,11 @@ func New(name string) *Service {
 		ctx := NewContext(service.Context, rw, req, params)
 		err := notFoundHandler(ctx, ContextResponse(ctx), req)
 		if !ContextResponse(ctx).Written() {
-			service.Send(ctx, 404, err)
+			status := 404
+			if e, ok := err.(ServiceError); ok && e.ResponseStatus() == http.StatusMethodNotAllowed {
+				status = e.ResponseStatus()
+			}
+			service.Send(ctx, status, err)
 		}
 	})
 
@@ -173,20 +273,191 @@ func (service *Service) NewController(name string) *Controller {
 		Service:              service,
 		Context:              context.WithValue(service.Context, ctrlKey, name),
 		MaxRequestBodyLength: 1073741824, // 1 GB
+		MaxPayloadDepth:      32,
 	}
 }
 
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders. It uses the default service encoder if no match is found. The response Content-Type
+// header is set to the negotiated content type unless it already specifies the same format (e.g.
+// "application/vnd.goa.example+json" for "application/json"). Send returns a NotAcceptableError
+// without writing the response if no encoder is acceptable and there is no default encoder,
+// error responses that cannot be encoded are sent without a body.
+// Responses with status code 204 No Content or 304 Not Modified are sent without a body, the
+// Content-Type header is also removed from 204 responses. Send writes an empty body rather than
+// encoding nil bodies (e.g. as "null" in JSON), see also NoContentForNilBody. Nil pointers such
+// as the nil media types returned by the generated code are handled as nil bodies.
 func (service *Service) Send(ctx context.Context, code int, body interface{}) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	if v := reflect.ValueOf(body); v.Kind() == reflect.Ptr && v.IsNil() {
+		body = nil
+	}
+	if body == nil && code == http.StatusOK && service.NoContentForNilBody {
+		code = http.StatusNoContent
+	}
+	if !bodyAllowed(code) {
+		if code == http.StatusNoContent {
+			r.Header().Del("Content-Type")
+		}
+		r.WriteHeader(code)
+		return nil
+	}
+	if service.ValidateResponses && code < 400 {
+		if v, ok := body.(validator); ok {
+			if err := v.Validate(); err != nil {
+				LogError(ctx, "invalid response", "err", err)
+				r.Header().Del("Content-Type")
+				return service.Send(ctx, http.StatusInternalServerError, invalidResponseError(err))
+			}
+		}
+	}
+	if service.ProblemDetails && code >= 400 {
+		if pd, ok := problemDetails(code, body); ok {
+			r.Header().Set("Content-Type", ProblemMediaIdentifier)
+			body = pd
+		}
+	} else if service.EnvelopeErrors && code >= 400 {
+		body = envelopeError(code, body)
+	}
+	if m, ok := body.(MultiError); ok {
+		// Encoders other than JSON and XML cannot serialize the error interface values.
+		body = m.Responses()
+	}
+	if body != nil {
+		ct, err := service.Encoder.Negotiate(ContextRequest(ctx).Header.Get("Accept"))
+		if err != nil {
+			if code < 400 {
+				return err
+			}
+			// The error cannot be encoded in any acceptable media type, send the status only.
+			r.WriteHeader(code)
+			return nil
+		}
+		if current := r.Header().Get("Content-Type"); ct != "*/*" &&
+			(current == "" || mediaTypeFormat(current) != mediaTypeFormat(ct)) {
+			r.Header().Set("Content-Type", ct)
+		}
+	}
 	r.WriteHeader(code)
+	if body == nil {
+		return nil
+	}
 	return service.EncodeResponse(ctx, body)
 }
 
+// validator is the interface implemented by the generated types that support validation.
+type validator interface {
+	Validate() error
+}
+
+// invalidResponseError wraps the error returned when validating a response body into an
+// ErrInvalidResponse error that preserves the individual validation errors.
+func invalidResponseError(err error) error {
+	e := asErrorResponse(err)
+	res := ErrInvalidResponse("response does not match the design: " + e.Detail).(*ErrorResponse)
+	res.Errors = e.Errors
+	return res
+}
+
+// bodyAllowed returns false if responses with the given status code must not include a body as
+// per RFC 7230 section 3.3.
+func bodyAllowed(code int) bool {
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
+}
+
+// envelopeError wraps the body of an error response into an ErrorResponse. String and error
+// bodies are used as the error detail, other bodies are stored in the error metadata. MultiError
+// bodies produce the list of the envelopes of each error.
+func envelopeError(code int, body interface{}) interface{} {
+	if _, ok := body.(*ErrorResponse); ok {
+		return body
+	}
+	class := NewErrorClass(strings.ToLower(strings.Replace(http.StatusText(code), " ", "_", -1)), code)
+	switch actual := body.(type) {
+	case nil:
+		return class(http.StatusText(code))
+	case MultiError:
+		res := make(ErrorResponses, len(actual))
+		for i, err := range actual {
+			if e, ok := err.(*ErrorResponse); ok {
+				res[i] = e
+			} else {
+				res[i] = class(err).(*ErrorResponse)
+			}
+		}
+		return res
+	case string, error:
+		return class(actual)
+	default:
+		return class(http.StatusText(code), "body", body)
+	}
+}
+
+// problemDetails converts the body of an error response into a ProblemDetails. It returns false
+// if the body is neither nil, a string nor an error.
+func problemDetails(code int, body interface{}) (*ProblemDetails, bool) {
+	pd := &ProblemDetails{Type: "about:blank", Title: http.StatusText(code), Status: code}
+	switch actual := body.(type) {
+	case nil:
+	case string:
+		pd.Detail = actual
+	case *ErrorResponse:
+		pd.Detail = actual.Detail
+		pd.Code = actual.Code
+		pd.ID = actual.ID
+		pd.Meta = actual.Meta
+		pd.Errors = actual.Errors
+	case MultiError:
+		return problemDetails(code, asErrorResponse(actual))
+	case ServiceError:
+		pd.Detail = actual.Error()
+		pd.ID = actual.Token()
+	case error:
+		pd.Detail = actual.Error()
+	default:
+		return nil, false
+	}
+	return pd, true
+}
+
+// RespondUnprocessable sends a 422 Unprocessable Entity response that lists the given field
+// errors. Use it to report requests that pass the design validations but violate business rules.
+func (service *Service) RespondUnprocessable(ctx context.Context, errors []FieldError) error {
+	return service.Send(ctx, 422, UnprocessableEntityError(errors))
+}
+
+// RespondBytes sends a HTTP response whose body consists of the given bytes written verbatim,
+// bypassing the response encoders. It sets the Content-Type header to contentType and the
+// Content-Length header to the length of body.
+func (service *Service) RespondBytes(ctx context.Context, code int, contentType string, body []byte) error {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return fmt.Errorf("no response data in context")
+	}
+	r.Header().Set("Content-Type", contentType)
+	r.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	r.WriteHeader(code)
+	_, err := r.Write(body)
+	return err
+}
+
+// RequestBudget bounds the resources any single request may consume when its body is loaded.
+// Request bodies longer than maxBytes are rejected with a 413 response and requests whose body
+// takes longer than readTimeout to read are rejected with a 408 response. A zero value disables
+// the corresponding limit. The size limit applies in addition to the controllers
+// MaxRequestBodyLength, the smallest of the two wins. The read timeout is implemented with a
+// connection read deadline (see http.ResponseController) and thus only applies to requests served
+// by a server whose response writers support it, such as the net/http server, and requires Go
+// 1.20 or later. The deadline is only armed while the body is loaded, it does not apply to
+// requests with no body or with a chunked body nor to the action itself.
+func (service *Service) RequestBudget(maxBytes int64, readTimeout time.Duration) {
+	service.maxBodyLength = maxBytes
+	service.bodyReadBudget = readTimeout
+}
+
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
 func (service *Service) ServeFiles(path, filename string) error {
 	ctrl := service.NewController("FileServer")
@@ -199,7 +470,13 @@ func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 	body, contentType := req.Body, req.Header.Get("Content-Type")
 	defer body.Close()
 
-	if err := service.Decoder.Decode(v, body, contentType); err != nil {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "multipart/form-data" {
+		if err := service.decodeMultipart(req, v); err != nil {
+			return fmt.Errorf("failed to decode request body with content type %#v: %s", contentType, err)
+		}
+		return nil
+	}
+	if err := service.Decoder.decode(v, body, contentType, service.UseNumber); err != nil {
 		return fmt.Errorf("failed to decode request body with content type %#v: %s", contentType, err)
 	}
 
@@ -207,10 +484,56 @@ func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 }
 
 // EncodeResponse uses the HTTP encoder to marshal and write the response body based on the request
-// Accept header.
+// Accept header. The response body is pruned with LimitDepth if RenderDepthParam is set and the
+// request specifies a valid depth.
 func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error {
-	accept := ContextRequest(ctx).Header.Get("Accept")
-	return service.Encoder.Encode(v, ContextResponse(ctx), accept)
+	req := ContextRequest(ctx)
+	if service.RenderDepthParam != "" && v != nil {
+		if d, err := strconv.Atoi(req.URL.Query().Get(service.RenderDepthParam)); err == nil && d >= 0 {
+			v = LimitDepth(v, d)
+		}
+	}
+	accept := req.Header.Get("Accept")
+	resp := ContextResponse(ctx)
+	if !service.PrettyJSON {
+		return service.Encoder.Encode(v, resp, accept)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "" && !strings.Contains(ct, "json") {
+		return service.Encoder.Encode(v, resp, accept)
+	}
+	var buf bytes.Buffer
+	if err := service.Encoder.Encode(v, &buf, accept); err != nil {
+		return err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		// Not JSON after all, write the body as is.
+		_, err = resp.Write(buf.Bytes())
+		return err
+	}
+	_, err := resp.Write(indented.Bytes())
+	return err
+}
+
+// Match attaches request matchers to the route mounted for the given HTTP method and path. This
+// makes it possible to restrict routes further than what the design supports, for example to
+// requests made to a given host or carrying a given header. Requests that do not satisfy all the
+// matchers are handled as if the route did not exist. Match must be called after the controller
+// is mounted:
+//
+//	app.MountBottleController(service, c)
+//	service.Match("GET", "/bottles", goa.HeaderMatcher("X-Api-Version", "2"))
+//
+// Match returns an error if no route is mounted for method and path or if the service mux does
+// not support matchers.
+func (service *Service) Match(method, path string, matchers ...RequestMatcher) error {
+	m, ok := service.Mux.(interface {
+		Match(string, string, ...RequestMatcher) error
+	})
+	if !ok {
+		return fmt.Errorf("mux %T does not support request matchers", service.Mux)
+	}
+	return m.Match(method, path, matchers...)
 }
 
 // ServeFiles replies to the request with the contents of the named file or directory. See
@@ -247,6 +570,8 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	var handler Handler
 
 	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		start := time.Now()
+
 		// Build handler middleware chains on first invocation
 		if handler == nil {
 			handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -265,17 +590,101 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 		// Build context
 		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
 
+		// Compress response body if enabled and accepted by the client
+		if ctrl.Service.CompressResponses {
+			resp := ContextResponse(ctx)
+			resp.Header().Add("Vary", "Accept-Encoding")
+			if enc := negotiateEncoding(req.Header.Get("Accept-Encoding")); enc != "" {
+				cw := newCompressWriter(resp.SwitchWriter(nil), enc, ctrl.Service.CompressMinSize)
+				resp.SwitchWriter(cw)
+				defer cw.Close()
+				ctx = context.WithValue(ctx, compressKey, cw)
+			}
+		}
+
 		// Protect against request bodies with unreasonable length
-		if ctrl.MaxRequestBodyLength > 0 {
-			req.Body = http.MaxBytesReader(rw, req.Body, ctrl.MaxRequestBodyLength)
+		maxLength := ctrl.MaxRequestBodyLength
+		if l := ctrl.Service.maxBodyLength; l > 0 && (maxLength == 0 || l < maxLength) {
+			maxLength = l
+		}
+		if maxLength > 0 {
+			req.Body = http.MaxBytesReader(rw, req.Body, maxLength)
+		}
+
+		// Protect against request bodies that take too long to read, the read deadline is
+		// only armed while the body is loaded below.
+		var (
+			tr *timeoutReader
+			rc *http.ResponseController
+		)
+		budget := ctrl.Service.bodyReadBudget
+		if budget > 0 && req.ContentLength > 0 && unm != nil {
+			rc = http.NewResponseController(rw)
+			tr = &timeoutReader{ReadCloser: req.Body}
+			req.Body = tr
+		}
+
+		// Decompress request body if enabled
+		var cr *decompressReader
+		if ctrl.Service.DecompressRequests && req.Body != nil {
+			if enc := requestEncoding(req.Header.Get("Content-Encoding")); enc != "" {
+				max := ctrl.Service.MaxDecompressedLength
+				if max <= 0 {
+					max = DefaultMaxDecompressedLength
+				}
+				if maxLength > 0 && maxLength < max {
+					max = maxLength
+				}
+				cr = &decompressReader{ReadCloser: req.Body, encoding: enc, max: max}
+				req.Body = cr
+				req.Header.Del("Content-Encoding")
+			}
+		}
+
+		// Protect against request bodies with unreasonable nesting
+		var dr *depthReader
+		if ctrl.MaxPayloadDepth > 0 && req.Body != nil {
+			if ct := req.Header.Get("Content-Type"); isJSON(ct) {
+				dr = &depthReader{ReadCloser: req.Body, max: ctrl.MaxPayloadDepth}
+				req.Body = dr
+			} else if strings.Contains(ct, "xml") {
+				dr = &depthReader{ReadCloser: req.Body, max: ctrl.MaxPayloadDepth, xml: true}
+				req.Body = dr
+			}
 		}
 
 		// Load body if any
+		var payloadDur time.Duration
 		if req.ContentLength > 0 && unm != nil {
-			if err := unm(ctx, ctrl.Service, req); err != nil {
+			payloadStart := time.Now()
+			err := func() error {
+				if rc != nil && rc.SetReadDeadline(time.Now().Add(budget)) == nil {
+					defer rc.SetReadDeadline(time.Time{})
+				}
+				return unm(ctx, ctrl.Service, req)
+			}()
+			payloadDur = time.Since(payloadStart)
+			if err != nil {
 				if err.Error() == "http: request body too large" {
-					msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
+					msg := fmt.Sprintf("request body length exceeds %d bytes", maxLength)
+					err = ErrRequestBodyTooLarge(msg)
+				} else if tr != nil && tr.timedOut {
+					// Close the connection rather than wait for the remainder of the
+					// body once the response is written.
+					ContextResponse(ctx).Header().Set("Connection", "close")
+					msg := fmt.Sprintf("request body not read within %s", budget)
+					err = ErrRequestTimeout(msg)
+				} else if cr != nil && cr.exceeded {
+					msg := fmt.Sprintf("decompressed request body length exceeds %d bytes", cr.max)
 					err = ErrRequestBodyTooLarge(msg)
+				} else if cr != nil && cr.corrupt != nil {
+					msg := fmt.Sprintf("invalid %s request body: %s", cr.encoding, cr.corrupt)
+					err = ErrBadRequest(msg)
+				} else if dr != nil && dr.exceeded {
+					msg := fmt.Sprintf("request body nesting exceeds %d levels", ctrl.MaxPayloadDepth)
+					err = ErrBadRequest(msg)
+				} else if _, ok := err.(*payloadTooLargeError); ok {
+					err = ErrRequestBodyTooLarge(err)
 				} else {
 					err = ErrBadRequest(err)
 				}
@@ -283,6 +692,23 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 			}
 		}
 
+		// Report timings when the response header is written
+		if ctrl.Service.ServerTiming {
+			resp := ContextResponse(ctx)
+			handlerStart := time.Now()
+			resp.SwitchWriter(&headerHookWriter{
+				ResponseWriter: resp.SwitchWriter(nil),
+				hook: func() {
+					now := time.Now()
+					if payloadDur > 0 {
+						resp.AddServerTiming("payload", payloadDur, "")
+					}
+					resp.AddServerTiming("controller", now.Sub(handlerStart), "")
+					resp.AddServerTiming("total", now.Sub(start), "")
+				},
+			})
+		}
+
 		// Invoke handler
 		if err := handler(ctx, ContextResponse(ctx), req); err != nil {
 			LogError(ctx, "uncaught error", "err", err)
@@ -359,6 +785,162 @@ func (ctrl *Controller) FileHandler(path, filename string) Handler {
 	}
 }
 
+// LimitPayload returns an unmarshaler that rejects request bodies longer than maxBytes with a 413
+// response and otherwise invokes unm. It makes it possible to set a limit specific to an action in
+// addition to the service and controller wide limits (see RequestBudget and
+// Controller.MaxRequestBodyLength), the code generated for actions whose design sets a maximum
+// payload length uses it.
+func LimitPayload(maxBytes int64, unm Unmarshaler) Unmarshaler {
+	return func(ctx context.Context, service *Service, req *http.Request) error {
+		if req.ContentLength > maxBytes {
+			return &payloadTooLargeError{max: maxBytes}
+		}
+		lr := &lengthReader{ReadCloser: req.Body, max: maxBytes}
+		req.Body = lr
+		err := unm(ctx, service, req)
+		if lr.exceeded {
+			return &payloadTooLargeError{max: maxBytes}
+		}
+		return err
+	}
+}
+
+// payloadTooLargeError is the error returned by the unmarshalers created with LimitPayload when
+// the request body is too long.
+type payloadTooLargeError struct {
+	max int64
+}
+
+// Error returns the error message.
+func (e *payloadTooLargeError) Error() string {
+	return fmt.Sprintf("request body length exceeds %d bytes", e.max)
+}
+
+// lengthReader is a reader that fails once more than max bytes have been read.
+type lengthReader struct {
+	io.ReadCloser
+	max      int64
+	n        int64
+	exceeded bool
+}
+
+// Read reads from the underlying reader and keeps track of the number of bytes read.
+func (r *lengthReader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, &payloadTooLargeError{max: r.max}
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	if r.n > r.max {
+		r.exceeded = true
+		return 0, &payloadTooLargeError{max: r.max}
+	}
+	return n, err
+}
+
+// timeoutReader is a reader that records whether reading failed because the connection read
+// deadline set for the request body read budget expired.
+type timeoutReader struct {
+	io.ReadCloser
+	timedOut bool
+}
+
+// Read reads from the underlying reader and records read deadline errors.
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if ne, ok := err.(net.Error); (ok && ne.Timeout()) || errors.Is(err, os.ErrDeadlineExceeded) {
+			r.timedOut = true
+		}
+	}
+	return n, err
+}
+
+// headerHookWriter is a response writer that calls a hook right before the response header is
+// written so that the hook may still add headers.
+type headerHookWriter struct {
+	http.ResponseWriter
+	hook func()
+	done bool
+}
+
+// WriteHeader calls the hook and the underlying writer.
+func (w *headerHookWriter) WriteHeader(status int) {
+	if !w.done {
+		w.done = true
+		w.hook()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write calls the hook if the header was not written yet and the underlying writer.
+func (w *headerHookWriter) Write(b []byte) (int, error) {
+	if !w.done {
+		w.done = true
+		w.hook()
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// errPayloadTooDeep is the error returned by depthReader when the maximum depth is exceeded.
+var errPayloadTooDeep = errors.New("request body nesting too deep")
+
+// depthReader is a reader that fails once the nesting depth of the JSON document it reads
+// exceeds a maximum. It keeps track of string literals so that brackets appearing in strings
+// are not counted. The depth of XML documents is not tracked by the reader, the XML decoder
+// checks it against max instead as it walks the document elements, see xmlDecoder.
+type depthReader struct {
+	io.ReadCloser
+	max      int
+	xml      bool
+	depth    int
+	inString bool
+	escaped  bool
+	exceeded bool
+}
+
+// Read reads from the underlying reader and updates the nesting depth.
+func (r *depthReader) Read(p []byte) (int, error) {
+	if r.exceeded {
+		return 0, errPayloadTooDeep
+	}
+	n, err := r.ReadCloser.Read(p)
+	if r.xml {
+		return n, err
+	}
+	for _, b := range p[:n] {
+		if r.inString {
+			switch {
+			case r.escaped:
+				r.escaped = false
+			case b == '\\':
+				r.escaped = true
+			case b == '"':
+				r.inString = false
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			r.inString = true
+		case '{', '[':
+			r.depth++
+			if r.depth > r.max {
+				r.exceeded = true
+				return 0, errPayloadTooDeep
+			}
+		case '}', ']':
+			r.depth--
+		}
+	}
+	return n, err
+}
+
+// isJSON returns true if the given content type is empty or designates a JSON document.
+func isJSON(contentType string) bool {
+	return contentType == "" || strings.Contains(contentType, "json")
+}
+
 var replacer = strings.NewReplacer(
 	"&", "&amp;",
 	"<", "&lt;",
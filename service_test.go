@@ -1,15 +1,28 @@
 package goa_test
 
 import (
+	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/encoding/form"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -35,83 +48,940 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("Match", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var called bool
+
+		BeforeEach(func() {
+			called = false
+			req, _ = http.NewRequest("GET", "/bottles", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				called = true
+				return nil
+			}
+			s.Mux.Handle("GET", "/bottles", ctrl.MuxHandler("list", handler, nil))
+			Ω(s.Match("GET", "/bottles", goa.HeaderMatcher("X-Api-Version", "2"))).ShouldNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			s.Mux.ServeHTTP(rw, req)
+		})
+
+		It("rejects requests that do not satisfy the matchers", func() {
+			Ω(called).Should(BeFalse())
+			Ω(rw.Status).Should(Equal(404))
+		})
+
+		Context("with a request satisfying the matchers", func() {
+			BeforeEach(func() {
+				req.Header.Set("X-Api-Version", "2")
+			})
+
+			It("handles the request", func() {
+				Ω(called).Should(BeTrue())
+			})
+		})
+
+		It("fails for routes that are not mounted", func() {
+			Ω(s.Match("POST", "/bottles")).Should(HaveOccurred())
+		})
+	})
+
 	Describe("NotFound", func() {
 		var rw *TestResponseWriter
 		var req *http.Request
 
 		BeforeEach(func() {
-			req, _ = http.NewRequest("GET", "/foo", nil)
-			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			req, _ = http.NewRequest("GET", "/foo", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		})
+
+		JustBeforeEach(func() {
+			s.Mux.ServeHTTP(rw, req)
+		})
+
+		It("handles requests with no registered handlers", func() {
+			Ω(string(rw.Body)).Should(MatchRegexp(`{"id":".*","code":"not_found","status":404,"detail":"/foo"}` + "\n"))
+		})
+
+		Context("with a method that has no handler", func() {
+			BeforeEach(func() {
+				ctrl := s.NewController("test")
+				handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					rw.WriteHeader(200)
+					return nil
+				}
+				s.Mux.Handle("GET", "/foo", ctrl.MuxHandler("show", handler, nil))
+				req, _ = http.NewRequest("DELETE", "/foo", nil)
+			})
+
+			It("responds with 405 and the allowed methods", func() {
+				Ω(rw.Status).Should(Equal(405))
+				Ω(rw.ParentHeader.Get("Allow")).Should(Equal("GET, HEAD, OPTIONS"))
+				Ω(string(rw.Body)).Should(ContainSubstring(`"code":"method_not_allowed","status":405,"detail":"method DELETE not allowed for /foo"`))
+			})
+
+			Context("using OPTIONS", func() {
+				BeforeEach(func() {
+					req, _ = http.NewRequest("OPTIONS", "/foo", nil)
+				})
+
+				It("responds with 200 and the allowed methods", func() {
+					Ω(rw.Status).Should(Equal(200))
+					Ω(rw.ParentHeader.Get("Allow")).Should(Equal("GET, HEAD, OPTIONS"))
+					Ω(rw.Body).Should(BeEmpty())
+				})
+			})
+		})
+
+		Context("with middleware", func() {
+			middlewareCalled := false
+
+			BeforeEach(func() {
+				s.Use(TMiddleware(&middlewareCalled))
+				// trigger finalize
+				ctrl := s.NewController("test")
+				ctrl.MuxHandler("", nil, nil)
+			})
+
+			It("calls the middleware", func() {
+				Ω(middlewareCalled).Should(BeTrue())
+			})
+		})
+
+		Context("middleware and multiple controllers", func() {
+			middlewareCalled := 0
+
+			BeforeEach(func() {
+				s.Use(CMiddleware(&middlewareCalled))
+				ctrl := s.NewController("test")
+				ctrl.MuxHandler("/foo", nil, nil)
+				ctrl.MuxHandler("/bar", nil, nil)
+			})
+
+			It("calls the middleware once", func() {
+				Ω(middlewareCalled).Should(Equal(1))
+			})
+		})
+	})
+
+	Describe("MaxRequestBodyLength", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			body := bytes.NewBuffer([]byte{'"', '2', '3', '4', '"'})
+			req, _ = http.NewRequest("GET", "/foo", body)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			ctrl.MaxRequestBodyLength = 4
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				_, err := ioutil.ReadAll(req.Body)
+				return err
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(400)
+				rw.Write([]byte(goa.ContextError(ctx).Error()))
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("testMax", handler, unmarshaler)
+		})
+
+		JustBeforeEach(func() {
+			muxHandler(rw, req, nil)
+		})
+
+		It("prevents reading more bytes", func() {
+			Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 413 request_too_large: request body length exceeds 4 bytes`))
+		})
+	})
+
+	Describe("RequestBudget", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			s.RequestBudget(4, 50*time.Millisecond)
+			req, _ = http.NewRequest("POST", "/foo", bytes.NewBufferString(`"23"`))
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				_, err := ioutil.ReadAll(req.Body)
+				return err
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					rw.WriteHeader(400)
+					rw.Write([]byte(err.Error()))
+					return nil
+				}
+				rw.WriteHeader(200)
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("testBudget", handler, unmarshaler)
+		})
+
+		JustBeforeEach(func() {
+			muxHandler(rw, req, nil)
+		})
+
+		It("accepts requests within budget", func() {
+			Ω(rw.Status).Should(Equal(200))
+		})
+
+		Context("with an oversized body", func() {
+			BeforeEach(func() {
+				req.Body = ioutil.NopCloser(bytes.NewBufferString(`"234"`))
+			})
+
+			It("rejects the request", func() {
+				Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 413 request_too_large: request body length exceeds 4 bytes`))
+			})
+		})
+
+		Context("with a slow body", func() {
+			It("rejects the request", func() {
+				server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					muxHandler(rw, req, nil)
+				}))
+				defer server.Close()
+				conn, err := net.Dial("tcp", server.Listener.Addr().String())
+				Ω(err).ShouldNot(HaveOccurred())
+				defer conn.Close()
+				_, err = conn.Write([]byte("POST /foo HTTP/1.1\r\nHost: goa.design\r\nContent-Length: 4\r\n\r\n\"2"))
+				Ω(err).ShouldNot(HaveOccurred())
+				resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+				Ω(err).ShouldNot(HaveOccurred())
+				body, err := ioutil.ReadAll(resp.Body)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(body)).Should(MatchRegexp(`\[.*\] 408 request_timeout: request body not read within 50ms`))
+			})
+		})
+
+		Context("with a slow handler and no body", func() {
+			It("does not cancel the request", func() {
+				s.RequestBudget(0, 50*time.Millisecond)
+				var reqErr error
+				ctrl := s.NewController("test")
+				handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					time.Sleep(200 * time.Millisecond)
+					reqErr = req.Context().Err()
+					rw.WriteHeader(200)
+					return nil
+				}
+				muxHandler = ctrl.MuxHandler("testSlow", handler, nil)
+				server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					muxHandler(rw, req, nil)
+				}))
+				defer server.Close()
+				resp, err := http.Get(server.URL + "/foo")
+				Ω(err).ShouldNot(HaveOccurred())
+				resp.Body.Close()
+				Ω(resp.StatusCode).Should(Equal(200))
+				Ω(reqErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("LimitPayload", func() {
+		type todo struct {
+			Title string `json:"title" xml:"title" form:"title"`
+		}
+
+		var rw *TestResponseWriter
+		var contentType, body string
+		var contentLength int64
+		var payload *todo
+
+		BeforeEach(func() {
+			s.Decoder.Register(goa.NewXMLDecoder, "application/xml")
+			s.Decoder.Register(form.NewDecoder, "application/x-www-form-urlencoded")
+			payload = nil
+			contentLength = 0
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/todos", strings.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			if contentLength > 0 {
+				req.ContentLength = contentLength
+			}
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var p todo
+				if err := service.DecodeRequest(req, &p); err != nil {
+					return err
+				}
+				payload = &p
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					return s.Send(ctx, err.(goa.ServiceError).ResponseStatus(), err)
+				}
+				rw.WriteHeader(201)
+				return nil
+			}
+			unm := goa.LimitPayload(28, unmarshaler)
+			s.NewController("todo").MuxHandler("create", handler, unm)(rw, req, nil)
+		})
+
+		for _, enc := range []struct{ contentType, small, large string }{
+			{"application/json", `{"title":"docs"}`, `{"title":"write the docs now"}`},
+			{"application/xml", `<t><title>docs</title></t>`, `<t><title>write the docs</title></t>`},
+			{"application/x-www-form-urlencoded", "title=docs", "title=write+the+docs+and+tests"},
+		} {
+			enc := enc
+			Context("with a "+enc.contentType+" body", func() {
+				BeforeEach(func() {
+					contentType = enc.contentType
+					body = enc.small
+				})
+
+				It("loads payloads within the limit", func() {
+					Ω(rw.Status).Should(Equal(201))
+					Ω(payload).Should(Equal(&todo{Title: "docs"}))
+				})
+
+				Context("exceeding the limit", func() {
+					BeforeEach(func() {
+						body = enc.large
+					})
+
+					It("responds with 413", func() {
+						Ω(payload).Should(BeNil())
+						Ω(rw.Status).Should(Equal(413))
+						Ω(string(rw.Body)).Should(ContainSubstring("request body length exceeds 28 bytes"))
+					})
+
+					Context("and an understated content length", func() {
+						BeforeEach(func() {
+							contentLength = 8
+						})
+
+						It("responds with 413", func() {
+							Ω(payload).Should(BeNil())
+							Ω(rw.Status).Should(Equal(413))
+						})
+					})
+				})
+			})
+		}
+	})
+
+	Describe("CompressResponses", func() {
+		const body = "compress me, compress me, compress me"
+		var rw *TestResponseWriter
+		var req *http.Request
+		var encoding string
+		var noLength bool
+		var mode goa.CompressMode
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			s.CompressResponses = true
+			encoding = ""
+			noLength = false
+			mode = goa.CompressAuto
+			req, _ = http.NewRequest("GET", "/foo", nil)
+			req.Header.Set("Accept-Encoding", "gzip, deflate")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		})
+
+		JustBeforeEach(func() {
+			ctrl := s.NewController("test")
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if encoding != "" {
+					rw.Header().Set("Content-Encoding", encoding)
+				}
+				if !noLength {
+					rw.Header().Set("Content-Length", fmt.Sprintf("%d", len(body)))
+				}
+				rw.WriteHeader(200)
+				rw.Write([]byte(body[:10]))
+				rw.Write([]byte(body[10:]))
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("testCompress", goa.CompressResponse(mode, handler), nil)
+			muxHandler(rw, req, nil)
+		})
+
+		It("compresses the response using gzip", func() {
+			Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+			Ω(rw.Header().Get("Content-Length")).Should(BeEmpty())
+			Ω(rw.Header().Get("Vary")).Should(Equal("Accept-Encoding"))
+			gr, err := gzip.NewReader(bytes.NewReader(rw.Body))
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadAll(gr)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal(body))
+		})
+
+		Context("with an action that is never compressed", func() {
+			BeforeEach(func() {
+				mode = goa.CompressNever
+			})
+
+			It("does not compress the response", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+				Ω(rw.Header().Get("Content-Length")).Should(Equal(fmt.Sprintf("%d", len(body))))
+				Ω(string(rw.Body)).Should(Equal(body))
+			})
+		})
+
+		Context("with a minimum size", func() {
+			BeforeEach(func() {
+				s.CompressMinSize = len(body) + 1
+			})
+
+			Context("and an action that is always compressed", func() {
+				BeforeEach(func() {
+					mode = goa.CompressAlways
+				})
+
+				It("compresses the response", func() {
+					Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+				})
+			})
+
+			It("does not compress smaller responses", func() {
+				Ω(rw.Status).Should(Equal(200))
+				Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+				Ω(rw.Header().Get("Content-Length")).Should(Equal(fmt.Sprintf("%d", len(body))))
+				Ω(string(rw.Body)).Should(Equal(body))
+			})
+
+			Context("and no content length", func() {
+				BeforeEach(func() {
+					noLength = true
+				})
+
+				It("does not compress smaller responses", func() {
+					Ω(rw.Status).Should(Equal(200))
+					Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+					Ω(string(rw.Body)).Should(Equal(body))
+				})
+
+				Context("with a body exceeding the minimum size", func() {
+					BeforeEach(func() {
+						s.CompressMinSize = 20
+					})
+
+					It("compresses the response", func() {
+						Ω(rw.Status).Should(Equal(200))
+						Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+						gr, err := gzip.NewReader(bytes.NewReader(rw.Body))
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadAll(gr)
+						Ω(err).ShouldNot(HaveOccurred())
+						Ω(string(b)).Should(Equal(body))
+					})
+				})
+			})
+		})
+
+		Context("with a client accepting deflate only", func() {
+			BeforeEach(func() {
+				req.Header.Set("Accept-Encoding", "gzip;q=0, deflate")
+			})
+
+			It("compresses the response using deflate", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+				b, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(rw.Body)))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).Should(Equal(body))
+			})
+		})
+
+		Context("with a client refusing gzip explicitly", func() {
+			BeforeEach(func() {
+				req.Header.Set("Accept-Encoding", "gzip;q=0, *")
+			})
+
+			It("does not let the wildcard override the refusal", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+			})
+		})
+
+		Context("with a client preferring deflate", func() {
+			BeforeEach(func() {
+				req.Header.Set("Accept-Encoding", "gzip;q=0.5, deflate;q=0.8")
+			})
+
+			It("compresses the response using deflate", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+			})
+		})
+
+		Context("with a client that does not accept compression", func() {
+			BeforeEach(func() {
+				req.Header.Del("Accept-Encoding")
+			})
+
+			It("does not compress the response", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+				Ω(string(rw.Body)).Should(Equal(body))
+			})
+		})
+
+		Context("with a handler setting the content encoding", func() {
+			BeforeEach(func() {
+				encoding = "br"
+			})
+
+			It("does not compress the response twice", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(Equal("br"))
+				Ω(string(rw.Body)).Should(Equal(body))
+			})
+		})
+
+		Context("when disabled", func() {
+			BeforeEach(func() {
+				s.CompressResponses = false
+			})
+
+			It("does not compress the response", func() {
+				Ω(rw.Header().Get("Content-Encoding")).Should(BeEmpty())
+				Ω(rw.Header().Get("Content-Length")).Should(Equal(fmt.Sprintf("%d", len(body))))
+				Ω(string(rw.Body)).Should(Equal(body))
+			})
+
+			Context("with an action that is always compressed", func() {
+				BeforeEach(func() {
+					mode = goa.CompressAlways
+				})
+
+				It("compresses the response", func() {
+					Ω(rw.Header().Get("Content-Encoding")).Should(Equal("gzip"))
+					Ω(rw.Header().Get("Vary")).Should(Equal("Accept-Encoding"))
+					gr, err := gzip.NewReader(bytes.NewReader(rw.Body))
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadAll(gr)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(Equal(body))
+				})
+			})
+		})
+	})
+
+	Describe("DecompressRequests", func() {
+		type todo struct {
+			Title string `json:"title" form:"title"`
+			Done  bool   `json:"done" form:"done"`
+		}
+
+		var rw *TestResponseWriter
+		var contentType, encoding string
+		var body []byte
+		var payload *todo
+
+		compress := func(enc, content string) []byte {
+			var buf bytes.Buffer
+			var w io.WriteCloser
+			if enc == "deflate" {
+				w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+			} else {
+				w = gzip.NewWriter(&buf)
+			}
+			w.Write([]byte(content))
+			w.Close()
+			return buf.Bytes()
+		}
+
+		BeforeEach(func() {
+			s.DecompressRequests = true
+			s.Decoder.Register(form.NewDecoder, "application/x-www-form-urlencoded")
+			contentType = "application/json"
+			encoding = "gzip"
+			body = compress("gzip", `{"title":"Write docs","done":true}`)
+			payload = nil
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/todos", bytes.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			req.Header.Set("Content-Encoding", encoding)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var p todo
+				if err := service.DecodeRequest(req, &p); err != nil {
+					return err
+				}
+				payload = &p
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					return s.Send(ctx, err.(goa.ServiceError).ResponseStatus(), err)
+				}
+				rw.WriteHeader(201)
+				return nil
+			}
+			s.NewController("todo").MuxHandler("create", handler, unmarshaler)(rw, req, nil)
+		})
+
+		It("decompresses gzip JSON bodies", func() {
+			Ω(rw.Status).Should(Equal(201))
+			Ω(payload).Should(Equal(&todo{Title: "Write docs", Done: true}))
+		})
+
+		Context("with a deflate form body", func() {
+			BeforeEach(func() {
+				contentType = "application/x-www-form-urlencoded"
+				encoding = "deflate"
+				body = compress("deflate", "title=Write+docs&done=true")
+			})
+
+			It("decompresses the body", func() {
+				Ω(rw.Status).Should(Equal(201))
+				Ω(payload).Should(Equal(&todo{Title: "Write docs", Done: true}))
+			})
+		})
+
+		Context("with a gzip form body", func() {
+			BeforeEach(func() {
+				contentType = "application/x-www-form-urlencoded"
+				body = compress("gzip", "title=Write+docs&done=true")
+			})
+
+			It("decompresses the body", func() {
+				Ω(rw.Status).Should(Equal(201))
+				Ω(payload).Should(Equal(&todo{Title: "Write docs", Done: true}))
+			})
+		})
+
+		Context("with a corrupt stream", func() {
+			BeforeEach(func() {
+				body = []byte(`{"title":"not compressed"}`)
+			})
+
+			It("responds with 400", func() {
+				Ω(rw.Status).Should(Equal(400))
+				Ω(string(rw.Body)).Should(ContainSubstring("invalid gzip request body"))
+			})
+		})
+
+		Context("with a body decompressing past the limit", func() {
+			BeforeEach(func() {
+				s.RequestBudget(64, 0)
+				body = compress("gzip", `{"title":"`+strings.Repeat("a", 1024)+`"}`)
+			})
+
+			It("responds with 413", func() {
+				Ω(len(body)).Should(BeNumerically("<", 64))
+				Ω(rw.Status).Should(Equal(413))
+				Ω(string(rw.Body)).Should(ContainSubstring("decompressed request body length exceeds 64 bytes"))
+			})
+		})
+
+		Context("with a body decompressing past the maximum decompressed length", func() {
+			BeforeEach(func() {
+				s.MaxDecompressedLength = 128
+				body = compress("gzip", `{"title":"`+strings.Repeat("a", 1024)+`"}`)
+			})
+
+			It("responds with 413 even though the request body length limit is higher", func() {
+				Ω(rw.Status).Should(Equal(413))
+				Ω(string(rw.Body)).Should(ContainSubstring("decompressed request body length exceeds 128 bytes"))
+			})
+		})
+
+		Context("when disabled", func() {
+			BeforeEach(func() {
+				s.DecompressRequests = false
+			})
+
+			It("does not decompress the body", func() {
+				Ω(rw.Status).Should(Equal(400))
+			})
+		})
+	})
+
+	Describe("ServerTiming", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+
+		BeforeEach(func() {
+			s.ServerTiming = true
+			req, _ = http.NewRequest("POST", "/foo", bytes.NewBufferString(`"23"`))
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		})
+
+		JustBeforeEach(func() {
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				_, err := ioutil.ReadAll(req.Body)
+				return err
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return s.Send(ctx, 200, "ok")
+			}
+			ctrl.MuxHandler("testTiming", handler, unmarshaler)(rw, req, nil)
+		})
+
+		It("reports the processing time", func() {
+			Ω(rw.Status).Should(Equal(200))
+			timing := rw.Header().Get("Server-Timing")
+			Ω(timing).Should(MatchRegexp(`^payload;dur=[0-9.]+, controller;dur=[0-9.]+, total;dur=[0-9.]+$`))
+		})
+
+		Context("when disabled", func() {
+			BeforeEach(func() {
+				s.ServerTiming = false
+			})
+
+			It("does not set the header", func() {
+				Ω(rw.Header().Get("Server-Timing")).Should(BeEmpty())
+			})
+		})
+	})
+
+	Describe("MaxPayloadDepth", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var muxHandler goa.MuxHandler
+
+		BeforeEach(func() {
+			body := bytes.NewBufferString(`{"a":[{"b":"[[[["}]}`)
+			req, _ = http.NewRequest("POST", "/foo", body)
+			req.Header.Set("Content-Type", "application/json")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			ctrl.MaxPayloadDepth = 3
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var payload interface{}
+				return service.DecodeRequest(req, &payload)
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					rw.WriteHeader(400)
+					rw.Write([]byte(err.Error()))
+					return nil
+				}
+				rw.WriteHeader(200)
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("testDepth", handler, unmarshaler)
+		})
+
+		JustBeforeEach(func() {
+			muxHandler(rw, req, nil)
+		})
+
+		It("accepts payloads within the limit", func() {
+			Ω(rw.Status).Should(Equal(200))
+		})
+
+		Context("with a payload exceeding the depth", func() {
+			BeforeEach(func() {
+				req.Body = ioutil.NopCloser(bytes.NewBufferString(`{"a":[{"b":{"c":1}}]}`))
+			})
+
+			It("rejects the request", func() {
+				Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 400 bad_request: request body nesting exceeds 3 levels`))
+			})
+		})
+
+		Context("with XML payloads", func() {
+			BeforeEach(func() {
+				s.Decoder.Register(goa.NewXMLDecoder, "application/xml")
+				req.Header.Set("Content-Type", "application/xml")
+				req.Body = ioutil.NopCloser(bytes.NewBufferString(`<doc><a><b>1</b></a></doc>`))
+			})
+
+			It("accepts payloads within the limit", func() {
+				Ω(rw.Status).Should(Equal(200))
+			})
+
+			Context("exceeding the depth", func() {
+				BeforeEach(func() {
+					req.Body = ioutil.NopCloser(bytes.NewBufferString(`<doc><a><b><c>1</c></b></a></doc>`))
+				})
+
+				It("rejects the request", func() {
+					Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 400 bad_request: request body nesting exceeds 3 levels`))
+				})
+			})
+		})
+	})
+
+	Describe("streaming multipart requests", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var muxHandler goa.MuxHandler
+		var read chan string
+		var parts []string
+
+		BeforeEach(func() {
+			pr, pw := io.Pipe()
+			mw := multipart.NewWriter(pw)
+			read = make(chan string)
+			parts = nil
+			go func() {
+				defer GinkgoRecover()
+				prev := ""
+				for _, name := range []string{"one", "two", "three"} {
+					w, _ := mw.CreateFormFile(name, name+".txt")
+					// Writing the boundary completes the previous part, wait for the
+					// handler to read it before writing the next part content.
+					if prev != "" {
+						Ω(<-read).Should(Equal(prev))
+					}
+					w.Write([]byte(name))
+					prev = name
+				}
+				mw.Close()
+				Ω(<-read).Should(Equal(prev))
+				pw.Close()
+			}()
+			req, _ = http.NewRequest("POST", "/upload", pr)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				mr, err := goa.ContextRequest(ctx).MultipartReader()
+				if err != nil {
+					return err
+				}
+				for {
+					p, err := mr.NextPart()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						return err
+					}
+					b, err := ioutil.ReadAll(p)
+					if err != nil {
+						return err
+					}
+					parts = append(parts, string(b))
+					read <- p.FormName()
+				}
+				rw.WriteHeader(200)
+				return nil
+			}
+			muxHandler = ctrl.MuxHandler("upload", handler, nil)
+		})
+
+		JustBeforeEach(func() {
+			muxHandler(rw, req, nil)
+		})
+
+		It("reads the parts one at a time", func() {
+			Ω(rw.Status).Should(Equal(200))
+			Ω(parts).Should(Equal([]string{"one", "two", "three"}))
+		})
+	})
+
+	Describe("XML payloads", func() {
+		type todo struct {
+			XMLName xml.Name `xml:"todo"`
+			Title   string   `xml:"title"`
+			Done    bool     `xml:"done"`
+		}
+
+		var rw *TestResponseWriter
+		var req *http.Request
+		var contentType, body string
+		var payload interface{}
+		var unmarshaler goa.Unmarshaler
+
+		BeforeEach(func() {
+			s.Decoder.Register(goa.NewXMLDecoder, "application/xml")
+			contentType = "application/xml"
+			body = `<todo id="1"><title>Write docs</title><done>true</done><tag>a</tag><tag>b</tag></todo>`
+			payload = nil
+			unmarshaler = func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var p interface{}
+				if err := service.DecodeRequest(req, &p); err != nil {
+					return err
+				}
+				payload = p
+				return nil
+			}
 		})
 
 		JustBeforeEach(func() {
-			s.Mux.ServeHTTP(rw, req)
+			req, _ = http.NewRequest("POST", "/todos", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", contentType)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					rw.WriteHeader(400)
+					rw.Write([]byte(err.Error()))
+					return nil
+				}
+				rw.WriteHeader(201)
+				return nil
+			}
+			s.NewController("todo").MuxHandler("create", handler, unmarshaler)(rw, req, nil)
 		})
 
-		It("handles requests with no registered handlers", func() {
-			Ω(string(rw.Body)).Should(MatchRegexp(`{"id":".*","code":"not_found","status":404,"detail":"/foo"}` + "\n"))
+		It("decodes the body into a generic payload", func() {
+			Ω(rw.Status).Should(Equal(201))
+			Ω(payload).Should(Equal(map[string]interface{}{
+				"id":    "1",
+				"title": "Write docs",
+				"done":  "true",
+				"tag":   []interface{}{"a", "b"},
+			}))
 		})
 
-		Context("with middleware", func() {
-			middlewareCalled := false
-
+		Context("with a XML content type variant", func() {
 			BeforeEach(func() {
-				s.Use(TMiddleware(&middlewareCalled))
-				// trigger finalize
-				ctrl := s.NewController("test")
-				ctrl.MuxHandler("", nil, nil)
+				contentType = "application/vnd.todo+xml; charset=utf-8"
 			})
 
-			It("calls the middleware", func() {
-				Ω(middlewareCalled).Should(BeTrue())
+			It("uses the XML decoder", func() {
+				Ω(rw.Status).Should(Equal(201))
+				Ω(payload).Should(HaveKeyWithValue("title", "Write docs"))
 			})
 		})
 
-		Context("middleware and multiple controllers", func() {
-			middlewareCalled := 0
-
+		Context("with multiple root elements", func() {
 			BeforeEach(func() {
-				s.Use(CMiddleware(&middlewareCalled))
-				ctrl := s.NewController("test")
-				ctrl.MuxHandler("/foo", nil, nil)
-				ctrl.MuxHandler("/bar", nil, nil)
+				body = `<todo><title>one</title></todo><todo><title>two</title></todo>`
 			})
 
-			It("calls the middleware once", func() {
-				Ω(middlewareCalled).Should(Equal(1))
+			It("rejects the request", func() {
+				Ω(rw.Status).Should(Equal(400))
+				Ω(string(rw.Body)).Should(ContainSubstring("unexpected element <todo> after root element <todo>"))
 			})
 		})
-	})
 
-	Describe("MaxRequestBodyLength", func() {
-		var rw *TestResponseWriter
-		var req *http.Request
-		var muxHandler goa.MuxHandler
+		Context("with a struct payload", func() {
+			BeforeEach(func() {
+				unmarshaler = func(ctx context.Context, service *goa.Service, req *http.Request) error {
+					var p todo
+					if err := service.DecodeRequest(req, &p); err != nil {
+						return err
+					}
+					payload = &p
+					return nil
+				}
+			})
 
-		BeforeEach(func() {
-			body := bytes.NewBuffer([]byte{'"', '2', '3', '4', '"'})
-			req, _ = http.NewRequest("GET", "/foo", body)
-			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
-			ctrl := s.NewController("test")
-			ctrl.MaxRequestBodyLength = 4
-			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
-				_, err := ioutil.ReadAll(req.Body)
-				return err
-			}
-			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-				rw.WriteHeader(400)
-				rw.Write([]byte(goa.ContextError(ctx).Error()))
-				return nil
-			}
-			muxHandler = ctrl.MuxHandler("testMax", handler, unmarshaler)
-		})
+			It("decodes the body into the struct", func() {
+				Ω(rw.Status).Should(Equal(201))
+				Ω(payload).Should(Equal(&todo{XMLName: xml.Name{Local: "todo"}, Title: "Write docs", Done: true}))
+			})
 
-		JustBeforeEach(func() {
-			muxHandler(rw, req, nil)
-		})
+			Context("with an unexpected root element", func() {
+				BeforeEach(func() {
+					body = `<task><title>Write docs</title></task>`
+				})
 
-		It("prevents reading more bytes", func() {
-			Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 413 request_too_large: request body length exceeds 4 bytes`))
+				It("rejects the request", func() {
+					Ω(rw.Status).Should(Equal(400))
+					Ω(string(rw.Body)).Should(ContainSubstring("expected element type <todo> but have <task>"))
+				})
+			})
 		})
 	})
 
@@ -306,6 +1176,349 @@ var _ = Describe("Service", func() {
 			})
 		})
 	})
+
+	Describe("RespondUnprocessable", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/bottles", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(nil, rw, req, nil)
+		})
+
+		It("sends a 422 response listing the field errors", func() {
+			errs := []goa.FieldError{{Field: "end", Message: "must be after start"}}
+			Ω(s.RespondUnprocessable(ctx, errs)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(422))
+			Ω(string(rw.Body)).Should(MatchRegexp(`{"id":".*","code":"unprocessable_entity","status":422,"detail":"end: must be after start","meta":\[{"errors":\[{"field":"end","message":"must be after start"}\]}\]}`))
+		})
+	})
+
+	Describe("Send", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			req, _ := http.NewRequest("DELETE", "/bottles/1", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(nil, rw, req, nil)
+		})
+
+		It("sends 204 responses without a body", func() {
+			rw.ParentHeader.Set("Content-Type", "application/json")
+			Ω(s.Send(ctx, 204, nil)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(204))
+			Ω(rw.Body).Should(BeEmpty())
+			Ω(rw.ParentHeader).ShouldNot(HaveKey("Content-Type"))
+		})
+
+		It("sends an empty body for nil bodies", func() {
+			Ω(s.Send(ctx, 200, nil)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.Body).Should(BeEmpty())
+		})
+
+		Context("with NoContentForNilBody", func() {
+			BeforeEach(func() {
+				s.NoContentForNilBody = true
+			})
+
+			It("sends 204 responses for nil bodies", func() {
+				Ω(s.Send(ctx, 200, nil)).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(204))
+				Ω(rw.Body).Should(BeEmpty())
+			})
+
+			It("sends 204 responses for nil pointers", func() {
+				Ω(s.Send(ctx, 200, (*TestBottleView)(nil))).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(204))
+				Ω(rw.Body).Should(BeEmpty())
+			})
+		})
+
+		It("sends 304 responses without a body", func() {
+			Ω(s.Send(ctx, 304, "ignored")).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(304))
+			Ω(rw.Body).Should(BeEmpty())
+		})
+
+		Context("with ValidateResponses", func() {
+			BeforeEach(func() {
+				s.ValidateResponses = true
+				rw.ParentHeader.Set("Content-Type", "application/vnd.goa.example.bottle")
+			})
+
+			It("sends responses rendering all the view members", func() {
+				name := "Number 8"
+				Ω(s.Send(ctx, 200, &TestBottleView{ID: 1, Name: &name})).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(200))
+				Ω(string(rw.Body)).Should(MatchJSON(`{"id":1,"name":"Number 8"}`))
+			})
+
+			It("replaces responses missing view members with an error", func() {
+				Ω(s.Send(ctx, 200, &TestBottleView{ID: 1})).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(500))
+				Ω(rw.ParentHeader.Get("Content-Type")).ShouldNot(Equal("application/vnd.goa.example.bottle"))
+				var resp goa.ErrorResponse
+				Ω(json.Unmarshal(rw.Body, &resp)).ShouldNot(HaveOccurred())
+				Ω(resp.Code).Should(Equal("invalid_response"))
+				Ω(resp.Detail).Should(ContainSubstring(`attribute "name" of response is missing`))
+				Ω(resp.Errors).Should(HaveLen(1))
+				Ω(resp.Errors[0].Field).Should(Equal("response.name"))
+			})
+
+			It("does not validate error responses", func() {
+				Ω(s.Send(ctx, 400, &TestBottleView{ID: 1})).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(400))
+			})
+		})
+	})
+
+	Describe("content negotiation", func() {
+		type bottle struct {
+			XMLName xml.Name `json:"-" xml:"bottle"`
+			Name    string   `json:"name" xml:"name"`
+		}
+		var rw *TestResponseWriter
+		var ctx context.Context
+		var accept string
+		var sendErr error
+
+		BeforeEach(func() {
+			s.Encoder.Register(goa.NewXMLEncoder, "application/xml")
+			accept = ""
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("GET", "/bottles/1", nil)
+			if accept != "" {
+				req.Header.Set("Accept", accept)
+			}
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			rw.ParentHeader.Set("Content-Type", "application/vnd.goa.example.bottle+json")
+			ctx = goa.NewContext(nil, rw, req, nil)
+			sendErr = s.Send(ctx, 200, &bottle{Name: "Kelsey"})
+		})
+
+		It("uses the default encoder", func() {
+			Ω(sendErr).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/vnd.goa.example.bottle+json"))
+			Ω(string(rw.Body)).Should(Equal(`{"name":"Kelsey"}` + "\n"))
+		})
+
+		Context("with an Accept header requesting XML", func() {
+			BeforeEach(func() {
+				accept = "application/json;q=0.5, application/xml"
+			})
+
+			It("encodes the body in XML", func() {
+				Ω(sendErr).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(200))
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/xml"))
+				Ω(string(rw.Body)).Should(Equal(`<bottle><name>Kelsey</name></bottle>`))
+			})
+		})
+
+		Context("with an Accept header using a structured syntax suffix", func() {
+			BeforeEach(func() {
+				s.Encoder = goa.NewHTTPEncoder()
+				s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+				s.Encoder.Register(goa.NewXMLEncoder, "application/xml")
+				accept = "application/vnd.goa.example.bottle+json"
+			})
+
+			It("uses the encoder of the suffix and keeps the content type", func() {
+				Ω(sendErr).ShouldNot(HaveOccurred())
+				Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/vnd.goa.example.bottle+json"))
+				Ω(string(rw.Body)).Should(Equal(`{"name":"Kelsey"}` + "\n"))
+			})
+		})
+
+		Context("with an unsupported Accept header", func() {
+			BeforeEach(func() {
+				accept = "text/csv"
+			})
+
+			It("uses the default encoder", func() {
+				Ω(sendErr).ShouldNot(HaveOccurred())
+				Ω(rw.Status).Should(Equal(200))
+				Ω(string(rw.Body)).Should(Equal(`{"name":"Kelsey"}` + "\n"))
+			})
+
+			Context("and no default encoder", func() {
+				BeforeEach(func() {
+					s.Encoder = goa.NewHTTPEncoder()
+					s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+					s.Encoder.Register(goa.NewXMLEncoder, "application/xml")
+				})
+
+				It("returns a not acceptable error", func() {
+					Ω(sendErr).Should(HaveOccurred())
+					Ω(sendErr.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+					Ω(rw.Status).Should(Equal(0))
+				})
+
+				It("responds with 406 through the error handler", func() {
+					Ω(s.Send(ctx, 406, sendErr)).ShouldNot(HaveOccurred())
+					Ω(rw.Status).Should(Equal(406))
+					Ω(rw.Body).Should(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Describe("EnvelopeErrors", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			s.EnvelopeErrors = true
+			req, _ := http.NewRequest("GET", "/bottles/1", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(nil, rw, req, nil)
+		})
+
+		It("leaves success bodies untouched", func() {
+			Ω(s.Send(ctx, 200, map[string]interface{}{"id": 1})).ShouldNot(HaveOccurred())
+			Ω(string(rw.Body)).Should(Equal(`{"id":1}` + "\n"))
+		})
+
+		It("wraps error bodies into error responses", func() {
+			Ω(s.Send(ctx, 404, "bottle not found")).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(404))
+			Ω(string(rw.Body)).Should(MatchRegexp(`{"id":".*","code":"not_found","status":404,"detail":"bottle not found"}`))
+		})
+
+		It("wraps each error of a multi error", func() {
+			err := goa.AppendError(goa.MissingParamError("name"), errors.New("boom"))
+			Ω(s.Send(ctx, 400, err)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(400))
+			var decoded []*goa.ErrorResponse
+			Ω(json.Unmarshal(rw.Body, &decoded)).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(HaveLen(2))
+			Ω(decoded[0].Code).Should(Equal("invalid_request"))
+			Ω(decoded[1].Code).Should(Equal("bad_request"))
+			Ω(decoded[1].Detail).Should(Equal("boom"))
+		})
+	})
+
+	Describe("ProblemDetails", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			s.ProblemDetails = true
+			req, _ := http.NewRequest("GET", "/bottles/1", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(nil, rw, req, nil)
+		})
+
+		It("leaves success bodies untouched", func() {
+			Ω(s.Send(ctx, 200, map[string]interface{}{"id": 1})).ShouldNot(HaveOccurred())
+			Ω(string(rw.Body)).Should(Equal(`{"id":1}` + "\n"))
+		})
+
+		It("maps validation errors into the problem detail", func() {
+			err := goa.AppendError(goa.InvalidParamTypeError("id", "one", "integer"), goa.MissingParamError("name"))
+			Ω(s.Send(ctx, 400, err)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(400))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/problem+json"))
+			var pd map[string]interface{}
+			Ω(json.Unmarshal(rw.Body, &pd)).ShouldNot(HaveOccurred())
+			Ω(pd["type"]).Should(Equal("about:blank"))
+			Ω(pd["title"]).Should(Equal("Bad Request"))
+			Ω(pd["status"]).Should(BeEquivalentTo(400))
+			Ω(pd["detail"]).Should(ContainSubstring(`invalid value "one" for parameter "id", must be a integer`))
+			Ω(pd["detail"]).Should(ContainSubstring(`missing required parameter "name"`))
+			Ω(pd["code"]).Should(Equal("invalid_request"))
+		})
+
+		It("renders internal errors", func() {
+			Ω(s.Send(ctx, 500, "Internal error: boom")).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(500))
+			Ω(string(rw.Body)).Should(Equal(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal error: boom"}` + "\n"))
+		})
+	})
+
+	Describe("RespondBytes", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			req, _ := http.NewRequest("GET", "/report", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(nil, rw, req, nil)
+		})
+
+		It("writes the bytes unmodified", func() {
+			body := []byte{0x25, 0x50, 0x44, 0x46, 0x00, 0xff, 0x0a}
+			Ω(s.RespondBytes(ctx, 200, "application/pdf", body)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.Body).Should(Equal(body))
+			Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/pdf"))
+			Ω(rw.ParentHeader.Get("Content-Length")).Should(Equal("7"))
+		})
+	})
+
+	Describe("EncodeResponse", func() {
+		var rw *TestResponseWriter
+		var ctx context.Context
+		var body interface{}
+
+		BeforeEach(func() {
+			s.RenderDepthParam = "depth"
+			req, _ := http.NewRequest("GET", "/blogs/1?depth=1", nil)
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(nil, rw, req, nil)
+			body = map[string]interface{}{
+				"id": 1,
+				"posts": []interface{}{
+					map[string]interface{}{
+						"id":    2,
+						"title": "post",
+						"comments": []interface{}{
+							map[string]interface{}{
+								"id":     3,
+								"author": map[string]interface{}{"id": 4},
+							},
+						},
+						"meta": map[string]interface{}{"tags": "none"},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			Ω(s.EncodeResponse(ctx, body)).ShouldNot(HaveOccurred())
+		})
+
+		It("omits objects nested below the requested depth", func() {
+			Ω(string(rw.Body)).Should(Equal(`{"id":1,"posts":[{"comments":[{"id":3}],"id":2,"title":"post"}]}` + "\n"))
+		})
+
+		Context("with PrettyJSON", func() {
+			BeforeEach(func() {
+				s.RenderDepthParam = ""
+				body = map[string]interface{}{"id": 1, "tags": []string{"a"}}
+			})
+
+			It("writes compact JSON by default", func() {
+				Ω(string(rw.Body)).Should(Equal(`{"id":1,"tags":["a"]}` + "\n"))
+			})
+
+			Context("enabled", func() {
+				BeforeEach(func() {
+					s.PrettyJSON = true
+				})
+
+				It("indents the JSON body with two spaces", func() {
+					Ω(string(rw.Body)).Should(Equal("{\n  \"id\": 1,\n  \"tags\": [\n    \"a\"\n  ]\n}\n"))
+				})
+			})
+		})
+	})
 })
 
 func TErrorHandler(witness *bool) goa.Middleware {
@@ -350,6 +1563,19 @@ func SecondMiddleware(witness1, witness2 *bool) goa.Middleware {
 	}
 }
 
+// TestBottleView mimics a media type view generated by goagen with a required member.
+type TestBottleView struct {
+	ID   int     `json:"id"`
+	Name *string `json:"name"`
+}
+
+func (mt *TestBottleView) Validate() (err error) {
+	if mt.Name == nil {
+		err = goa.AppendError(err, goa.MissingAttributeError(`response`, "name"))
+	}
+	return
+}
+
 type TestResponseWriter struct {
 	ParentHeader http.Header
 	Body         []byte
@@ -368,3 +1594,40 @@ func (t *TestResponseWriter) Write(b []byte) (int, error) {
 func (t *TestResponseWriter) WriteHeader(s int) {
 	t.Status = s
 }
+
+var _ = Describe("DecodeRequest", func() {
+	var service *goa.Service
+	var payload interface{}
+	var err error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Decoder.Register(goa.NewJSONDecoder, "application/json")
+	})
+
+	JustBeforeEach(func() {
+		req, _ := http.NewRequest("POST", "/", strings.NewReader(`{"id": 9007199254740993, "ratio": 0.5}`))
+		req.Header.Set("Content-Type", "application/json")
+		payload = nil
+		err = service.DecodeRequest(req, &payload)
+	})
+
+	It("decodes generic numbers as floats", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(payload.(map[string]interface{})["ratio"]).Should(Equal(0.5))
+	})
+
+	Context("with UseNumber enabled", func() {
+		BeforeEach(func() {
+			service.UseNumber = true
+		})
+
+		It("decodes generic numbers without losing precision", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload).Should(Equal(map[string]interface{}{
+				"id":    json.Number("9007199254740993"),
+				"ratio": json.Number("0.5"),
+			}))
+		})
+	})
+})
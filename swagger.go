@@ -0,0 +1,79 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// ServeDocs mounts a "Docs" controller that serves the API specification returned by load under
+// path and a documentation page that renders it under "{path}/ui". The specification may be
+// either the Swagger or the OpenAPI 3.0 specification generated by goagen in the swagger
+// directory.
+//
+// The page is not Swagger UI: it is a minimal HTML page embedded in goa that lists the API
+// operations with their parameters, request bodies and responses, it does not let users send
+// requests. Its style and script are embedded so that the documentation does not depend on any
+// third party host. Point a separately hosted Swagger UI at path for the full experience.
+//
+// load is called on the first request and its result cached for the lifetime of the service, it
+// may read the file produced by goagen or generate the specification from the design package:
+//
+//	service.ServeDocs("/openapi.json", func() ([]byte, error) {
+//		return ioutil.ReadFile("swagger/openapi.json")
+//	})
+//
+// Requests fail with a 500 response if load returns an error or invalid JSON, in which case the
+// next request calls load again.
+func (service *Service) ServeDocs(path string, load func() ([]byte, error)) {
+	var (
+		mu   sync.Mutex
+		spec []byte
+	)
+	path = strings.TrimSuffix(path, "/")
+	ctrl := service.NewController("Docs")
+
+	specHandler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		mu.Lock()
+		if spec == nil {
+			b, err := load()
+			if err == nil {
+				var doc interface{}
+				if err = json.Unmarshal(b, &doc); err == nil {
+					spec = b
+				}
+			}
+			if err != nil {
+				mu.Unlock()
+				return fmt.Errorf("failed to load API specification: %s", err)
+			}
+		}
+		mu.Unlock()
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		_, err := rw.Write(spec)
+		return err
+	}
+	service.Mux.Handle("GET", path, ctrl.MuxHandler("spec", specHandler, nil))
+	LogInfo(ctrl.Context, "mount docs", "route", "GET "+path)
+
+	data := map[string]string{"Title": service.Name, "SpecURL": path}
+	uiHandler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+		return docsUIT.Execute(rw, data)
+	}
+	service.Mux.Handle("GET", path+"/ui", ctrl.MuxHandler("ui", uiHandler, nil))
+	LogInfo(ctrl.Context, "mount docs", "route", "GET "+path+"/ui")
+}
+
+// ServeSwagger serves the Swagger specification returned by load under the given path, for
+// example to point a Swagger UI instance at a running service. It is equivalent to ServeDocs
+// which also serves the documentation page under "{path}/ui".
+func (service *Service) ServeSwagger(path string, load func() ([]byte, error)) {
+	service.ServeDocs(path, load)
+}
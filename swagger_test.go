@@ -0,0 +1,112 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ServeDocs", func() {
+	const spec = `{"openapi":"3.0.3","info":{"title":"test","version":"1.0"},"paths":{}}`
+	var s *goa.Service
+	var body string
+	var loads int
+	var loadErr error
+
+	get := func(path string) *TestResponseWriter {
+		rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+		req, _ := http.NewRequest("GET", path, nil)
+		s.Mux.ServeHTTP(rw, req)
+		return rw
+	}
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		body = spec
+		loads = 0
+		loadErr = nil
+		s.ServeDocs("/openapi.json", func() ([]byte, error) {
+			loads++
+			if loadErr != nil {
+				return nil, loadErr
+			}
+			return []byte(body), nil
+		})
+	})
+
+	It("does not load the specification until requested", func() {
+		Ω(loads).Should(Equal(0))
+	})
+
+	It("serves the JSON specification", func() {
+		rw := get("/openapi.json")
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/json"))
+		var decoded map[string]interface{}
+		Ω(json.Unmarshal(rw.Body, &decoded)).ShouldNot(HaveOccurred())
+		Ω(decoded).Should(HaveKeyWithValue("openapi", "3.0.3"))
+	})
+
+	It("loads the specification once", func() {
+		get("/openapi.json")
+		rw := get("/openapi.json")
+		Ω(rw.Status).Should(Equal(200))
+		Ω(string(rw.Body)).Should(Equal(spec))
+		Ω(loads).Should(Equal(1))
+	})
+
+	It("serves a self-contained page rendering the specification", func() {
+		rw := get("/openapi.json/ui")
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("text/html; charset=utf-8"))
+		Ω(string(rw.Body)).Should(ContainSubstring(`"/openapi.json"`))
+		Ω(string(rw.Body)).ShouldNot(ContainSubstring("https://"))
+		Ω(loads).Should(Equal(0))
+	})
+
+	Context("with a failing loader", func() {
+		BeforeEach(func() {
+			loadErr = errors.New("boom")
+		})
+
+		It("responds with 500 and retries on the next request", func() {
+			rw := get("/openapi.json")
+			Ω(rw.Status).Should(Equal(500))
+			loadErr = nil
+			rw = get("/openapi.json")
+			Ω(rw.Status).Should(Equal(200))
+			Ω(loads).Should(Equal(2))
+		})
+	})
+
+	Context("with an invalid specification", func() {
+		BeforeEach(func() {
+			body = "{"
+		})
+
+		It("responds with 500", func() {
+			rw := get("/openapi.json")
+			Ω(rw.Status).Should(Equal(500))
+		})
+	})
+})
+
+var _ = Describe("ServeSwagger", func() {
+	const spec = `{"swagger":"2.0","info":{"title":"test","version":"1.0"},"paths":{}}`
+
+	It("serves the JSON specification", func() {
+		s := goa.New("test")
+		s.ServeSwagger("/swagger.json", func() ([]byte, error) { return []byte(spec), nil })
+		rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+		req, _ := http.NewRequest("GET", "/swagger.json", nil)
+		s.Mux.ServeHTTP(rw, req)
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(string(rw.Body)).Should(Equal(spec))
+	})
+})
@@ -0,0 +1,111 @@
+package goa
+
+import "html/template"
+
+// docsUIT is the template used to render the documentation page served by ServeDocs. The page
+// embeds its style and script so that it does not load any asset from a third party host. The
+// script fetches the specification and lists the API operations with their parameters, request
+// body and responses. It supports both Swagger 2.0 and OpenAPI 3.0 specifications.
+var docsUIT = template.Must(template.New("docs-ui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>{{ .Title }}</title>
+  <style>
+    body { font-family: sans-serif; margin: 0 auto; max-width: 960px; padding: 1em; color: #333; }
+    h1 small { color: #888; font-size: 0.5em; }
+    .op { border: 1px solid #ddd; border-radius: 4px; margin: 0.5em 0; }
+    .op summary { cursor: pointer; padding: 0.5em; }
+    .op > div { padding: 0 1em 1em; }
+    .method { display: inline-block; min-width: 5em; font-weight: bold; text-transform: uppercase; }
+    .get { color: #2a7ae2; } .post { color: #2e9e4f; } .put, .patch { color: #c57b00; } .delete { color: #c0392b; }
+    table { border-collapse: collapse; width: 100%; }
+    th, td { border-bottom: 1px solid #eee; padding: 0.3em; text-align: left; vertical-align: top; }
+    .error { color: #c0392b; }
+  </style>
+</head>
+<body>
+  <div id="docs">Loading...</div>
+  <script>
+    (function() {
+      var root = document.getElementById("docs");
+      function el(tag, cls, text) {
+        var e = document.createElement(tag);
+        if (cls) { e.className = cls; }
+        if (text !== undefined) { e.textContent = text; }
+        return e;
+      }
+      function typeOf(p) {
+        var s = p.schema || p;
+        if (s.$ref) { return s.$ref.split("/").pop(); }
+        if (s.type === "array" && s.items) { return "[]" + typeOf(s.items); }
+        return (s.type || "object") + (s.format ? " (" + s.format + ")" : "");
+      }
+      function table(headers, rows) {
+        var t = el("table"), tr = el("tr");
+        headers.forEach(function(h) { tr.appendChild(el("th", "", h)); });
+        t.appendChild(tr);
+        rows.forEach(function(row) {
+          var tr = el("tr");
+          row.forEach(function(c) { tr.appendChild(el("td", "", c)); });
+          t.appendChild(tr);
+        });
+        return t;
+      }
+      function operation(path, method, op) {
+        var d = el("details", "op"), s = el("summary"), body = el("div");
+        s.appendChild(el("span", "method " + method, method));
+        s.appendChild(document.createTextNode(" " + path + (op.summary ? " - " + op.summary : "")));
+        d.appendChild(s);
+        if (op.description) { body.appendChild(el("p", "", op.description)); }
+        var params = (op.parameters || []).map(function(p) {
+          return [p.name, p.in, typeOf(p), p.required ? "yes" : "no", p.description || ""];
+        });
+        if (params.length) {
+          body.appendChild(el("h4", "", "Parameters"));
+          body.appendChild(table(["Name", "In", "Type", "Required", "Description"], params));
+        }
+        if (op.requestBody && op.requestBody.content) {
+          body.appendChild(el("h4", "", "Request body"));
+          body.appendChild(table(["Content type", "Type"], Object.keys(op.requestBody.content).map(function(ct) {
+            return [ct, typeOf(op.requestBody.content[ct])];
+          })));
+        }
+        var resps = Object.keys(op.responses || {}).map(function(code) {
+          return [code, op.responses[code].description || ""];
+        });
+        if (resps.length) {
+          body.appendChild(el("h4", "", "Responses"));
+          body.appendChild(table(["Status", "Description"], resps));
+        }
+        d.appendChild(body);
+        return d;
+      }
+      function render(spec) {
+        var info = spec.info || {};
+        root.textContent = "";
+        var h = el("h1", "", info.title || "API");
+        h.appendChild(document.createTextNode(" "));
+        h.appendChild(el("small", "", info.version || ""));
+        root.appendChild(h);
+        if (info.description) { root.appendChild(el("p", "", info.description)); }
+        var base = spec.basePath || "";
+        Object.keys(spec.paths || {}).sort().forEach(function(path) {
+          var item = spec.paths[path];
+          ["get", "head", "post", "put", "patch", "delete", "options"].forEach(function(m) {
+            if (item[m]) { root.appendChild(operation(base + path, m, item[m])); }
+          });
+        });
+      }
+      fetch({{ .SpecURL }}).then(function(resp) {
+        if (!resp.ok) { throw new Error(resp.status + " " + resp.statusText); }
+        return resp.json();
+      }).then(render).catch(function(err) {
+        root.textContent = "";
+        root.appendChild(el("p", "error", "Failed to load the API specification: " + err.message));
+      });
+    })();
+  </script>
+</body>
+</html>
+`))
@@ -0,0 +1,145 @@
+package goa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// errActionTimedOut is the error returned to handlers writing a response after the deadline set
+// by ActionTimeout.
+var errActionTimedOut = errors.New("action timed out")
+
+// timeoutWriter is the response writer given to handlers run by ActionTimeout. It buffers the
+// response so that nothing reaches the client if the deadline expires before the handler returns.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+// ActionTimeout runs the given action handler under a context whose deadline expires after
+// timeout. If the handler does not return before the deadline ActionTimeout returns an error
+// built from ErrActionTimeout right away, which the error handler middleware turns into a 503
+// response. The handler keeps running in the background until it returns: anything it writes
+// afterwards is discarded and its errors and panics are dropped. Handlers should watch the
+// context Done channel and stop any work in progress when the deadline expires. The response is
+// buffered until the handler returns so that it can be discarded, actions that stream their
+// response should not use a timeout. ActionTimeout returns h unchanged if timeout is not greater
+// than 0.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func ActionTimeout(timeout time.Duration, h Handler) Handler {
+	if timeout <= 0 {
+		return h
+	}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		resp := ContextResponse(ctx)
+		if resp == nil {
+			return h(ctx, rw, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{header: make(http.Header, len(resp.Header()))}
+		for k, v := range resp.Header() {
+			tw.header[k] = append([]string(nil), v...)
+		}
+		inner := &ResponseData{ResponseWriter: tw, Service: resp.Service}
+		ictx := context.WithValue(ctx, respKey, inner)
+
+		type result struct {
+			err error
+			p   interface{}
+		}
+		// done is buffered so that handlers that outlive the deadline do not block on it.
+		done := make(chan result, 1)
+		go func() {
+			var res result
+			defer func() {
+				if p := recover(); p != nil {
+					res.p = p
+				}
+				done <- res
+			}()
+			res.err = h(ictx, inner, req)
+		}()
+
+		var res result
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+			if ctx.Err() != context.DeadlineExceeded {
+				// Canceled by the parent context, let the handler complete.
+				res = <-done
+				break
+			}
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+			return ErrActionTimeout(fmt.Sprintf("action did not respond within %s", timeout))
+		}
+		if res.p != nil {
+			panic(res.p)
+		}
+
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		header := resp.Header()
+		for k := range header {
+			delete(header, k)
+		}
+		for k, v := range tw.header {
+			header[k] = v
+		}
+		resp.ErrorCode = inner.ErrorCode
+		if tw.status != 0 {
+			resp.Status = tw.status
+			resp.ResponseWriter.WriteHeader(tw.status)
+			if tw.buf.Len() > 0 {
+				if _, err := resp.Write(tw.buf.Bytes()); err != nil && res.err == nil {
+					res.err = err
+				}
+			}
+		}
+		return res.err
+	}
+}
+
+// Header returns the response header. The map is private to the writer, it is copied to the
+// actual response only if the handler completes in time.
+func (w *timeoutWriter) Header() http.Header {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.header
+}
+
+// WriteHeader records the response status code.
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || w.status != 0 {
+		return
+	}
+	w.status = status
+}
+
+// Write buffers the response body.
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, errActionTimedOut
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
@@ -0,0 +1,105 @@
+package goa_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+var _ = Describe("ActionTimeout", func() {
+	var service *goa.Service
+	var timeout, delay time.Duration
+	var rw *TestResponseWriter
+	var deadlineSet, ignoreDeadline bool
+	var elapsed time.Duration
+	var err error
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		timeout = 50 * time.Millisecond
+		delay = 0
+		deadlineSet = false
+		ignoreDeadline = false
+	})
+
+	JustBeforeEach(func() {
+		// The handler may outlive the call, it only uses local copies of the test variables.
+		var hasDeadline bool
+		delay, ignore, send := delay, ignoreDeadline, service.Send
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if ignore {
+				time.Sleep(delay)
+				return nil
+			}
+			_, hasDeadline = ctx.Deadline()
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+			rw.Header().Set("X-Handler", "done")
+			return send(ctx, 200, map[string]string{"status": "ok"})
+		}
+		req, _ := http.NewRequest("GET", "/report", nil)
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		ctx := goa.NewContext(nil, rw, req, nil)
+		start := time.Now()
+		err = goa.ActionTimeout(timeout, handler)(ctx, goa.ContextResponse(ctx), req)
+		elapsed = time.Since(start)
+		if err == nil {
+			deadlineSet = hasDeadline
+		}
+	})
+
+	It("sends the response of actions that complete in time", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(deadlineSet).Should(BeTrue())
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("X-Handler")).Should(Equal("done"))
+		Ω(string(rw.Body)).Should(Equal(`{"status":"ok"}` + "\n"))
+	})
+
+	Context("with an action that does not complete in time", func() {
+		BeforeEach(func() {
+			delay = time.Second
+		})
+
+		It("returns a timeout error and discards the response", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(503))
+			Ω(err.Error()).Should(ContainSubstring("action did not respond within 50ms"))
+			Ω(rw.Status).Should(Equal(0))
+			Ω(rw.ParentHeader.Get("X-Handler")).Should(BeEmpty())
+		})
+	})
+
+	Context("with an action that ignores the deadline", func() {
+		BeforeEach(func() {
+			delay = time.Second
+			ignoreDeadline = true
+		})
+
+		It("returns the timeout error without waiting for the action", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(503))
+			Ω(elapsed).Should(BeNumerically("<", 500*time.Millisecond))
+			Ω(rw.Status).Should(Equal(0))
+		})
+	})
+
+	Context("with no timeout", func() {
+		BeforeEach(func() {
+			timeout = 0
+		})
+
+		It("runs the handler as is", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(deadlineSet).Should(BeFalse())
+			Ω(rw.Status).Should(Equal(200))
+		})
+	})
+})
@@ -1,4 +1,15 @@
 package uuid
 
+import "fmt"
+
 // UUID This is needed to build with gopherjs
 type UUID [16]byte
+
+// FromBytes returns the UUID whose binary representation is input. input must be 16 bytes long.
+func FromBytes(input []byte) (u UUID, err error) {
+	if len(input) != len(u) {
+		return u, fmt.Errorf("uuid: UUID must be exactly %d bytes long, got %d bytes", len(u), len(input))
+	}
+	copy(u[:], input)
+	return u, nil
+}
@@ -2,11 +2,14 @@ package goa
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"net/mail"
 	"net/url"
+	"reflect"
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goadesign/goa/uuid"
@@ -119,25 +122,148 @@ func ValidateFormat(f Format, val string) error {
 	return nil
 }
 
-// knownPatterns records the compiled patterns.
-// TBD: refactor all this so that the generated code initializes the map on start to get rid of the
-// need for a RW mutex.
-var knownPatterns = make(map[string]*regexp.Regexp)
+// knownPatterns records the compiled patterns indexed by pattern. The map is never modified once
+// stored: adding a pattern replaces it with a copy so that lookups done when validating requests
+// don't require any locking.
+var knownPatterns atomic.Value
 
-// knownPatternsLock is the mutex used to access knownPatterns
-var knownPatternsLock = &sync.RWMutex{}
+// knownPatternsLock is the mutex used to serialize updates to knownPatterns.
+var knownPatternsLock = &sync.Mutex{}
 
-// ValidatePattern returns an error if val does not match the regular expression p.
-// It makes an effort to minimize the number of times the regular expression needs to be compiled.
+func init() {
+	knownPatterns.Store(make(map[string]*regexp.Regexp))
+}
+
+// CompilePatterns compiles the given regular expressions and caches the results so that
+// ValidatePattern does not need to compile them when validating requests. The generated code
+// calls CompilePatterns when mounting controllers with the patterns used by the design.
+func CompilePatterns(patterns ...string) error {
+	knownPatternsLock.Lock()
+	defer knownPatternsLock.Unlock()
+	known := knownPatterns.Load().(map[string]*regexp.Regexp)
+	compiled := make(map[string]*regexp.Regexp, len(known)+len(patterns))
+	for p, r := range known {
+		compiled[p] = r
+	}
+	for _, p := range patterns {
+		if _, ok := compiled[p]; ok {
+			continue
+		}
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %#v: %s", p, err)
+		}
+		compiled[p] = r
+	}
+	knownPatterns.Store(compiled)
+	return nil
+}
+
+// ValidatePattern returns true if val matches the regular expression p, false otherwise.
+// Patterns compiled with CompilePatterns are looked up without locking, other patterns are compiled
+// and cached on first use.
 func ValidatePattern(p string, val string) bool {
-	knownPatternsLock.RLock()
-	r, ok := knownPatterns[p]
-	knownPatternsLock.RUnlock()
+	r, ok := knownPatterns.Load().(map[string]*regexp.Regexp)[p]
 	if !ok {
-		r = regexp.MustCompile(p) // DSL validation makes sure regexp is valid
-		knownPatternsLock.Lock()
-		knownPatterns[p] = r
-		knownPatternsLock.Unlock()
+		if err := CompilePatterns(p); err != nil {
+			panic(err) // DSL validation makes sure regexp is valid
+		}
+		r = knownPatterns.Load().(map[string]*regexp.Regexp)[p]
 	}
 	return r.MatchString(val)
 }
+
+// multipleOfEpsilon is the tolerance used when checking that a floating point value is a multiple
+// of a divisor. The tolerance is relative to the quotient of the value by the divisor as the
+// rounding errors grow with the magnitude of the quotient, it is multipleOfEpsilon for quotients
+// smaller than multipleOfEpsilon/multipleOfRelativeEpsilon.
+const (
+	multipleOfEpsilon         = 1e-9
+	multipleOfRelativeEpsilon = 1e-14
+)
+
+// ValidateMultipleOf returns true if val is a multiple of divisor. The check is exact when val and
+// divisor are both integral, otherwise it tolerates the rounding errors inherent to floating point
+// arithmetic so that for example 0.3 is a multiple of 0.1.
+func ValidateMultipleOf(val, divisor float64) bool {
+	if divisor == 0 {
+		return false
+	}
+	if val == math.Trunc(val) && divisor == math.Trunc(divisor) {
+		return math.Mod(val, divisor) == 0
+	}
+	q := val / divisor
+	tolerance := math.Max(multipleOfEpsilon, math.Abs(q)*multipleOfRelativeEpsilon)
+	return math.Abs(q-math.Floor(q+0.5)) < tolerance
+}
+
+// ValidateUniqueItems checks that the elements of the slice val are all different. It returns the
+// first element that is repeated and false if that is not the case. Elements are compared using
+// reflect.DeepEqual so that slices of structs, maps or slices are supported. Elements that are
+// scalars or pointers to scalars are indexed so that validating large slices stays cheap, the
+// other elements are compared pairwise.
+func ValidateUniqueItems(val interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, true
+	}
+	seen := make(map[uniqueKey]struct{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		key, ok := newUniqueKey(v.Index(i))
+		if !ok {
+			return validateUniqueItemsPairwise(v)
+		}
+		if _, ok := seen[key]; ok {
+			return v.Index(i).Interface(), false
+		}
+		seen[key] = struct{}{}
+	}
+	return nil, true
+}
+
+// uniqueKey identifies the value of a slice element compared by ValidateUniqueItems.
+type uniqueKey struct {
+	typ reflect.Type // dynamic type of the element
+	val interface{}  // scalar value the element holds or points to, nil if it is nil
+}
+
+// newUniqueKey returns the key identifying the value of elem. It returns false if the value
+// cannot be indexed because it is not a scalar or a pointer to a scalar.
+func newUniqueKey(elem reflect.Value) (uniqueKey, bool) {
+	if elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			return uniqueKey{}, true
+		}
+		elem = elem.Elem()
+	}
+	key := uniqueKey{typ: elem.Type()}
+	for elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return key, true
+		}
+		elem = elem.Elem()
+	}
+	switch elem.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		key.val = elem.Interface()
+		return key, true
+	}
+	return key, false
+}
+
+// validateUniqueItemsPairwise implements ValidateUniqueItems for slices whose elements cannot be
+// indexed.
+func validateUniqueItemsPairwise(v reflect.Value) (interface{}, bool) {
+	for i := 1; i < v.Len(); i++ {
+		elem := v.Index(i).Interface()
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(v.Index(j).Interface(), elem) {
+				return elem, false
+			}
+		}
+	}
+	return nil, true
+}
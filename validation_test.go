@@ -1,6 +1,9 @@
 package goa_test
 
 import (
+	"testing"
+	"unicode/utf8"
+
 	"github.com/goadesign/goa"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -43,6 +46,26 @@ var _ = Describe("ValidateFormat", func() {
 				Ω(valErr).ShouldNot(HaveOccurred())
 			})
 		})
+
+		Context("with a valid value using a zone offset", func() {
+			BeforeEach(func() {
+				val = "2015-10-26T08:31:23.123-07:00"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with the RFC3339 layout as value", func() {
+			BeforeEach(func() {
+				val = "2006-01-02T15:04:05Z07:00"
+			})
+
+			It("does not validate", func() {
+				Ω(valErr).Should(HaveOccurred())
+			})
+		})
 	})
 
 	Context("UUID", func() {
@@ -343,3 +366,183 @@ var _ = Describe("ValidateFormat", func() {
 
 	})
 })
+
+var _ = Describe("ValidatePattern", func() {
+	It("validates values against uncompiled patterns", func() {
+		Ω(goa.ValidatePattern("^goa[0-9]$", "goa1")).Should(BeTrue())
+		Ω(goa.ValidatePattern("^goa[0-9]$", "goa")).Should(BeFalse())
+	})
+
+	Context("with precompiled patterns", func() {
+		BeforeEach(func() {
+			Ω(goa.CompilePatterns("^[a-z]+$")).ShouldNot(HaveOccurred())
+		})
+
+		It("produces the same results", func() {
+			Ω(goa.ValidatePattern("^[a-z]+$", "goa")).Should(BeTrue())
+			Ω(goa.ValidatePattern("^[a-z]+$", "Goa")).Should(BeFalse())
+		})
+	})
+
+	Context("compiling an invalid pattern", func() {
+		It("returns an error", func() {
+			Ω(goa.CompilePatterns("foo[")).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ValidateMultipleOf", func() {
+	It("validates integer multiples", func() {
+		Ω(goa.ValidateMultipleOf(15, 5)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(0, 5)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(16, 5)).Should(BeFalse())
+	})
+
+	It("validates large integer multiples exactly", func() {
+		Ω(goa.ValidateMultipleOf(100000000000001, 10)).Should(BeFalse())
+		Ω(goa.ValidateMultipleOf(1000000000000001, 2)).Should(BeFalse())
+		Ω(goa.ValidateMultipleOf(1000000000000000, 2)).Should(BeTrue())
+	})
+
+	It("validates negative multiples", func() {
+		Ω(goa.ValidateMultipleOf(-10, 5)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(-11, 5)).Should(BeFalse())
+	})
+
+	It("tolerates floating point rounding errors", func() {
+		Ω(goa.ValidateMultipleOf(0.3, 0.1)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(45.27, 0.01)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(45.275, 0.01)).Should(BeFalse())
+	})
+
+	It("tolerates the rounding errors of large quotients", func() {
+		Ω(goa.ValidateMultipleOf(98765432.1, 0.1)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(98765432.15, 0.1)).Should(BeFalse())
+	})
+
+	It("does not validate with a zero divisor", func() {
+		Ω(goa.ValidateMultipleOf(5, 0)).Should(BeFalse())
+	})
+})
+
+var _ = Describe("ValidateUniqueItems", func() {
+	type item struct {
+		Name string
+		Qty  int
+	}
+
+	It("validates primitive elements", func() {
+		_, ok := goa.ValidateUniqueItems([]string{"a", "b", "c"})
+		Ω(ok).Should(BeTrue())
+		dup, ok := goa.ValidateUniqueItems([]int{1, 2, 1})
+		Ω(ok).Should(BeFalse())
+		Ω(dup).Should(Equal(1))
+	})
+
+	It("validates struct elements", func() {
+		_, ok := goa.ValidateUniqueItems([]*item{{"a", 1}, {"a", 2}})
+		Ω(ok).Should(BeTrue())
+		dup, ok := goa.ValidateUniqueItems([]*item{{"a", 1}, {"b", 2}, {"a", 1}})
+		Ω(ok).Should(BeFalse())
+		Ω(dup).Should(Equal(&item{"a", 1}))
+	})
+
+	It("compares the values pointed to", func() {
+		a, b, c := "a", "b", "a"
+		dup, ok := goa.ValidateUniqueItems([]*string{&a, &b, &c})
+		Ω(ok).Should(BeFalse())
+		Ω(dup).Should(Equal(&c))
+	})
+
+	It("validates generic elements", func() {
+		_, ok := goa.ValidateUniqueItems([]interface{}{"1", 1.0, true})
+		Ω(ok).Should(BeTrue())
+		dup, ok := goa.ValidateUniqueItems([]interface{}{"a", map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 1.0}})
+		Ω(ok).Should(BeFalse())
+		Ω(dup).Should(Equal(map[string]interface{}{"a": 1.0}))
+	})
+
+	It("validates large slices", func() {
+		vals := make([]int, 100000)
+		for i := range vals {
+			vals[i] = i
+		}
+		_, ok := goa.ValidateUniqueItems(vals)
+		Ω(ok).Should(BeTrue())
+	})
+
+	It("ignores values that are not slices", func() {
+		_, ok := goa.ValidateUniqueItems("aa")
+		Ω(ok).Should(BeTrue())
+	})
+})
+
+func BenchmarkValidatePattern(b *testing.B) {
+	goa.CompilePatterns("^[a-z]+@[a-z]+\\.com$")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			goa.ValidatePattern("^[a-z]+@[a-z]+\\.com$", "goa@goa.com")
+		}
+	})
+}
+
+// benchItem is an item of the payload validated by BenchmarkValidateFailFast.
+type benchItem struct {
+	Count int
+	Name  string
+}
+
+// validateBenchItems is the validation code generated for an array of items with required
+// "name" and "count" members, a minimum length on "name" and a minimum on "count".
+func validateBenchItems(items []*benchItem) (err error) {
+	for _, e := range items {
+		if e.Name == "" {
+			err = goa.AppendError(err, goa.MissingAttributeError(`payload.items[*]`, "name"))
+		}
+		if e.Count < 1 {
+			err = goa.AppendError(err, goa.InvalidRangeError(`payload.items[*].count`, e.Count, 1, true))
+		}
+		if utf8.RuneCountInString(e.Name) < 3 {
+			err = goa.AppendError(err, goa.InvalidLengthError(`payload.items[*].name`, e.Name, utf8.RuneCountInString(e.Name), 3, true))
+		}
+	}
+	return
+}
+
+// validateBenchItemsFailFast is the code generated for the same validations when the design uses
+// the FailFast DSL.
+func validateBenchItemsFailFast(items []*benchItem) (err error) {
+	for _, e := range items {
+		if e.Name == "" {
+			err = goa.AppendError(err, goa.MissingAttributeError(`payload.items[*]`, "name"))
+			return err
+		}
+		if e.Count < 1 {
+			err = goa.AppendError(err, goa.InvalidRangeError(`payload.items[*].count`, e.Count, 1, true))
+			return err
+		}
+		if utf8.RuneCountInString(e.Name) < 3 {
+			err = goa.AppendError(err, goa.InvalidLengthError(`payload.items[*].name`, e.Name, utf8.RuneCountInString(e.Name), 3, true))
+			return err
+		}
+	}
+	return
+}
+
+func BenchmarkValidateFailFast(b *testing.B) {
+	items := make([]*benchItem, 1000)
+	for i := range items {
+		items[i] = &benchItem{Name: "a"}
+	}
+	bench := func(validate func([]*benchItem) error) func(*testing.B) {
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if validate(items) == nil {
+					b.Fatal("invalid payload passed validation")
+				}
+			}
+		}
+	}
+	b.Run("collect-all", bench(validateBenchItems))
+	b.Run("fail-fast", bench(validateBenchItemsFailFast))
+}